@@ -0,0 +1,76 @@
+package acceptinvitation
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	companies repository.Companies
+	members   repository.Members
+}
+
+func NewHandler(logger *slog.Logger, companies repository.Companies, members repository.Members) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		companies: companies,
+		members:   members,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/companies/{slug}/invitations/accept")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["slug"] == "" {
+		logger.Error("missing slug parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("slug is required"))
+		return
+	}
+	slug := pathValues["slug"]
+	logger = logger.With("slug", slug)
+
+	company, err := h.companies.GetBySlug(r.Context(), slug)
+	if errors.Is(err, repository.ErrCompanyNotFound) {
+		logger.Error("company not found")
+		respond.WithError(w, r, respond.CompanyNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting company", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// The invitation is for whoever signed in with the invited address, so no token is needed.
+	member, err := h.members.Accept(r.Context(), company.CompanyID, identity.Email, time.Now())
+	if errors.Is(err, repository.ErrMemberNotFound) {
+		logger.Error("no invitation to accept")
+		respond.WithError(w, r, respond.MemberNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error accepting invitation", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	respond.WithJSON(w, member, http.StatusOK)
+}
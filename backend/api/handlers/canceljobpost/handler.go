@@ -0,0 +1,230 @@
+package canceljobpost
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+	"github.com/stripe/stripe-go/v80"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	payments payments.Provider
+	jobPosts repository.JobPosts
+	access   access.Checker
+}
+
+type CancelJobPostRequest struct {
+	Reason string `json:"reason"`
+	// Full refunds every purchase in full instead of only the unused time, only admins can set it.
+	Full bool `json:"full"`
+}
+
+func NewHandler(logger *slog.Logger, provider payments.Provider, jobPosts repository.JobPosts, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		payments: provider,
+		jobPosts: jobPosts,
+		access:   checker,
+	}, nil
+}
+
+// The same lambda serves recruiters cancelling their own posts and admins cancelling any post.
+var (
+	matcher      = pathvars.NewExtractor("*/upfront/job-posts/{id}/cancel")
+	adminMatcher = pathvars.NewExtractor("*/upfront/admin/job-posts/{id}/cancel")
+)
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, admin := adminMatcher.Extract(r.URL)
+	if !admin {
+		pathValues, _ = matcher.Extract(r.URL)
+	}
+	id := pathValues["id"]
+	if id == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	logger = logger.With("id", id, "admin", admin)
+
+	if admin && !identity.IsAdmin() {
+		logger.Error("admin route called by a non admin", "email", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	// The body is optional, an empty one cancels with a prorated refund and no reason.
+	var request CancelJobPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+	if request.Full && !admin {
+		logger.Error("full refund requested by owner")
+		respond.WithError(w, r, respond.ValidationFailed("full can only be set by an admin"))
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	if !admin {
+		err = h.access.JobPost(r.Context(), identity, item, models.Editor)
+		if errors.Is(err, access.ErrForbidden) {
+			logger.Error("recruiter can not edit the job post")
+			respond.WithError(w, r, respond.Forbidden())
+			return
+		}
+		if err != nil {
+			logger.Error("error checking access", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+	}
+
+	// A cancelled post with pending refunds is a retry after a refund failed, the refunds are
+	// issued again with the amounts recorded the first time.
+	if item.Status == models.Cancelled && len(item.PendingRefunds()) == 0 {
+		logger.Error("job post is already cancelled")
+		respond.WithError(w, r, respond.InvalidJobStatus("the job post is already cancelled"))
+		return
+	}
+
+	if item.Status == models.Removed {
+		logger.Error("job post was removed by a moderator")
+		respond.WithError(w, r, respond.InvalidJobStatus("the job post was removed by a moderator and has already been refunded"))
+		return
+	}
+
+	if item.Status != models.Cancelled {
+		before := item.Snapshot()
+		now := time.Now()
+		if err := h.settlePurchases(r, &item, now); err != nil {
+			logger.Error("error settling pending purchases", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		err = item.Cancel(now, identity.Email, request.Reason, request.Full)
+		var transitionErr models.TransitionError
+		if errors.As(err, &transitionErr) {
+			logger.Error("job post can't be cancelled", "error", err)
+			respond.WithError(w, r, respond.InvalidJobStatus(err.Error()))
+			return
+		}
+		if err != nil {
+			logger.Error("error cancelling job post", "error", err)
+			respond.WithError(w, r, respond.Internal())
+			return
+		}
+		if !h.save(w, r, logger, &item, item.Audit(models.AuditCancelled, identity.Email, before, now)) {
+			return
+		}
+		logger.Info("cancelled job post", "refunds", len(item.PendingRefunds()))
+	}
+
+	before := item.Snapshot()
+	var refundErr error
+	issued := false
+	for _, i := range item.PendingRefunds() {
+		pending := &item.Refunds[i]
+		result, err := h.payments.Refund(r.Context(), pending.SessionID, pending.Amount)
+		if err != nil {
+			logger.Error("error refunding purchase", "error", err, "sessionID", pending.SessionID)
+			refundErr = err
+			continue
+		}
+		now := time.Now().Format(time.RFC3339)
+		pending.RefundID = result.ID
+		pending.Status = models.RefundIssued
+		pending.IssuedAt = now
+		item.UpdatedAt = now
+		issued = true
+		logger.Info("refunded purchase", "sessionID", pending.SessionID, "refundID", result.ID, "amount", pending.Amount)
+	}
+	if issued && !h.save(w, r, logger, &item, item.Audit(models.AuditRefunded, identity.Email, before, time.Now())) {
+		return
+	}
+	if refundErr != nil {
+		// The post stays cancelled, calling cancel again retries the refunds that failed.
+		respond.WithError(w, r, respond.Upstream(refundErr))
+		return
+	}
+
+	respond.WithJSON(w, item, http.StatusOK)
+}
+
+// settlePurchases resolves checkouts that were still pending, a paid one is applied so it is
+// refunded and an open one is expired so it can't be paid for a cancelled post.
+func (h Handler) settlePurchases(r *http.Request, item *models.JobPostItem, now time.Time) error {
+	for i, purchase := range item.Purchases() {
+		if purchase.Status != models.PurchasePending {
+			continue
+		}
+		checkoutSession, err := h.payments.GetCheckout(r.Context(), purchase.SessionID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case payments.Paid(checkoutSession):
+			err = item.ApplyPurchase(i, checkoutSession.AmountTotal, now)
+		case checkoutSession.Status == stripe.CheckoutSessionStatusOpen:
+			if err = h.payments.ExpireCheckout(r.Context(), purchase.SessionID); err == nil {
+				err = item.ExpirePurchase(i, now)
+			}
+		default:
+			err = item.ExpirePurchase(i, now)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// save writes item and responds with an error if it couldn't, it reports whether it succeeded.
+func (h Handler) save(w http.ResponseWriter, r *http.Request, logger *slog.Logger, item *models.JobPostItem, audit ...models.AuditEntry) bool {
+	err := h.jobPosts.Save(r.Context(), item, audit...)
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while cancelling")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return false
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return false
+	}
+	return true
+}
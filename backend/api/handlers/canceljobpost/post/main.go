@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+	"github.com/josepheid/upfront/api/handlers/canceljobpost"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/timeouts"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	secretName := "STRIPE_SECRET_KEY"
+	region := "eu-west-2"
+
+	ctx := context.Background()
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithAPIOptions(requestid.AWSAPIOptions))
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// Create Secrets Manager client
+	svc := secretsmanager.NewFromConfig(config)
+
+	input := &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(secretName),
+		VersionStage: aws.String("AWSCURRENT"), // VersionStage defaults to AWSCURRENT if unspecified
+	}
+
+	result, err := svc.GetSecretValue(ctx, input)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	var secretKeyValuePair map[string]string
+	if err = json.Unmarshal([]byte(*result.SecretString), &secretKeyValuePair); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	secret := secretKeyValuePair["STRIPE_SECRET_KEY"]
+
+	upfrontTableName := os.Getenv("UPFRONT_TABLE_NAME")
+
+	// If the environment variable is not set
+	if upfrontTableName == "" {
+		logger.Error("environment variable UPFRONT_TABLE_NAME is not set", "error", err)
+		os.Exit(1)
+	}
+
+	ddbc := dynamodb.NewFromConfig(config, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.DynamoDB))
+	})
+
+	h, err := canceljobpost.NewHandler(logger, payments.NewProvider(secret, budgets), repository.NewJobPosts(ddbc, upfrontTableName), access.NewChecker(repository.NewMembers(ddbc, upfrontTableName)))
+	if err != nil {
+		logger.Error("could not create handler", slog.Any("error", err))
+		os.Exit(1)
+	}
+	function := httpadapter.New(requestid.Middleware(logger, timeouts.Middleware(h))).ProxyWithContext
+	lambda.Start(function)
+}
@@ -3,136 +3,378 @@ package createcheckoutsession
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/a-h/pathvars"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/google/uuid"
 	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/duplicates"
+	"github.com/josepheid/upfront/internal/idempotency"
+	"github.com/josepheid/upfront/internal/origins"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
 	"github.com/josepheid/upfront/internal/respond"
-	"github.com/stripe/stripe-go/v80"
-	"github.com/stripe/stripe-go/v80/checkout/session"
-	"github.com/stripe/stripe-go/v80/price"
+	"github.com/josepheid/upfront/internal/scoring"
 )
 
 type Handler struct {
 	logger    *slog.Logger
-	stripeKey string
-	ddbc      *dynamodb.Client
-	tableName string
+	payments  payments.Provider
+	jobPosts  repository.JobPosts
+	origins   origins.Allowlist
+	requests  idempotency.Store
+	companies repository.Companies
+	access    access.Checker
+	scorer    scoring.Scorer
+	detector  duplicates.Detector
 }
 
+// CheckoutSessionRequest is either the whole form, or a saved draft's ID with the redirect URLs.
+// A draft is checked out as it was saved, the rest of the form is ignored, and only by its owner
+// signed in.
 type CheckoutSessionRequest struct {
-	Amount      int64  `json:"amount"`
-	SuccessURL  string `json:"successUrl"`
-	ProductName string `json:"productName"`
+	models.JobPostFormProps
+	DraftID string `json:"draftId,omitempty"`
 }
 
 type CheckoutSessionResponse struct {
 	URL string `json:"url"`
+	// PossibleDuplicates are the company's posts that are nearly the same as the new one, so the
+	// recruiter can renew one of them instead.
+	PossibleDuplicates []models.PossibleDuplicate `json:"possibleDuplicates,omitempty"`
 }
 
-func NewHandler(logger *slog.Logger, stripeKey string, ddbc *dynamodb.Client, tableName string) (Handler, error) {
+func NewHandler(logger *slog.Logger, provider payments.Provider, ddbc *dynamodb.Client, tableName string, allowedOrigins origins.Allowlist, requests idempotency.Store, companies repository.Companies, checker access.Checker, scorer scoring.Scorer, detector duplicates.Detector) (Handler, error) {
 	return Handler{
 		logger:    logger,
-		stripeKey: stripeKey,
-		ddbc:      ddbc,
-		tableName: tableName,
+		payments:  provider,
+		jobPosts:  repository.NewJobPosts(ddbc, tableName),
+		origins:   allowedOrigins,
+		requests:  requests,
+		companies: companies,
+		access:    checker,
+		scorer:    scorer,
+		detector:  detector,
 	}, nil
 }
 
-var priceFactors = map[models.PlanType]int{
-	models.Standard: 35,
-	models.Premium:  90,
-}
+// idempotencyScope namespaces this endpoint's keys in the idempotency store.
+const idempotencyScope = "checkout-session"
+
+// jobIDNamespace derives a stable job ID from an idempotency key, so retries send Stripe the same
+// parameters as the original request.
+var jobIDNamespace = uuid.MustParse("8f4b7c52-0f0e-4a55-9f6c-2f4f3e2b9d61")
+
+// Paths on the frontend that Stripe redirects back to, these are joined to an allowed origin
+// rather than taken from the request.
+const (
+	successPath = "/success"
+	cancelPath  = "/post-job"
+)
+
+// The same lambda serves anyone checking out with the form and signed in recruiters, only the
+// second can post for a company or join one they belong to when the post is paid for.
+var recruiterMatcher = pathvars.NewExtractor("*/upfront/recruiter/checkout-session")
 
 func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	var request models.JobPostFormProps
-	err := json.NewDecoder(r.Body).Decode(&request)
+	logger := requestid.Logger(r.Context(), h.logger)
+	var identity *auth.Identity
+	if _, ok := recruiterMatcher.Extract(r.URL); ok {
+		id, err := auth.FromRequest(r)
+		if err != nil {
+			logger.Error("request is not authenticated", "error", err)
+			respond.WithError(w, r, respond.Unauthenticated())
+			return
+		}
+		identity = &id
+	}
+
+	var checkout CheckoutSessionRequest
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &checkout)
+	}
 
-	h.logger.Info("Incoming request", "requestBody", request)
+	logger.Info("Incoming request", "requestBody", checkout)
 	if err != nil {
-		h.logger.Error("error decoding request body", "error", err)
-		respond.WithError(w, "error decoding request body", http.StatusBadRequest)
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
 		return
 	}
 
-	stripe.Key = h.stripeKey
+	now := time.Now()
+	request := checkout.JobPostFormProps
+	var draft *models.JobPostItem
+	if checkout.DraftID != "" {
+		logger = logger.With("draftID", checkout.DraftID)
+		if identity == nil {
+			logger.Error("checking out a draft needs its signed in owner")
+			respond.WithError(w, r, respond.Unauthenticated())
+			return
+		}
+		item, err := h.jobPosts.Get(r.Context(), checkout.DraftID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			logger.Error("error getting draft", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		if err != nil || !item.IsDraft(now) {
+			logger.Error("draft not found", "status", item.Status)
+			respond.WithError(w, r, respond.DraftNotFound())
+			return
+		}
+		if !identity.Owns(item.LoginEmail) {
+			logger.Error("recruiter doesn't own the draft", "identity", identity.Email)
+			respond.WithError(w, r, respond.Forbidden())
+			return
+		}
+		draft = &item
+		request = item.JobPostFormProps
+		request.SuccessURL, request.CancelURL = checkout.SuccessURL, checkout.CancelURL
+	}
+	// A signed in recruiter can only check out as themselves.
+	if identity != nil {
+		request.LoginEmail = identity.Email
+	}
+
+	origin, err := h.origins.Resolve(request.SuccessURL)
+	if err != nil {
+		logger.Error("success url origin not allowed", "error", err)
+		respond.WithError(w, r, respond.OriginNotAllowed(fmt.Sprintf("successURL %q is not on an allowed origin", request.SuccessURL)))
+		return
+	}
+	if cancelOrigin, err := h.origins.Resolve(request.CancelURL); err != nil || cancelOrigin != origin {
+		logger.Error("cancel url origin not allowed", "error", err, "origin", origin)
+		respond.WithError(w, r, respond.OriginNotAllowed(fmt.Sprintf("cancelURL %q is not on the same allowed origin as successURL", request.CancelURL)))
+		return
+	}
 
-	totalAmount := ((request.PlanDuration * priceFactors[request.PlanType]) / 30) * 100
+	idempotencyKey := r.Header.Get(idempotency.Header)
+	if len(idempotencyKey) > idempotency.MaxKeyLength {
+		logger.Error("idempotency key too long", "length", len(idempotencyKey))
+		respond.WithError(w, r, respond.ValidationFailed(fmt.Sprintf("%s must be at most %d characters", idempotency.Header, idempotency.MaxKeyLength)))
+		return
+	}
 
-	priceParams := &stripe.PriceParams{
-		Currency:    stripe.String(string(stripe.CurrencyGBP)),
-		UnitAmount:  stripe.Int64(int64(totalAmount)),
-		ProductData: &stripe.PriceProductDataParams{Name: stripe.String(fmt.Sprintf("%s plan for %d days.", request.PlanType, request.PlanDuration))},
+	// Keys are chosen by clients, so they are scoped to the recruiter checking out, otherwise two
+	// recruiters sending the same key and form would get each other's checkout.
+	callerKey := ""
+	if idempotencyKey != "" {
+		callerKey = idempotency.CallerKey(request.LoginEmail, idempotencyKey)
 	}
-	priceResult, err := price.New(priceParams)
-	if err != nil {
-		h.logger.Error("Error creating price", "error", err)
-		respond.WithError(w, "error creating price", http.StatusInternalServerError)
+
+	// Until the job post is stored a failure releases the key, so the client can retry with it.
+	stored := false
+	if idempotencyKey != "" {
+		logger = logger.With("idempotencyKey", idempotencyKey)
+		record, err := h.requests.Begin(r.Context(), idempotencyScope, callerKey, idempotency.Hash(body))
+		switch {
+		case errors.Is(err, idempotency.ErrKeyReused):
+			logger.Error("idempotency key reused with a different request")
+			respond.WithError(w, r, respond.IdempotencyKeyReused())
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			logger.Warn("idempotent request still in progress")
+			respond.WithError(w, r, respond.RequestInProgress())
+			return
+		case err != nil:
+			logger.Error("error claiming idempotency key", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		case record != nil:
+			logger.Info("replaying idempotent response")
+			record.Replay(w)
+			return
+		}
+		defer func() {
+			if stored {
+				return
+			}
+			if err := h.requests.Release(context.WithoutCancel(r.Context()), idempotencyScope, callerKey); err != nil {
+				logger.Error("error releasing idempotency key", "error", err)
+			}
+		}()
+	}
+
+	if issues := models.ValidatePlan(request.PlanType, request.PlanDuration); len(issues) > 0 {
+		logger.Error("invalid plan", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
 		return
 	}
+	amount := models.Price(request.PlanType, request.PlanDuration)
+
+	if request.CompanyID != "" {
+		if identity == nil {
+			logger.Error("posting for a company needs a signed in recruiter", "companyID", request.CompanyID)
+			respond.WithError(w, r, respond.Unauthenticated())
+			return
+		}
+		company, err := h.companies.Get(r.Context(), request.CompanyID)
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			logger.Error("company not found", "companyID", request.CompanyID)
+			respond.WithError(w, r, respond.ValidationFailed(fmt.Sprintf("companyID %q does not exist", request.CompanyID)))
+			return
+		}
+		if err != nil {
+			logger.Error("error getting company", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		err = h.access.Company(r.Context(), *identity, company.CompanyID, models.Editor)
+		if errors.Is(err, access.ErrForbidden) {
+			logger.Error("recruiter can not post for the company", "companyID", request.CompanyID)
+			respond.WithError(w, r, respond.Forbidden())
+			return
+		}
+		if err != nil {
+			logger.Error("error checking access", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		company.ApplyTo(&request)
+	} else {
+		// Posts from forms without a company picker get a company of the same name once they are
+		// paid for, see validatepurchase. Its logo is only ever one we host, so a companyLogoURL in
+		// the form is ignored.
+		if models.Slugify(request.CompanyName) == "" {
+			logger.Error("company name has no letters or numbers")
+			respond.WithError(w, r, respond.ValidationFailed("companyName must contain at least one letter or number"))
+			return
+		}
+		request.CompanyLogoURL = nil
+	}
+	contentScore := h.scorer.Score(request, now)
+	logger.Info("scored job post", "score", contentScore.Score, "needsReview", contentScore.NeedsReview)
 
 	jobID := uuid.New()
+	switch {
+	case draft != nil:
+		// The draft becomes the post, so links to it and its history carry over.
+		jobID, err = uuid.Parse(draft.JobID)
+		if err != nil {
+			logger.Error("draft has an invalid id", "error", err)
+			respond.WithError(w, r, respond.DraftNotFound())
+			return
+		}
+	case idempotencyKey != "":
+		jobID = uuid.NewSHA1(jobIDNamespace, []byte(request.LoginEmail+"/"+idempotencyKey))
+	}
+
+	// Only the origin is taken from the client, the redirect targets are always built here.
+	request.SuccessURL = origins.URL(origin, successPath, url.Values{"id": {jobID.String()}})
+	request.CancelURL = origins.URL(origin, cancelPath, nil)
 
-	checkoutSessionParams := &stripe.CheckoutSessionParams{
-		ClientReferenceID: stripe.String(jobID.String()),
-		SuccessURL:        stripe.String(fmt.Sprintf("%s?id=%s", request.SuccessURL, jobID.String())),
-		CancelURL:         stripe.String(request.CancelURL),
-		CustomerEmail:     stripe.String(request.LoginEmail),
-		LineItems: []*stripe.CheckoutSessionLineItemParams{
-			{
-				Price:    stripe.String(priceResult.ID),
-				Quantity: stripe.Int64(1),
-			},
-		},
-		Mode: stripe.String(string(stripe.CheckoutSessionModePayment)),
-	}
-	checkoutSessionResult, err := session.New(checkoutSessionParams)
+	checkoutSession, err := h.payments.CreateCheckout(r.Context(), payments.CheckoutParams{
+		JobID:          jobID.String(),
+		ProductName:    fmt.Sprintf("%s plan for %d days.", request.PlanType, request.PlanDuration),
+		Amount:         amount,
+		CustomerEmail:  request.LoginEmail,
+		SuccessURL:     request.SuccessURL,
+		CancelURL:      request.CancelURL,
+		IdempotencyKey: callerKey,
+	})
 	if err != nil {
-		h.logger.Error("error creating checkout session", "error", err)
-		respond.WithError(w, "error creating checkout session", http.StatusInternalServerError)
+		logger.Error("error creating checkout", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
 		return
 	}
 
-	now := time.Now()
 	createdAt, updatedAt := now, now
 
-	jobPostItem := models.JobPostItem{
-		JobPostFormProps:  request,
-		PK:                models.FormatPK(jobID.String()),
-		SK:                createdAt.Format(time.RFC3339),
-		JobID:             jobID.String(),
-		SessionID:         checkoutSessionResult.ID,
-		CreatedAt:         createdAt.Format(time.RFC3339),
-		UpdatedAt:         updatedAt.Format(time.RFC3339),
-		Status:            models.PendingPayment,
-		ClickedApplyCount: 0,
-		AllJobs:           "ALL_JOBS",
+	// Duplicates are only a warning, so checkout goes ahead if they can't be looked for. Posts
+	// without a company yet are checked once they have one.
+	minHash := duplicates.Signature(request.Title, request.Description)
+	possibleDuplicates, err := h.detector.Find(r.Context(), models.JobPostItem{JobPostFormProps: request, JobID: jobID.String(), MinHash: minHash})
+	if err != nil {
+		logger.Error("error finding duplicate job posts", "error", err)
+	}
+	if len(possibleDuplicates) > 0 {
+		logger.Info("found possible duplicate job posts", "count", len(possibleDuplicates))
 	}
 
-	data, err := attributevalue.MarshalMap(jobPostItem)
+	jobPostItem := models.JobPostItem{
+		JobPostFormProps:   request,
+		PK:                 models.FormatPK(jobID.String()),
+		SK:                 createdAt.Format(time.RFC3339),
+		JobID:              jobID.String(),
+		SessionID:          checkoutSession.ID,
+		CreatedAt:          createdAt.Format(time.RFC3339),
+		UpdatedAt:          updatedAt.Format(time.RFC3339),
+		Status:             models.PendingPayment,
+		ClickedApplyCount:  0,
+		AllJobs:            "ALL_JOBS",
+		LoginVerified:      identity != nil,
+		ContentScore:       &contentScore,
+		MinHash:            minHash,
+		PossibleDuplicates: possibleDuplicates,
+		TTL:                createdAt.Add(models.PendingPaymentTTL).Unix(),
+		BillingHistory: []models.Purchase{{
+			SessionID:    checkoutSession.ID,
+			Kind:         models.InitialPurchase,
+			PlanType:     request.PlanType,
+			PlanDuration: request.PlanDuration,
+			Amount:       amount,
+			Currency:     models.BillingCurrency,
+			Status:       models.PurchasePending,
+			CreatedAt:    createdAt.Format(time.RFC3339),
+		}},
+	}
 
+	if draft != nil {
+		// The draft's key is kept, its createdAt is the checkout's so unpaid posts are still
+		// reconciled a day after checkout. An abandoned checkout goes back to being a draft, so it
+		// is kept as long as the draft would have been.
+		jobPostItem.SK = draft.SK
+		jobPostItem.Status = models.Draft
+		jobPostItem.Version = draft.Version
+		jobPostItem.FromDraft = true
+		jobPostItem.TTL = now.Add(models.DraftTTL).Unix()
+		before := draft.Snapshot()
+		if err := jobPostItem.Transition(models.PendingPayment, now); err != nil {
+			logger.Error("error checking out draft", "error", err)
+			respond.WithError(w, r, respond.InvalidJobStatus(err.Error()))
+			return
+		}
+		audit := jobPostItem.Audit(models.AuditCheckoutStarted, request.LoginEmail, before, now)
+		err = h.jobPosts.Save(r.Context(), &jobPostItem, audit)
+	} else {
+		audit := jobPostItem.Audit(models.AuditCreated, request.LoginEmail, nil, createdAt)
+		err = h.jobPosts.Create(r.Context(), jobPostItem, audit)
+	}
+	if errors.Is(err, repository.ErrConflict) && draft != nil {
+		logger.Error("draft changed or was checked out during checkout")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
 	if err != nil {
-		h.logger.Error("error marshalling job item", "error", err)
-		respond.WithError(w, "error marshalling job item", http.StatusInternalServerError)
+		logger.Error("error putting item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
 		return
 	}
 
-	_, err = h.ddbc.PutItem(context.TODO(), &dynamodb.PutItemInput{
-		TableName: aws.String(h.tableName),
-		Item:      data,
-	})
+	stored = true
 
-	if err != nil {
-		h.logger.Error("error putting item", "error", err)
-		respond.WithError(w, "error putting item", http.StatusInternalServerError)
-		return
+	response := CheckoutSessionResponse{URL: checkoutSession.URL, PossibleDuplicates: possibleDuplicates}
+	if idempotencyKey != "" {
+		responseBody, err := json.Marshal(response)
+		if err == nil {
+			err = h.requests.Complete(r.Context(), idempotencyScope, callerKey, http.StatusCreated, responseBody)
+		}
+		if err != nil {
+			// The job post exists, so the key is kept, retries get request_in_progress until it expires.
+			logger.Error("error storing idempotent response", "error", err)
+		}
 	}
 
-	respond.WithJSON(w, CheckoutSessionResponse{URL: checkoutSessionResult.URL}, http.StatusCreated)
+	respond.WithJSON(w, response, http.StatusCreated)
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -13,6 +14,15 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
 	"github.com/josepheid/upfront/api/handlers/createcheckoutsession"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/duplicates"
+	"github.com/josepheid/upfront/internal/idempotency"
+	"github.com/josepheid/upfront/internal/origins"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/scoring"
+	"github.com/josepheid/upfront/internal/timeouts"
 )
 
 func main() {
@@ -21,12 +31,18 @@ func main() {
 	region := "eu-west-2"
 
 	ctx := context.Background()
-	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithAPIOptions(requestid.AWSAPIOptions))
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
 	// Create Secrets Manager client
 	svc := secretsmanager.NewFromConfig(config)
 
@@ -56,13 +72,31 @@ func main() {
 		os.Exit(1)
 	}
 
-	ddbc := dynamodb.NewFromConfig(config)
+	allowedOrigins, err := origins.NewAllowlist(os.Getenv("ALLOWED_ORIGINS"))
+	if err != nil {
+		logger.Error("environment variable ALLOWED_ORIGINS is not valid", "error", err)
+		os.Exit(1)
+	}
+
+	ddbc := dynamodb.NewFromConfig(config, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.DynamoDB))
+	})
+
+	// Stripe keeps idempotency keys for 24 hours, so stored responses last as long.
+	requests := idempotency.NewStore(ddbc, upfrontTableName, 24*time.Hour)
+
+	rules, err := scoring.FromEnv()
+	if err != nil {
+		logger.Error("invalid scoring rules", "error", err)
+		os.Exit(1)
+	}
 
-	h, err := createcheckoutsession.NewHandler(logger, secret, ddbc, upfrontTableName)
+	companies := repository.NewCompanies(ddbc, upfrontTableName)
+	h, err := createcheckoutsession.NewHandler(logger, payments.NewProvider(secret, budgets), ddbc, upfrontTableName, allowedOrigins, requests, companies, access.NewChecker(repository.NewMembers(ddbc, upfrontTableName)), scoring.NewScorer(rules), duplicates.NewDetector(companies))
 	if err != nil {
 		logger.Error("could not create handler", slog.Any("error", err))
 		os.Exit(1)
 	}
-	function := httpadapter.New(h).ProxyWithContext
+	function := httpadapter.New(requestid.Middleware(logger, timeouts.Middleware(h))).ProxyWithContext
 	lambda.Start(function)
 }
@@ -0,0 +1,96 @@
+package createdraft
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/google/uuid"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/ratelimit"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+	limiter  ratelimit.Limiter
+	hasher   ratelimit.IPHasher
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, limiter ratelimit.Limiter, hasher ratelimit.IPHasher) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+		limiter:  limiter,
+		hasher:   hasher,
+	}, nil
+}
+
+// The same lambda serves anyone saving a draft and signed in recruiters, only the second proves
+// the draft's email is theirs.
+var recruiterMatcher = pathvars.NewExtractor("*/upfront/recruiter/drafts")
+
+// ServeHTTP saves the form as a new draft owned by its loginEmail. Anyone can save one, reading,
+// changing and checking it out later needs its owner signed in. Nobody has proved they own the
+// email of a draft saved without signing in, so it doesn't let them sign in, see startchallenge.
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	var identity *auth.Identity
+	if _, ok := recruiterMatcher.Extract(r.URL); ok {
+		id, err := auth.FromRequest(r)
+		if err != nil {
+			logger.Error("request is not authenticated", "error", err)
+			respond.WithError(w, r, respond.Unauthenticated())
+			return
+		}
+		identity = &id
+	}
+
+	var request models.JobPostFormProps
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	if identity != nil {
+		request.LoginEmail = identity.Email
+	}
+	if issues := models.ValidateDraft(request); len(issues) > 0 {
+		logger.Error("invalid draft", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
+		return
+	}
+
+	now := time.Now()
+	clientID := h.hasher.Hash(ratelimit.ClientIP(r))
+	allowed, err := h.limiter.Allow(r.Context(), "drafts", clientID, now)
+	if err != nil {
+		logger.Error("error checking rate limit", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	if !allowed {
+		logger.Error("client is rate limited", "clientID", clientID)
+		respond.WithError(w, r, respond.RateLimited())
+		return
+	}
+
+	item := models.NewDraft(uuid.NewString(), request, now)
+	item.LoginVerified = identity != nil
+	logger = logger.With("id", item.JobID)
+	err = h.jobPosts.Create(r.Context(), item, item.Audit(models.AuditCreated, item.LoginEmail, nil, now))
+	if err != nil {
+		logger.Error("error creating draft", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	logger.Info("created draft")
+
+	respond.WithJSON(w, item.Form(), http.StatusCreated)
+}
@@ -0,0 +1,163 @@
+package createreport
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/ratelimit"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	jobPosts  repository.JobPosts
+	reports   repository.Reports
+	limiter   ratelimit.Limiter
+	hasher    ratelimit.IPHasher
+	threshold int
+}
+
+type CreateReportRequest struct {
+	Reason  models.ReportReason `json:"reason"`
+	Details string              `json:"details"`
+}
+
+// NewHandler creates a handler that holds an active post for review once threshold visitors have
+// reported it.
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, reports repository.Reports, limiter ratelimit.Limiter, hasher ratelimit.IPHasher, threshold int) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		jobPosts:  jobPosts,
+		reports:   reports,
+		limiter:   limiter,
+		hasher:    hasher,
+		threshold: threshold,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/job-posts/{id}/reports")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	var request CreateReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	request.Details = strings.TrimSpace(request.Details)
+	if issues := models.ValidateReport(request.Reason, request.Details); len(issues) > 0 {
+		logger.Error("invalid report", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
+		return
+	}
+
+	now := time.Now()
+	reporterID := h.hasher.Hash(ratelimit.ClientIP(r))
+	logger = logger.With("reporterID", reporterID)
+	allowed, err := h.limiter.Allow(r.Context(), "reports", reporterID, now)
+	if err != nil {
+		logger.Error("error checking rate limit", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	if !allowed {
+		logger.Error("reporter is rate limited")
+		respond.WithError(w, r, respond.RateLimited())
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	// Visitors can only see live posts, any other post is treated as missing.
+	if item.Status != models.Active && item.Status != models.PendingReview {
+		logger.Error("job post can't be reported", "status", item.Status)
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+
+	report := models.Report{
+		ReporterID: reporterID,
+		Reason:     request.Reason,
+		Details:    request.Details,
+		Status:     models.ReportOpen,
+		CreatedAt:  now.Format(time.RFC3339),
+	}
+	err = h.reports.Create(r.Context(), item, report)
+	if errors.Is(err, repository.ErrAlreadyReported) {
+		logger.Error("job post already reported by reporter")
+		respond.WithError(w, r, respond.AlreadyReported())
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error creating report", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	logger.Info("reported job post", "reason", report.Reason)
+	report.PK = item.PK
+	report.SK = models.FormatReportSK(reporterID)
+	report.JobID = item.JobID
+
+	if item.Status == models.Active && item.OpenReportCount+1 >= h.threshold {
+		h.hold(r, logger, id, now)
+	}
+
+	respond.WithJSON(w, report, http.StatusCreated)
+}
+
+// hold takes a post that has had enough reports down until an admin reviews it. The report is
+// already stored, so a failure is only logged and the next report tries again.
+func (h Handler) hold(r *http.Request, logger *slog.Logger, id string, now time.Time) {
+	// The post is read again to pick up the counts written with the report.
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if err != nil {
+		logger.Error("error getting reported job", "error", err)
+		return
+	}
+	if item.Status != models.Active || item.OpenReportCount < h.threshold {
+		return
+	}
+	before := item.Snapshot()
+	if err := item.HoldForReview(now); err != nil {
+		logger.Error("error holding reported job post for review", "error", err)
+		return
+	}
+	err = h.jobPosts.Save(r.Context(), &item, item.Audit(models.AuditHeldForReview, models.ReportsActor, before, now))
+	if err != nil {
+		logger.Error("error holding reported job post for review", "error", err)
+		return
+	}
+	logger.Info("held reported job post for review", "openReports", item.OpenReportCount)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+	"github.com/josepheid/upfront/api/handlers/createreport"
+	"github.com/josepheid/upfront/internal/ratelimit"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/timeouts"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	region := "eu-west-2"
+
+	ctx := context.Background()
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithAPIOptions(requestid.AWSAPIOptions))
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
+	upfrontTableName := os.Getenv("UPFRONT_TABLE_NAME")
+
+	// If the environment variable is not set
+	if upfrontTableName == "" {
+		logger.Error("environment variable UPFRONT_TABLE_NAME is not set", "error", err)
+		os.Exit(1)
+	}
+
+	threshold := 3
+	if v := os.Getenv("REPORT_THRESHOLD"); v != "" {
+		threshold, err = strconv.Atoi(v)
+		if err != nil || threshold < 1 {
+			logger.Error("environment variable REPORT_THRESHOLD must be a positive integer", "value", v)
+			os.Exit(1)
+		}
+	}
+
+	hasher, err := ratelimit.IPHasherFromSecret(ctx, secretsmanager.NewFromConfig(config))
+	if err != nil {
+		logger.Error("could not load the ip hash key", "error", err)
+		os.Exit(1)
+	}
+
+	ddbc := dynamodb.NewFromConfig(config, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.DynamoDB))
+	})
+
+	// Each visitor can make a few reports an hour, enough for genuine use but not to flood the
+	// review queue.
+	limiter := ratelimit.NewLimiter(ddbc, upfrontTableName, 5, time.Hour)
+	h, err := createreport.NewHandler(logger, repository.NewJobPosts(ddbc, upfrontTableName), repository.NewReports(ddbc, upfrontTableName), limiter, hasher, threshold)
+	if err != nil {
+		logger.Error("could not create handler", slog.Any("error", err))
+		os.Exit(1)
+	}
+	function := httpadapter.New(requestid.Middleware(logger, timeouts.Middleware(h))).ProxyWithContext
+	lambda.Start(function)
+}
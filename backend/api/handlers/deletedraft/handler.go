@@ -0,0 +1,86 @@
+package deletedraft
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/drafts/{id}")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("draft not found")
+		respond.WithError(w, r, respond.DraftNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting draft", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	if !item.IsDraft(time.Now()) {
+		logger.Error("job post isn't a draft", "status", item.Status)
+		respond.WithError(w, r, respond.DraftNotFound())
+		return
+	}
+
+	// A draft belongs to the email it was saved with, only that recruiter signed in can reach it.
+	if !identity.Owns(item.LoginEmail) {
+		logger.Error("recruiter doesn't own the draft", "identity", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	// A draft checked out in the meantime is a job post now, so it is kept.
+	err = h.jobPosts.DeleteDraft(r.Context(), item)
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("draft was checked out while being deleted")
+		respond.WithError(w, r, respond.DraftNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error deleting draft", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	logger.Info("deleted draft")
+
+	w.WriteHeader(http.StatusNoContent)
+}
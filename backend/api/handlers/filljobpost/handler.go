@@ -0,0 +1,170 @@
+package filljobpost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+	"github.com/stripe/stripe-go/v80"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	payments payments.Provider
+	jobPosts repository.JobPosts
+	access   access.Checker
+}
+
+type FillJobPostRequest struct {
+	// HireChannel is where the hire came from, it is optional.
+	HireChannel models.HireChannel `json:"hireChannel"`
+}
+
+func NewHandler(logger *slog.Logger, provider payments.Provider, jobPosts repository.JobPosts, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		payments: provider,
+		jobPosts: jobPosts,
+		access:   checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/job-posts/{id}/filled")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	// The body is optional, an empty one fills the post without saying where the hire came from.
+	var request FillJobPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	if issues := models.ValidateHireChannel(request.HireChannel); len(issues) > 0 {
+		logger.Error("invalid hire channel", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.JobPost(r.Context(), identity, item, models.Editor)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter can not edit the job post")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	before := item.Snapshot()
+	now := time.Now()
+	if item.Status != models.Filled {
+		if err := h.settlePurchases(r, &item, now); err != nil {
+			logger.Error("error settling pending purchases", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+	}
+	err = item.Fill(now, request.HireChannel)
+	if errors.Is(err, models.ErrAlreadyFilled) {
+		logger.Error("job post is already filled")
+		respond.WithError(w, r, respond.InvalidJobStatus("the job post is already filled"))
+		return
+	}
+	var transitionErr models.TransitionError
+	if errors.As(err, &transitionErr) {
+		logger.Error("job post can't be filled", "status", item.Status)
+		respond.WithError(w, r, respond.InvalidJobStatus(fmt.Sprintf("only %s, %s and %s job posts can be marked filled", models.Active, models.Paused, models.Expired)))
+		return
+	}
+	if err != nil {
+		logger.Error("error filling job post", "error", err)
+		respond.WithError(w, r, respond.Internal())
+		return
+	}
+
+	err = h.jobPosts.Save(r.Context(), &item, item.Audit(models.AuditFilled, identity.Email, before, now))
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while filling")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	logger.Info("filled job post", "hireChannel", item.HireChannel)
+
+	respond.WithJSON(w, item, http.StatusOK)
+}
+
+// settlePurchases resolves renewal and upgrade checkouts that were still pending, a paid one is
+// applied and an open one is expired so it can't be paid for a filled post.
+func (h Handler) settlePurchases(r *http.Request, item *models.JobPostItem, now time.Time) error {
+	for i, purchase := range item.Purchases() {
+		if purchase.Status != models.PurchasePending {
+			continue
+		}
+		checkoutSession, err := h.payments.GetCheckout(r.Context(), purchase.SessionID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case payments.Paid(checkoutSession):
+			err = item.ApplyPurchase(i, checkoutSession.AmountTotal, now)
+		case checkoutSession.Status == stripe.CheckoutSessionStatusOpen:
+			if err = h.payments.ExpireCheckout(r.Context(), purchase.SessionID); err == nil {
+				err = item.ExpirePurchase(i, now)
+			}
+		default:
+			err = item.ExpirePurchase(i, now)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package getanalytics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/a-h/pathvars"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	jobPosts  repository.JobPosts
+	companies repository.Companies
+	members   repository.Members
+}
+
+// AnalyticsResponse sums up the outcomes of the posts of a recruiter's companies, or every post
+// for an admin, in total and by plan.
+type AnalyticsResponse struct {
+	models.HiringStats
+	ByPlan map[models.PlanType]models.HiringStats `json:"byPlan"`
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, companies repository.Companies, members repository.Members) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		jobPosts:  jobPosts,
+		companies: companies,
+		members:   members,
+	}, nil
+}
+
+// The same lambda serves recruiters reading the analytics of their own posts and admins reading
+// those of every post.
+var adminMatcher = pathvars.NewExtractor("*/upfront/admin/analytics")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	_, admin := adminMatcher.Extract(r.URL)
+	logger = logger.With("admin", admin)
+	if admin && !identity.IsAdmin() {
+		logger.Error("admin route called by a non admin", "email", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	// Unpaid and draft posts never went live, so they have no outcome.
+	filter := expression.Name("status").NotEqual(expression.Value(models.PendingPayment)).
+		And(expression.Name("status").NotEqual(expression.Value(models.Draft)))
+	var posts []models.JobPostItem
+	if admin {
+		posts, err = h.jobPosts.Find(r.Context(), filter)
+	} else {
+		posts, err = h.companyPosts(r.Context(), identity.Email, filter)
+	}
+	if err != nil {
+		logger.Error("error getting job posts", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	byPlan := map[models.PlanType][]models.JobPostItem{}
+	for _, p := range posts {
+		byPlan[p.PlanType] = append(byPlan[p.PlanType], p)
+	}
+	response := AnalyticsResponse{
+		HiringStats: models.Stats(posts),
+		ByPlan:      map[models.PlanType]models.HiringStats{},
+	}
+	for plan, planPosts := range byPlan {
+		response.ByPlan[plan] = models.Stats(planPosts)
+	}
+
+	respond.WithJSON(w, response, http.StatusOK)
+}
+
+// companyPosts returns the posts matching filter of every company email is an active member of.
+func (h Handler) companyPosts(ctx context.Context, email string, filter expression.ConditionBuilder) ([]models.JobPostItem, error) {
+	memberships, err := h.members.Memberships(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	posts := []models.JobPostItem{}
+	for _, m := range memberships {
+		if !m.Active() {
+			continue
+		}
+		companyPosts, err := h.companies.JobPosts(ctx, m.CompanyID, filter)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, companyPosts...)
+	}
+	return posts, nil
+}
@@ -0,0 +1,101 @@
+package getauditlog
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+	audit    repository.Audit
+	access   access.Checker
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, audit repository.Audit, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+		audit:    audit,
+		access:   checker,
+	}, nil
+}
+
+// The same lambda serves recruiters reading the history of their own posts and admins reading
+// any post's.
+var (
+	matcher      = pathvars.NewExtractor("*/upfront/job-posts/{id}/audit")
+	adminMatcher = pathvars.NewExtractor("*/upfront/admin/job-posts/{id}/audit")
+)
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, admin := adminMatcher.Extract(r.URL)
+	if !admin {
+		pathValues, _ = matcher.Extract(r.URL)
+	}
+	id := pathValues["id"]
+	if id == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	logger = logger.With("id", id, "admin", admin)
+
+	if admin && !identity.IsAdmin() {
+		logger.Error("admin route called by a non admin", "email", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	if !admin {
+		err = h.access.JobPost(r.Context(), identity, item, models.Viewer)
+		if errors.Is(err, access.ErrForbidden) {
+			logger.Error("recruiter can not view the job post")
+			respond.WithError(w, r, respond.Forbidden())
+			return
+		}
+		if err != nil {
+			logger.Error("error checking access", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+	}
+
+	entries, err := h.audit.List(r.Context(), id)
+	if err != nil {
+		logger.Error("error listing audit entries", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	respond.WithJSON(w, entries, http.StatusOK)
+}
@@ -0,0 +1,71 @@
+package getcompany
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	companies repository.Companies
+}
+
+// CompanyResponse is a company's profile and its live job posts, newest first.
+type CompanyResponse struct {
+	models.Company
+	JobPosts []models.JobPostItem `json:"jobPosts"`
+}
+
+func NewHandler(logger *slog.Logger, companies repository.Companies) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		companies: companies,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/companies/{slug}")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["slug"] == "" {
+		logger.Error("missing slug parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("slug is required"))
+		return
+	}
+	slug := pathValues["slug"]
+	logger = logger.With("slug", slug)
+
+	company, err := h.companies.GetBySlug(r.Context(), slug)
+	if errors.Is(err, repository.ErrCompanyNotFound) {
+		logger.Error("company not found")
+		respond.WithError(w, r, respond.CompanyNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting company", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// The same posts as the public listing, the expiry job may not have caught up with expiresAt yet.
+	filter := expression.Name("status").Equal(expression.Value(models.Active)).
+		And(expression.Name("expiresAt").GreaterThan(expression.Value(time.Now().Format(time.RFC3339))))
+	jobPosts, err := h.companies.JobPosts(r.Context(), company.CompanyID, filter)
+	if err != nil {
+		logger.Error("error getting company job posts", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	respond.WithJSON(w, CompanyResponse{Company: company, JobPosts: jobPosts}, http.StatusOK)
+}
@@ -0,0 +1,93 @@
+package getcompanymembers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	companies repository.Companies
+	members   repository.Members
+	access    access.Checker
+}
+
+func NewHandler(logger *slog.Logger, companies repository.Companies, members repository.Members, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		companies: companies,
+		members:   members,
+		access:    checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/companies/{slug}/members")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["slug"] == "" {
+		logger.Error("missing slug parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("slug is required"))
+		return
+	}
+	slug := pathValues["slug"]
+	logger = logger.With("slug", slug)
+
+	company, err := h.companies.GetBySlug(r.Context(), slug)
+	if errors.Is(err, repository.ErrCompanyNotFound) {
+		logger.Error("company not found")
+		respond.WithError(w, r, respond.CompanyNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting company", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.Company(r.Context(), identity, company.CompanyID, models.Viewer)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter is not a member of the company")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	members, err := h.members.List(r.Context(), company.CompanyID)
+	if err != nil {
+		logger.Error("error listing members", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	now := time.Now()
+	current := []models.Member{}
+	for _, m := range members {
+		if !m.InvitationExpired(now) {
+			current = append(current, m)
+		}
+	}
+
+	respond.WithJSON(w, current, http.StatusOK)
+}
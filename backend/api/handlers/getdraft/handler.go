@@ -0,0 +1,85 @@
+package getdraft
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+	}, nil
+}
+
+// The same lambda returns a draft as saved and a preview of it as it would be listed.
+var (
+	matcher        = pathvars.NewExtractor("*/upfront/drafts/{id}")
+	previewMatcher = pathvars.NewExtractor("*/upfront/drafts/{id}/preview")
+)
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+	pathValues, preview := previewMatcher.Extract(r.URL)
+	if !preview {
+		pathValues, _ = matcher.Extract(r.URL)
+	}
+	id := pathValues["id"]
+	if id == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	logger = logger.With("id", id, "preview", preview)
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("draft not found")
+		respond.WithError(w, r, respond.DraftNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting draft", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	// Checked out posts are only reachable through the job post endpoints.
+	now := time.Now()
+	if !item.IsDraft(now) {
+		logger.Error("job post isn't a draft", "status", item.Status)
+		respond.WithError(w, r, respond.DraftNotFound())
+		return
+	}
+
+	// A draft belongs to the email it was saved with, only that recruiter signed in can reach it.
+	if !identity.Owns(item.LoginEmail) {
+		logger.Error("recruiter doesn't own the draft", "identity", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	if preview {
+		respond.WithJSON(w, item.Preview(now), http.StatusOK)
+		return
+	}
+	respond.WithJSON(w, item.Form(), http.StatusOK)
+}
@@ -0,0 +1,60 @@
+package getduplicates
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+	}, nil
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+	if !identity.IsAdmin() {
+		logger.Error("admin route called by a non admin", "email", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	// Posts that were never paid for, or have been taken down, don't need looking at.
+	filter := expression.AttributeExists(expression.Name("possibleDuplicates")).
+		And(expression.Name("status").In(
+			expression.Value(models.Active),
+			expression.Value(models.Expired),
+			expression.Value(models.PendingReview),
+		))
+	posts, err := h.jobPosts.Find(r.Context(), filter)
+	if err != nil {
+		logger.Error("error getting possible duplicate job posts", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].CreatedAt > posts[j].CreatedAt
+	})
+
+	respond.WithJSON(w, posts, http.StatusOK)
+}
@@ -0,0 +1,145 @@
+package getinvoice
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/invoices"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+	invoices invoices.Issuer
+	access   access.Checker
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, invoiceIssuer invoices.Issuer, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+		invoices: invoiceIssuer,
+		access:   checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/job-posts/{id}/invoices/{sessionId}")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" || pathValues["sessionId"] == "" {
+		logger.Error("missing parameters in path")
+		respond.WithError(w, r, respond.ValidationFailed("id and sessionId are required"))
+		return
+	}
+	id, sessionID := pathValues["id"], pathValues["sessionId"]
+	logger = logger.With("id", id, "sessionID", sessionID)
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.JobPost(r.Context(), identity, item, models.Viewer)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter can not view the job post")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	index := -1
+	for i, p := range item.Purchases() {
+		if p.SessionID == sessionID && p.Status == models.PurchasePaid {
+			index = i
+		}
+	}
+	if index < 0 {
+		logger.Error("no paid purchase for session")
+		respond.WithError(w, r, respond.InvoiceNotFound())
+		return
+	}
+
+	var pdf []byte
+	number := item.Purchases()[index].InvoiceNumber
+	if number == "" {
+		// Issuing failed when the purchase was validated, or it was paid before invoices existed.
+		pdf, number, ok = h.issue(w, r, logger, item, index, identity.Email)
+		if !ok {
+			return
+		}
+	} else {
+		pdf, err = h.invoices.Get(r.Context(), number)
+		if err != nil {
+			logger.Error("error getting invoice", "error", err, "invoiceNumber", number)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", invoices.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", number+".pdf"))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdf)
+}
+
+// issue creates the invoice for the purchase at index, saves its number and emails it. It responds
+// with an error and reports false if the invoice couldn't be issued.
+func (h Handler) issue(w http.ResponseWriter, r *http.Request, logger *slog.Logger, item models.JobPostItem, index int, actor string) ([]byte, string, bool) {
+	before := item.Snapshot()
+	now := time.Now()
+	invoice, pdf, err := h.invoices.Issue(r.Context(), &item, index, now)
+	if err != nil {
+		logger.Error("error issuing invoice", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return nil, "", false
+	}
+	item.UpdatedAt = now.Format(time.RFC3339)
+	err = h.jobPosts.Save(r.Context(), &item, item.Audit(models.AuditInvoiced, actor, before, now))
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while issuing invoice")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return nil, "", false
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return nil, "", false
+	}
+	logger.Info("issued invoice", "invoiceNumber", invoice.Number)
+	if err := h.invoices.Email(r.Context(), invoice, pdf); err != nil {
+		logger.Error("error emailing invoice", "error", err, "invoiceNumber", invoice.Number)
+	}
+	return pdf, invoice.Number, true
+}
@@ -0,0 +1,73 @@
+package getjobpost
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+}
+
+// JobPostDetail is a live post, or one filled within models.FilledGracePeriod with
+// PositionFilled set, as visitors see it.
+type JobPostDetail struct {
+	models.JobPostListing
+	PositionFilled bool `json:"positionFilled"`
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/job-posts/{id}")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// Visitors see what the listing shows, plus recently filled posts, any other post is treated
+	// as missing.
+	now := time.Now()
+	expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt)
+	live := item.Status == models.Active && err == nil && expiresAt.After(now)
+	filled := item.ShowsAsFilled(now)
+	if !live && !filled {
+		logger.Error("job post isn't visible", "status", item.Status)
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+
+	respond.WithJSON(w, JobPostDetail{JobPostListing: item.Listing(), PositionFilled: filled}, http.StatusOK)
+}
@@ -1,16 +1,18 @@
 package getjobposts
 
 import (
-	"context"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/ranking"
+	"github.com/josepheid/upfront/internal/requestid"
 	"github.com/josepheid/upfront/internal/respond"
 )
 
@@ -18,84 +20,70 @@ type Handler struct {
 	logger    *slog.Logger
 	tableName string
 	ddbc      *dynamodb.Client
+	ranking   ranking.Config
 }
 
 type ValidatePurchaseResponse struct {
 	URL string `json:"url"`
 }
 
-func NewHandler(logger *slog.Logger, tableName string, ddbc *dynamodb.Client) (Handler, error) {
+func NewHandler(logger *slog.Logger, tableName string, ddbc *dynamodb.Client, rankingConfig ranking.Config) (Handler, error) {
 	return Handler{
 		logger:    logger,
 		tableName: tableName,
 		ddbc:      ddbc,
+		ranking:   rankingConfig,
 	}, nil
 }
 
 func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
 	jobPosts := []models.JobPostItem{}
-	var filter expression.ConditionBuilder
-	hasFilter := false
+	now := time.Now()
+	// Only live posts are listed, which leaves out paused ones. The expiry job may not have caught
+	// up with expiresAt yet.
+	filter := expression.Name("status").Equal(expression.Value(models.Active)).
+		And(expression.Name("expiresAt").GreaterThan(expression.Value(now.Format(time.RFC3339))))
 	keyCondition := expression.KeyEqual(expression.Key("allJobs"), expression.Value("ALL_JOBS"))
 	// Extract salary from the query params and build the filter expression
 	salary := r.URL.Query().Get("salary")
 
 	if salary != "" {
-		h.logger.Info("incoming salary " + salary)
+		logger.Info("incoming salary " + salary)
 		intSalary, err := strconv.Atoi(salary)
 		if err != nil {
-			h.logger.Error("salary provided but could not convert to int", "error", err)
-			respond.WithError(w, "salary provided but could not convert to int", http.StatusBadRequest)
+			logger.Error("salary provided but could not convert to int", "error", err)
+			respond.WithError(w, r, respond.ValidationFailed("salary must be a whole number"))
 			return
 		}
-		// Initialize filter with the first condition (maxSalary)
-		filter = expression.Name("maxSalary").GreaterThanEqual(expression.Value(intSalary))
-		hasFilter = true
+		filter = filter.And(expression.Name("maxSalary").GreaterThanEqual(expression.Value(intSalary)))
 	}
 
 	// Extract location from the query params and build the filter expression
 	location := r.URL.Query().Get("location")
 	if location != "" {
-		h.logger.Info("incoming location " + location)
-		if hasFilter {
-			// Add location filter if a previous filter exists
-			filter = filter.And(expression.Name("location").Contains(location))
-		} else {
-			// Initialize filter with the location condition if no other filter exists
-			filter = expression.Name("location").Contains(location)
-			hasFilter = true
-		}
+		logger.Info("incoming location " + location)
+		filter = filter.And(expression.Name("location").Contains(location))
 	}
 
 	// Extract title from the query params and build the filter expression
 	title := r.URL.Query().Get("title")
 	if title != "" {
-		h.logger.Info("incoming title " + title)
-		if hasFilter {
-			// Add title filter if a previous filter exists
-			filter = filter.And(expression.Name("title").Contains(title))
-		} else {
-			// Initialize filter with the title condition if no other filter exists
-			filter = expression.Name("title").Contains(title)
-			hasFilter = true
-		}
+		logger.Info("incoming title " + title)
+		filter = filter.And(expression.Name("title").Contains(title))
 	}
 
 	// Build the expression using key condition and filter
-	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
-	if hasFilter {
-		builder = builder.WithFilter(filter) // Only add the filter if conditions exist
-	}
-
-	expr, err := builder.Build()
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).WithFilter(filter).Build()
 
 	if err != nil {
-		h.logger.Error("error building expression", "error", err)
-		respond.WithError(w, "error building expression", http.StatusInternalServerError)
+		logger.Error("error building expression", "error", err)
+		respond.WithError(w, r, respond.Internal())
 		return
 	}
 
-	data, err := h.ddbc.Query(context.TODO(), &dynamodb.QueryInput{
+	// Every matching post is read so ranking sees all of them, not just the first page.
+	paginator := dynamodb.NewQueryPaginator(h.ddbc, &dynamodb.QueryInput{
 		TableName:                 aws.String(h.tableName),
 		IndexName:                 aws.String("allJobsIndex"),
 		ExpressionAttributeNames:  expr.Names(),
@@ -103,17 +91,28 @@ func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		KeyConditionExpression:    expr.KeyCondition(),
 		FilterExpression:          expr.Filter(),
 	})
-	if err != nil {
-		h.logger.Error("error querying all jobs gsi", "error", err)
-		respond.WithError(w, "error querying all jobs gsi", http.StatusInternalServerError)
-		return
+	for paginator.HasMorePages() {
+		data, err := paginator.NextPage(r.Context())
+		if err != nil {
+			logger.Error("error querying all jobs gsi", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		page := []models.JobPostItem{}
+		err = attributevalue.UnmarshalListOfMaps(data.Items, &page)
+		if err != nil {
+			logger.Error("error unmarshalling list of maps", "error", err)
+			respond.WithError(w, r, respond.Internal())
+			return
+		}
+		jobPosts = append(jobPosts, page...)
 	}
-	err = attributevalue.UnmarshalListOfMaps(data.Items, &jobPosts)
-	if err != nil {
-		h.logger.Error("error unmarshalling list of maps", "error", err)
-		respond.WithError(w, "error unmarshalling list of maps", http.StatusInternalServerError)
-		return
+
+	jobPosts = ranking.Rank(jobPosts, now, h.ranking)
+	listings := make([]models.JobPostListing, len(jobPosts))
+	for i, item := range jobPosts {
+		listings[i] = item.Listing()
 	}
 
-	respond.WithJSON(w, jobPosts, http.StatusOK)
+	respond.WithJSON(w, listings, http.StatusOK)
 }
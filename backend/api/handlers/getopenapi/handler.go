@@ -0,0 +1,29 @@
+package getopenapi
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/josepheid/upfront/api/openapi"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger *slog.Logger
+	doc    openapi.Document
+}
+
+func NewHandler(logger *slog.Logger) (Handler, error) {
+	doc, err := openapi.Build("Upfront API", "1.0.0", openapi.Routes)
+	if err != nil {
+		return Handler{}, err
+	}
+	return Handler{
+		logger: logger,
+		doc:    doc,
+	}, nil
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	respond.WithJSON(w, h.doc, http.StatusOK)
+}
@@ -10,6 +10,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
 	"github.com/josepheid/upfront/api/handlers/getrecruiterjobposts"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/timeouts"
 )
 
 func main() {
@@ -17,12 +21,18 @@ func main() {
 	region := "eu-west-2"
 
 	ctx := context.Background()
-	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithAPIOptions(requestid.AWSAPIOptions))
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
 	upfrontTableName := os.Getenv("UPFRONT_TABLE_NAME")
 
 	// If the environment variable is not set
@@ -31,13 +41,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	ddbc := dynamodb.NewFromConfig(config)
+	ddbc := dynamodb.NewFromConfig(config, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.DynamoDB))
+	})
 
-	h, err := getrecruiterjobposts.NewHandler(logger, upfrontTableName, ddbc)
+	h, err := getrecruiterjobposts.NewHandler(logger, upfrontTableName, ddbc, repository.NewCompanies(ddbc, upfrontTableName), repository.NewMembers(ddbc, upfrontTableName), access.NewChecker(repository.NewMembers(ddbc, upfrontTableName)))
 	if err != nil {
 		logger.Error("could not create handler", slog.Any("error", err))
 		os.Exit(1)
 	}
-	function := httpadapter.New(h).ProxyWithContext
+	function := httpadapter.New(requestid.Middleware(logger, timeouts.Middleware(h))).ProxyWithContext
 	lambda.Start(function)
 }
@@ -1,7 +1,7 @@
 package getrecruiterjobposts
 
 import (
-	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -11,6 +11,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
 	"github.com/josepheid/upfront/internal/respond"
 )
 
@@ -18,71 +22,133 @@ type Handler struct {
 	logger    *slog.Logger
 	tableName string
 	ddbc      *dynamodb.Client
+	companies repository.Companies
+	members   repository.Members
+	access    access.Checker
 }
 
 type RecruiterJobPostsRequest struct {
 	Email string `json:"email"`
 }
 
-func NewHandler(logger *slog.Logger, tableName string, ddbc *dynamodb.Client) (Handler, error) {
+func NewHandler(logger *slog.Logger, tableName string, ddbc *dynamodb.Client, companies repository.Companies, members repository.Members, checker access.Checker) (Handler, error) {
 	return Handler{
 		logger:    logger,
 		tableName: tableName,
 		ddbc:      ddbc,
+		companies: companies,
+		members:   members,
+		access:    checker,
 	}, nil
 }
 
 var matcher = pathvars.NewExtractor("*/upfront/recruiter-posts/{email}")
 
 func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
 	jobPosts := []models.JobPostItem{}
 
 	pathValues, ok := matcher.Extract(r.URL)
 	if !ok {
-		h.logger.Error("missing parameters in path")
-		respond.WithError(w, "missing parameters in path", http.StatusBadRequest)
+		logger.Error("missing parameters in path")
+		respond.WithError(w, r, respond.ValidationFailed("missing parameters in path"))
 		return
 	}
 
 	email, ok := pathValues["email"]
 	if !ok || email == "" {
-		h.logger.Error("missing email parameter in path")
-		respond.WithError(w, "missing email parameter in path", http.StatusBadRequest)
+		logger.Error("missing email parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("email is required"))
 		return
 	}
 
-	h.logger.Info("email extracted", "email", email)
+	logger.Info("email extracted", "email", email)
+
+	// The email in the path only picks whose posts an admin is looking at, recruiters can only list
+	// their own.
+	if !identity.Owns(email) && !identity.IsAdmin() {
+		logger.Error("recruiter can not list another recruiter's job posts", "identity", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
 
 	// Build the expression using key condition and filter
 	keyEx := expression.Key("loginEmail").Equal(expression.Value(email))
+	// Drafts aren't job posts until they are checked out, so they aren't listed.
+	notDraft := expression.Name("status").NotEqual(expression.Value(models.Draft))
 
-	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).WithFilter(notDraft).Build()
 
 	if err != nil {
-		h.logger.Error("error building expression", "error", err)
-		respond.WithError(w, "error building expression", http.StatusInternalServerError)
+		logger.Error("error building expression", "error", err)
+		respond.WithError(w, r, respond.Internal())
 		return
 	}
 
-	data, err := h.ddbc.Query(context.TODO(), &dynamodb.QueryInput{
+	data, err := h.ddbc.Query(r.Context(), &dynamodb.QueryInput{
 		TableName:                 aws.String(h.tableName),
 		IndexName:                 aws.String("emailIndex"),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
 	})
 
 	if err != nil {
-		h.logger.Error("error querying email gsi", "error", err)
-		respond.WithError(w, "error querying email gsi", http.StatusInternalServerError)
+		logger.Error("error querying email gsi", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
 		return
 	}
 	err = attributevalue.UnmarshalListOfMaps(data.Items, &jobPosts)
 	if err != nil {
-		h.logger.Error("error unmarshalling list of maps", "error", err)
-		respond.WithError(w, "error unmarshalling list of maps", http.StatusInternalServerError)
+		logger.Error("error unmarshalling list of maps", "error", err)
+		respond.WithError(w, r, respond.Internal())
 		return
 	}
 
+	// Colleagues' posts are included, so nobody loses sight of them when a recruiter leaves.
+	memberships, err := h.members.Memberships(r.Context(), email)
+	if err != nil {
+		logger.Error("error getting memberships", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	seen := map[string]bool{}
+	for _, p := range jobPosts {
+		seen[p.JobID] = true
+	}
+	for _, m := range memberships {
+		if !m.Active() {
+			continue
+		}
+		err := h.access.Company(r.Context(), identity, m.CompanyID, models.Viewer)
+		if errors.Is(err, access.ErrForbidden) {
+			continue
+		}
+		if err != nil {
+			logger.Error("error checking access", "error", err, "companyID", m.CompanyID)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		companyPosts, err := h.companies.JobPosts(r.Context(), m.CompanyID, expression.ConditionBuilder{})
+		if err != nil {
+			logger.Error("error getting company job posts", "error", err, "companyID", m.CompanyID)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		for _, p := range companyPosts {
+			if !seen[p.JobID] {
+				seen[p.JobID] = true
+				jobPosts = append(jobPosts, p)
+			}
+		}
+	}
+
 	respond.WithJSON(w, jobPosts, http.StatusOK)
 }
@@ -0,0 +1,73 @@
+package getreports
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+	reports  repository.Reports
+}
+
+// ReportedJobPost is a job post with open reports and every report made about it.
+type ReportedJobPost struct {
+	models.JobPostItem
+	Reports []models.Report `json:"reports"`
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, reports repository.Reports) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+		reports:  reports,
+	}, nil
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+	if !identity.IsAdmin() {
+		logger.Error("admin route called by a non admin", "email", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	posts, err := h.jobPosts.Find(r.Context(), expression.Name("openReportCount").GreaterThan(expression.Value(0)))
+	if err != nil {
+		logger.Error("error getting reported job posts", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	// The most reported posts are looked at first.
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].OpenReportCount > posts[j].OpenReportCount
+	})
+
+	response := make([]ReportedJobPost, len(posts))
+	for i, post := range posts {
+		reports, err := h.reports.List(r.Context(), post.JobID)
+		if err != nil {
+			logger.Error("error getting reports", "error", err, "id", post.JobID)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		response[i] = ReportedJobPost{JobPostItem: post, Reports: reports}
+	}
+
+	respond.WithJSON(w, response, http.StatusOK)
+}
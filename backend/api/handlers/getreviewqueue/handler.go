@@ -0,0 +1,71 @@
+package getreviewqueue
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+}
+
+type ReviewQueueResponse struct {
+	// Pending posts are waiting for a moderator, longest waiting first.
+	Pending []models.JobPostItem `json:"pending"`
+	// AwaitingChanges posts are waiting for their recruiter to make the changes a moderator asked for.
+	AwaitingChanges []models.JobPostItem `json:"awaitingChanges"`
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+	}, nil
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+	if !identity.IsAdmin() {
+		logger.Error("admin route called by a non admin", "email", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	posts, err := h.jobPosts.WithStatus(r.Context(), models.PendingReview)
+	if err != nil {
+		logger.Error("error getting job posts pending review", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].ReviewRequestedAt < posts[j].ReviewRequestedAt
+	})
+
+	response := ReviewQueueResponse{
+		Pending:         []models.JobPostItem{},
+		AwaitingChanges: []models.JobPostItem{},
+	}
+	for _, post := range posts {
+		if post.AwaitingChanges {
+			response.AwaitingChanges = append(response.AwaitingChanges, post)
+			continue
+		}
+		response.Pending = append(response.Pending, post)
+	}
+
+	respond.WithJSON(w, response, http.StatusOK)
+}
@@ -0,0 +1,178 @@
+package invitecompanymember
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/accounts"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/origins"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	companies repository.Companies
+	members   repository.Members
+	access    access.Checker
+	accounts  accounts.Accounts
+	ses       *ses.Client
+	origins   origins.Allowlist
+}
+
+type InviteCompanyMemberRequest struct {
+	Email         string      `json:"email"`
+	Role          models.Role `json:"role"`
+	RequestOrigin string      `json:"requestOrigin"`
+}
+
+func NewHandler(logger *slog.Logger, companies repository.Companies, members repository.Members, checker access.Checker, recruiterAccounts accounts.Accounts, sesClient *ses.Client, allowedOrigins origins.Allowlist) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		companies: companies,
+		members:   members,
+		access:    checker,
+		accounts:  recruiterAccounts,
+		ses:       sesClient,
+		origins:   allowedOrigins,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/companies/{slug}/invitations")
+
+const (
+	// invitationPath is the frontend page that accepts an invitation, it is joined to an allowed origin.
+	invitationPath = "/invitations"
+	// senderEmail sends invitations, the same address as the magic link emails.
+	senderEmail = "josephceid@gmail.com"
+)
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["slug"] == "" {
+		logger.Error("missing slug parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("slug is required"))
+		return
+	}
+	slug := pathValues["slug"]
+	logger = logger.With("slug", slug)
+
+	var request InviteCompanyMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+
+	var issues []string
+	if address, err := mail.ParseAddress(request.Email); err != nil || address.Address != request.Email {
+		issues = append(issues, "email must be an email address")
+	}
+	if !request.Role.Valid() {
+		issues = append(issues, fmt.Sprintf("role must be one of %v", models.Roles))
+	}
+	if len(issues) > 0 {
+		logger.Error("invalid invitation", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
+		return
+	}
+
+	origin, err := h.origins.Resolve(request.RequestOrigin)
+	if err != nil {
+		logger.Error("request origin not allowed", "error", err)
+		respond.WithError(w, r, respond.OriginNotAllowed(fmt.Sprintf("requestOrigin %q is not an allowed origin", request.RequestOrigin)))
+		return
+	}
+
+	company, err := h.companies.GetBySlug(r.Context(), slug)
+	if errors.Is(err, repository.ErrCompanyNotFound) {
+		logger.Error("company not found")
+		respond.WithError(w, r, respond.CompanyNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting company", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.Company(r.Context(), identity, company.CompanyID, models.Owner)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter is not an owner of the company")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	invitation := models.NewInvitation(company.CompanyID, request.Email, request.Role, identity.Email, time.Now())
+	err = h.members.Invite(r.Context(), invitation)
+	if errors.Is(err, repository.ErrAlreadyMember) {
+		logger.Error("recruiter is already a member")
+		respond.WithError(w, r, respond.AlreadyMember())
+		return
+	}
+	if err != nil {
+		logger.Error("error storing invitation", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// Invited recruiters sign in with a magic link like everyone else, so they need a user.
+	if err := h.accounts.Ensure(r.Context(), invitation.Email); err != nil {
+		logger.Error("error ensuring user in userpool", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	link := origins.URL(origin, invitationPath, url.Values{"company": {company.Slug}})
+	emailBody := fmt.Sprintf(`<h1>You have been invited to join %s on Upfront as %s.</h1><br/><br/>
+	<a href='%s'>Accept the invitation</a><br/><br/>The invitation expires in %d days.`,
+		html.EscapeString(company.Name), invitation.Role, link, int(models.InvitationTTL.Hours()/24))
+	_, err = h.ses.SendEmail(r.Context(), &ses.SendEmailInput{
+		Destination: &types.Destination{
+			ToAddresses: []string{invitation.Email},
+		},
+		Message: &types.Message{
+			Subject: &types.Content{Data: aws.String(fmt.Sprintf("Join %s on Upfront", strings.TrimSpace(company.Name)))},
+			Body:    &types.Body{Html: &types.Content{Data: aws.String(emailBody)}},
+		},
+		Source: aws.String(senderEmail),
+	})
+	if err != nil {
+		// The invitation is stored, inviting again resends the email.
+		logger.Error("error sending invitation email via ses", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	respond.WithJSON(w, invitation, http.StatusCreated)
+}
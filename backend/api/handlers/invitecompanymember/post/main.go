@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+	"github.com/josepheid/upfront/api/handlers/invitecompanymember"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/accounts"
+	"github.com/josepheid/upfront/internal/origins"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/timeouts"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	region := "eu-west-2"
+
+	ctx := context.Background()
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithAPIOptions(requestid.AWSAPIOptions))
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
+	upfrontTableName := os.Getenv("UPFRONT_TABLE_NAME")
+
+	// If the environment variable is not set
+	if upfrontTableName == "" {
+		logger.Error("environment variable UPFRONT_TABLE_NAME is not set", "error", err)
+		os.Exit(1)
+	}
+
+	userPoolId := os.Getenv("USER_POOL_ID")
+
+	// If the environment variable is not set
+	if userPoolId == "" {
+		logger.Error("environment variable USER_POOL_ID is not set", "error", err)
+		os.Exit(1)
+	}
+
+	allowedOrigins, err := origins.NewAllowlist(os.Getenv("ALLOWED_ORIGINS"))
+	if err != nil {
+		logger.Error("environment variable ALLOWED_ORIGINS is not valid", "error", err)
+		os.Exit(1)
+	}
+
+	ddbc := dynamodb.NewFromConfig(config, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.DynamoDB))
+	})
+
+	cipc := cognitoidentityprovider.NewFromConfig(config, func(o *cognitoidentityprovider.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.Cognito))
+	})
+
+	sesc := ses.NewFromConfig(config, func(o *ses.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.SES))
+	})
+
+	members := repository.NewMembers(ddbc, upfrontTableName)
+	h, err := invitecompanymember.NewHandler(logger, repository.NewCompanies(ddbc, upfrontTableName), members, access.NewChecker(members), accounts.NewAccounts(cipc, userPoolId), sesc, allowedOrigins)
+	if err != nil {
+		logger.Error("could not create handler", slog.Any("error", err))
+		os.Exit(1)
+	}
+	function := httpadapter.New(requestid.Middleware(logger, timeouts.Middleware(h))).ProxyWithContext
+	lambda.Start(function)
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
 )
 
 func main() {
-	handler := http.NotFoundHandler()
-	function := httpadapter.New(handler).ProxyWithContext
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond.WithError(w, r, respond.RouteNotFound())
+	})
+	function := httpadapter.New(requestid.Middleware(logger, handler)).ProxyWithContext
 	lambda.Start(function)
 }
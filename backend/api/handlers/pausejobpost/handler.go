@@ -0,0 +1,134 @@
+package pausejobpost
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+	access   access.Checker
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+		access:   checker,
+	}, nil
+}
+
+// The same lambda pauses and resumes posts.
+var (
+	pauseMatcher  = pathvars.NewExtractor("*/upfront/job-posts/{id}/pause")
+	resumeMatcher = pathvars.NewExtractor("*/upfront/job-posts/{id}/resume")
+)
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, resume := resumeMatcher.Extract(r.URL)
+	if !resume {
+		pathValues, _ = pauseMatcher.Extract(r.URL)
+	}
+	id := pathValues["id"]
+	if id == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	logger = logger.With("id", id, "resume", resume)
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.JobPost(r.Context(), identity, item, models.Editor)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter can not edit the job post")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	before := item.Snapshot()
+	now := time.Now()
+	action := models.AuditPaused
+	if resume {
+		action = models.AuditResumed
+		err = item.Resume(now)
+	} else {
+		err = item.Pause(now)
+	}
+	if errors.Is(err, models.ErrPauseLimit) {
+		logger.Error("job post has no pause time left", "pausedSeconds", item.PausedSeconds)
+		respond.WithError(w, r, respond.PauseLimitReached())
+		return
+	}
+	if errors.Is(err, models.ErrNotPaused) {
+		logger.Error("job post is not paused", "status", item.Status)
+		respond.WithError(w, r, respond.InvalidJobStatus(fmt.Sprintf("only %s job posts can be resumed", models.Paused)))
+		return
+	}
+	if resume && errors.Is(err, models.ErrNoTimeLeft) {
+		logger.Error("job post ran out of time while paused", "expiresAt", item.ExpiresAt)
+		respond.WithError(w, r, respond.InvalidJobStatus("the job post's paid time ran out while it was paused, it can be renewed once it has expired"))
+		return
+	}
+	var transitionErr models.TransitionError
+	if errors.Is(err, models.ErrAlreadyPaused) || errors.As(err, &transitionErr) {
+		logger.Error("job post can't be paused", "status", item.Status, "error", err)
+		respond.WithError(w, r, respond.InvalidJobStatus(fmt.Sprintf("only %s job posts with time left can be paused", models.Active)))
+		return
+	}
+	if err != nil {
+		logger.Error("error pausing or resuming job post", "error", err)
+		respond.WithError(w, r, respond.Internal())
+		return
+	}
+
+	err = h.jobPosts.Save(r.Context(), &item, item.Audit(action, identity.Email, before, now))
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while pausing or resuming")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	logger.Info("paused or resumed job post", "status", item.Status, "expiresAt", item.ExpiresAt)
+
+	respond.WithJSON(w, item, http.StatusOK)
+}
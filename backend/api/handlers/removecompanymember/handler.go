@@ -0,0 +1,113 @@
+package removecompanymember
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	companies repository.Companies
+	members   repository.Members
+	access    access.Checker
+}
+
+func NewHandler(logger *slog.Logger, companies repository.Companies, members repository.Members, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		companies: companies,
+		members:   members,
+		access:    checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/companies/{slug}/members/{email}")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	email, err := url.PathUnescape(pathValues["email"])
+	if !ok || err != nil || pathValues["slug"] == "" || email == "" {
+		logger.Error("missing parameters in path")
+		respond.WithError(w, r, respond.ValidationFailed("slug and email are required"))
+		return
+	}
+	slug := pathValues["slug"]
+	logger = logger.With("slug", slug, "email", email)
+
+	company, err := h.companies.GetBySlug(r.Context(), slug)
+	if errors.Is(err, repository.ErrCompanyNotFound) {
+		logger.Error("company not found")
+		respond.WithError(w, r, respond.CompanyNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting company", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// Anyone can leave a company, only owners can remove someone else.
+	if !identity.Owns(email) {
+		err = h.access.Company(r.Context(), identity, company.CompanyID, models.Owner)
+		if errors.Is(err, access.ErrForbidden) {
+			logger.Error("recruiter is not an owner of the company")
+			respond.WithError(w, r, respond.Forbidden())
+			return
+		}
+		if err != nil {
+			logger.Error("error checking access", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+	}
+
+	member, err := h.members.Get(r.Context(), company.CompanyID, email)
+	if errors.Is(err, repository.ErrMemberNotFound) {
+		logger.Error("member not found")
+		respond.WithError(w, r, respond.MemberNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting member", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// Posts the member made stay with the company, an owner can transfer them to someone else.
+	err = h.members.Remove(r.Context(), member)
+	if errors.Is(err, repository.ErrLastOwner) {
+		logger.Error("can't remove the last owner")
+		respond.WithError(w, r, respond.LastOwner())
+		return
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("member changed while removing")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error removing member", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
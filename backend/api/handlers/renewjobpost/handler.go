@@ -0,0 +1,177 @@
+package renewjobpost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/origins"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	payments payments.Provider
+	jobPosts repository.JobPosts
+	origins  origins.Allowlist
+	access   access.Checker
+}
+
+type RenewJobPostRequest struct {
+	PlanDuration  int    `json:"planDuration"`
+	RequestOrigin string `json:"requestOrigin"`
+}
+
+type RenewJobPostResponse struct {
+	URL string `json:"url"`
+}
+
+func NewHandler(logger *slog.Logger, provider payments.Provider, jobPosts repository.JobPosts, allowedOrigins origins.Allowlist, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		payments: provider,
+		jobPosts: jobPosts,
+		origins:  allowedOrigins,
+		access:   checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/job-posts/{id}/renew")
+
+// Paths on the frontend that Stripe redirects back to, joined to the request's allowed origin.
+const (
+	successPath = "/success"
+	cancelPath  = "/dashboard"
+)
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	var request RenewJobPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+
+	origin, err := h.origins.Resolve(request.RequestOrigin)
+	if err != nil {
+		logger.Error("request origin not allowed", "error", err)
+		respond.WithError(w, r, respond.OriginNotAllowed(fmt.Sprintf("requestOrigin %q is not an allowed origin", request.RequestOrigin)))
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.JobPost(r.Context(), identity, item, models.Editor)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter can not edit the job post")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	if item.Status != models.Active && item.Status != models.Expired {
+		logger.Error("job post can't be renewed", "status", item.Status)
+		respond.WithError(w, r, respond.InvalidJobStatus(fmt.Sprintf("only %s and %s job posts can be renewed", models.Active, models.Expired)))
+		return
+	}
+
+	// Renewals keep the post's plan, only the duration is chosen.
+	if issues := models.ValidatePlan(item.PlanType, request.PlanDuration); len(issues) > 0 {
+		logger.Error("invalid plan", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
+		return
+	}
+	amount := models.Price(item.PlanType, request.PlanDuration)
+
+	checkoutSession, err := h.payments.CreateCheckout(r.Context(), payments.CheckoutParams{
+		JobID:         item.JobID,
+		ProductName:   fmt.Sprintf("%s plan renewal for %d days.", item.PlanType, request.PlanDuration),
+		Amount:        amount,
+		CustomerEmail: item.LoginEmail,
+		SuccessURL:    origins.URL(origin, successPath, url.Values{"id": {item.JobID}}),
+		CancelURL:     origins.URL(origin, cancelPath, nil),
+		Metadata:      map[string]string{"purchase_kind": string(models.Renewal)},
+	})
+	if err != nil {
+		logger.Error("error creating checkout", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	now := time.Now()
+	before := item.Snapshot()
+	item.BillingHistory = append(item.Purchases(), models.Purchase{
+		SessionID:    checkoutSession.ID,
+		Kind:         models.Renewal,
+		PlanType:     item.PlanType,
+		PlanDuration: request.PlanDuration,
+		Amount:       amount,
+		Currency:     models.BillingCurrency,
+		Status:       models.PurchasePending,
+		CreatedAt:    now.Format(time.RFC3339),
+	})
+	item.UpdatedAt = now.Format(time.RFC3339)
+
+	err = h.jobPosts.Save(r.Context(), &item, item.Audit(models.AuditCheckoutStarted, identity.Email, before, now))
+	if err != nil {
+		// Nobody can pay for a checkout the post doesn't know about.
+		if expireErr := h.payments.ExpireCheckout(r.Context(), checkoutSession.ID); expireErr != nil {
+			logger.Error("error expiring orphaned checkout", "error", expireErr, "sessionID", checkoutSession.ID)
+		}
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while renewing")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	respond.WithJSON(w, RenewJobPostResponse{URL: checkoutSession.URL}, http.StatusCreated)
+}
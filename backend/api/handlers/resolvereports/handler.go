@@ -0,0 +1,102 @@
+package resolvereports
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+	reports  repository.Reports
+}
+
+type ResolveReportsRequest struct {
+	Outcome models.ReportOutcome `json:"outcome"`
+	// Note is kept with the reports for other admins, it isn't sent to anyone.
+	Note string `json:"note"`
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, reports repository.Reports) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+		reports:  reports,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/admin/job-posts/{id}/reports/resolve")
+
+// ServeHTTP closes the open reports of a job post. Taking the post down, or putting a held post
+// back live, is done by reviewing it.
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+	if !identity.IsAdmin() {
+		logger.Error("admin route called by a non admin", "email", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	var request ResolveReportsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+	request.Note = strings.TrimSpace(request.Note)
+	if request.Outcome != models.ReportDismissed && request.Outcome != models.ReportUpheld {
+		logger.Error("invalid outcome")
+		respond.WithError(w, r, respond.ValidationFailed(fmt.Sprintf("outcome must be %q or %q", models.ReportDismissed, models.ReportUpheld)))
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	resolved, err := h.reports.Resolve(r.Context(), item, request.Outcome, request.Note, identity.Email, time.Now())
+	if err != nil {
+		logger.Error("error resolving reports", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	logger.Info("resolved reports", "outcome", request.Outcome, "count", len(resolved))
+
+	respond.WithJSON(w, resolved, http.StatusOK)
+}
@@ -0,0 +1,181 @@
+package reviewjobpost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/moderation"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	payments payments.Provider
+	jobPosts repository.JobPosts
+	notifier moderation.Notifier
+}
+
+type ReviewJobPostRequest struct {
+	Decision models.ReviewDecision `json:"decision"`
+	// Reason is sent to the recruiter, it is required unless the post is approved.
+	Reason string `json:"reason"`
+}
+
+func NewHandler(logger *slog.Logger, provider payments.Provider, jobPosts repository.JobPosts, notifier moderation.Notifier) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		payments: provider,
+		jobPosts: jobPosts,
+		notifier: notifier,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/admin/job-posts/{id}/review")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+	if !identity.IsAdmin() {
+		logger.Error("admin route called by a non admin", "email", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	var request ReviewJobPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+	request.Reason = strings.TrimSpace(request.Reason)
+	switch request.Decision {
+	case models.Approved:
+	case models.Rejected, models.ChangesRequested:
+		if request.Reason == "" {
+			logger.Error("missing reason")
+			respond.WithError(w, r, respond.ValidationFailed(fmt.Sprintf("reason is required when the decision is %q", request.Decision)))
+			return
+		}
+	default:
+		logger.Error("invalid decision")
+		respond.WithError(w, r, respond.ValidationFailed(fmt.Sprintf("decision must be one of %q, %q or %q", models.Approved, models.Rejected, models.ChangesRequested)))
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// A removed post with pending refunds is a retry after a refund failed, the refunds are issued
+	// again and the recruiter isn't emailed twice.
+	retry := item.Status == models.Removed && request.Decision == models.Rejected && len(item.PendingRefunds()) > 0
+	if !retry {
+		before := item.Snapshot()
+		now := time.Now()
+		err = item.Review(now, identity.Email, request.Decision, request.Reason)
+		if errors.Is(err, models.ErrNotPendingReview) {
+			logger.Error("job post is not pending review", "status", item.Status)
+			respond.WithError(w, r, respond.InvalidJobStatus(fmt.Sprintf("only %s job posts can be reviewed", models.PendingReview)))
+			return
+		}
+		var transitionErr models.TransitionError
+		if errors.As(err, &transitionErr) {
+			logger.Error("job post can't be reviewed", "error", err)
+			respond.WithError(w, r, respond.InvalidJobStatus(err.Error()))
+			return
+		}
+		if err != nil {
+			logger.Error("error reviewing job post", "error", err)
+			respond.WithError(w, r, respond.Internal())
+			return
+		}
+		if !h.save(w, r, logger, &item, item.Audit(models.AuditReviewed, identity.Email, before, now)) {
+			return
+		}
+		logger.Info("reviewed job post", "decision", request.Decision, "refunds", len(item.PendingRefunds()))
+		review := item.Reviews[len(item.Reviews)-1]
+		if err := h.notifier.Notify(r.Context(), item, review); err != nil {
+			logger.Error("error emailing review decision", "error", err)
+		}
+	}
+
+	before := item.Snapshot()
+	var refundErr error
+	issued := false
+	for _, i := range item.PendingRefunds() {
+		pending := &item.Refunds[i]
+		result, err := h.payments.Refund(r.Context(), pending.SessionID, pending.Amount)
+		if err != nil {
+			logger.Error("error refunding purchase", "error", err, "sessionID", pending.SessionID)
+			refundErr = err
+			continue
+		}
+		now := time.Now().Format(time.RFC3339)
+		pending.RefundID = result.ID
+		pending.Status = models.RefundIssued
+		pending.IssuedAt = now
+		item.UpdatedAt = now
+		issued = true
+		logger.Info("refunded purchase", "sessionID", pending.SessionID, "refundID", result.ID, "amount", pending.Amount)
+	}
+	if issued && !h.save(w, r, logger, &item, item.Audit(models.AuditRefunded, identity.Email, before, time.Now())) {
+		return
+	}
+	if refundErr != nil {
+		// The post stays removed, rejecting it again retries the refunds that failed.
+		respond.WithError(w, r, respond.Upstream(refundErr))
+		return
+	}
+
+	respond.WithJSON(w, item, http.StatusOK)
+}
+
+// save writes item and responds with an error if it couldn't, it reports whether it succeeded.
+func (h Handler) save(w http.ResponseWriter, r *http.Request, logger *slog.Logger, item *models.JobPostItem, audit ...models.AuditEntry) bool {
+	err := h.jobPosts.Save(r.Context(), item, audit...)
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while reviewing")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return false
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return false
+	}
+	return true
+}
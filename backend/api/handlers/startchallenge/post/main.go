@@ -13,6 +13,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ses"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
 	"github.com/josepheid/upfront/api/handlers/startchallenge"
+	"github.com/josepheid/upfront/internal/magiclink"
+	"github.com/josepheid/upfront/internal/origins"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/timeouts"
 )
 
 func main() {
@@ -20,19 +25,33 @@ func main() {
 	region := "eu-west-2"
 
 	ctx := context.Background()
-	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithAPIOptions(requestid.AWSAPIOptions))
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
-	ses := ses.NewFromConfig(config)
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
+	ses := ses.NewFromConfig(config, func(o *ses.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.SES))
+	})
 
-	ddbc := dynamodb.NewFromConfig(config)
+	ddbc := dynamodb.NewFromConfig(config, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.DynamoDB))
+	})
 
-	kmsc := kms.NewFromConfig(config)
+	kmsc := kms.NewFromConfig(config, func(o *kms.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.KMS))
+	})
 
-	cipc := cognitoidentityprovider.NewFromConfig(config)
+	cipc := cognitoidentityprovider.NewFromConfig(config, func(o *cognitoidentityprovider.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.Cognito))
+	})
 
 	upfrontTableName := os.Getenv("UPFRONT_TABLE_NAME")
 
@@ -57,11 +76,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	h, err := startchallenge.NewHandler(logger, ses, ddbc, kmsc, cipc, upfrontTableName, kmsKeyID, userPoolId)
+	allowedOrigins, err := origins.NewAllowlist(os.Getenv("ALLOWED_ORIGINS"))
+	if err != nil {
+		logger.Error("environment variable ALLOWED_ORIGINS is not valid", "error", err)
+		os.Exit(1)
+	}
+
+	h, err := startchallenge.NewHandler(logger, ddbc, magiclink.NewSender(ses, kmsc, cipc, kmsKeyID, userPoolId), upfrontTableName, allowedOrigins, repository.NewMembers(ddbc, upfrontTableName))
 	if err != nil {
 		logger.Error("could not create handler", slog.Any("error", err))
 		os.Exit(1)
 	}
-	function := httpadapter.New(h).ProxyWithContext
+	function := httpadapter.New(requestid.Middleware(logger, timeouts.Middleware(h))).ProxyWithContext
 	lambda.Start(function)
 }
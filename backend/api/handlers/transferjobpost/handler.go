@@ -0,0 +1,128 @@
+package transferjobpost
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+	members  repository.Members
+	access   access.Checker
+}
+
+type TransferJobPostRequest struct {
+	// LoginEmail is the member who will own the post, they must be an editor or owner.
+	LoginEmail string `json:"loginEmail"`
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, members repository.Members, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+		members:  members,
+		access:   checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/job-posts/{id}/transfer")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	var request TransferJobPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	if item.CompanyID == "" {
+		logger.Error("job post has no company")
+		respond.WithError(w, r, respond.ValidationFailed("only job posts that belong to a company can be transferred"))
+		return
+	}
+
+	err = h.access.JobPost(r.Context(), identity, item, models.Owner)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter is not an owner of the company")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	member, err := h.members.Get(r.Context(), item.CompanyID, request.LoginEmail)
+	if err != nil && !errors.Is(err, repository.ErrMemberNotFound) {
+		logger.Error("error getting member", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	if err != nil || !member.Active() || !member.Role.AtLeast(models.Editor) {
+		logger.Error("new owner can't edit the company's posts", "loginEmail", request.LoginEmail)
+		respond.WithError(w, r, respond.ValidationFailed("loginEmail must be an editor or owner of the company"))
+		return
+	}
+
+	before := item.Snapshot()
+	now := time.Now()
+	item.LoginEmail = strings.ToLower(member.Email)
+	item.UpdatedAt = now.Format(time.RFC3339)
+	err = h.jobPosts.Save(r.Context(), &item, item.Audit(models.AuditTransferred, identity.Email, before, now))
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while transferring")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	respond.WithJSON(w, item, http.StatusOK)
+}
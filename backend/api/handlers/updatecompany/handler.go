@@ -0,0 +1,127 @@
+package updatecompany
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	companies repository.Companies
+	access    access.Checker
+}
+
+func NewHandler(logger *slog.Logger, companies repository.Companies, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		companies: companies,
+		access:    checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/companies/{slug}")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["slug"] == "" {
+		logger.Error("missing slug parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("slug is required"))
+		return
+	}
+	slug := pathValues["slug"]
+	logger = logger.With("slug", slug)
+
+	var request models.CompanyProfile
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+
+	if issues := request.Validate(); len(issues) > 0 {
+		logger.Error("invalid company profile", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
+		return
+	}
+
+	company, err := h.companies.GetBySlug(r.Context(), slug)
+	if errors.Is(err, repository.ErrCompanyNotFound) {
+		logger.Error("company not found")
+		respond.WithError(w, r, respond.CompanyNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting company", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.Company(r.Context(), identity, company.CompanyID, models.Editor)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter can not edit the company")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// The slug is kept when the name changes, so links to the profile keep working.
+	previousUpdatedAt := company.UpdatedAt
+	now := time.Now()
+	company.Apply(request)
+	company.UpdatedAt = now.Format(time.RFC3339)
+
+	err = h.companies.Save(r.Context(), company, previousUpdatedAt)
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("company changed while updating")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error saving company", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// Posts keep a copy of the company's details for the listing, so every post is updated. A
+	// failure leaves some posts with the old details until the profile is saved again.
+	jobPosts, err := h.companies.JobPosts(r.Context(), company.CompanyID, expression.ConditionBuilder{})
+	if err != nil {
+		logger.Error("error getting company job posts", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	for _, post := range jobPosts {
+		if err := h.companies.CopyToJobPost(r.Context(), company, post, now); err != nil {
+			logger.Error("error updating job post company", "error", err, "jobID", post.JobID)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+	}
+
+	respond.WithJSON(w, company, http.StatusOK)
+}
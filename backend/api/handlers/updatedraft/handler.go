@@ -0,0 +1,103 @@
+package updatedraft
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		jobPosts: jobPosts,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/drafts/{id}")
+
+// ServeHTTP replaces the draft's form for its owner. The draft stays tied to the email it was saved
+// with, a different loginEmail in the form is ignored.
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	var request models.JobPostFormProps
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("draft not found")
+		respond.WithError(w, r, respond.DraftNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting draft", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	now := time.Now()
+	if !item.IsDraft(now) {
+		logger.Error("job post isn't a draft", "status", item.Status)
+		respond.WithError(w, r, respond.DraftNotFound())
+		return
+	}
+
+	// A draft belongs to the email it was saved with, only that recruiter signed in can reach it.
+	if !identity.Owns(item.LoginEmail) {
+		logger.Error("recruiter doesn't own the draft", "identity", identity.Email)
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+
+	before := item.Snapshot()
+	if err := item.EditDraft(request, now); err != nil {
+		logger.Error("error editing draft", "error", err)
+		respond.WithError(w, r, respond.DraftNotFound())
+		return
+	}
+	err = h.jobPosts.Save(r.Context(), &item, item.Audit(models.AuditEdited, identity.Email, before, now))
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("draft changed while being updated")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error saving draft", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	logger.Info("updated draft")
+
+	respond.WithJSON(w, item.Form(), http.StatusOK)
+}
@@ -0,0 +1,147 @@
+package updatejobpost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/duplicates"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+	"github.com/josepheid/upfront/internal/scoring"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	jobPosts repository.JobPosts
+	access   access.Checker
+	// moderated sends edited posts back for review, so an approved post can't be changed into
+	// something that wouldn't have been. Without it only edits the scorer flags are reviewed.
+	moderated bool
+	scorer    scoring.Scorer
+	detector  duplicates.Detector
+}
+
+func NewHandler(logger *slog.Logger, jobPosts repository.JobPosts, checker access.Checker, moderated bool, scorer scoring.Scorer, detector duplicates.Detector) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		jobPosts:  jobPosts,
+		access:    checker,
+		moderated: moderated,
+		scorer:    scorer,
+		detector:  detector,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/job-posts/{id}")
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	var request models.JobPostDetails
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+	if issues := request.Validate(); len(issues) > 0 {
+		logger.Error("invalid job post details", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.JobPost(r.Context(), identity, item, models.Editor)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter can not edit the job post")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	if item.Status != models.Active && item.Status != models.Paused && item.Status != models.PendingReview {
+		logger.Error("job post can't be edited", "status", item.Status)
+		respond.WithError(w, r, respond.InvalidJobStatus(fmt.Sprintf("only %s, %s and %s job posts can be edited", models.Active, models.Paused, models.PendingReview)))
+		return
+	}
+
+	before := item.Snapshot()
+	now := time.Now()
+	item.Edit(request, now)
+	contentScore := h.scorer.Score(item.JobPostFormProps, now)
+	item.ContentScore = &contentScore
+	logger.Info("scored job post", "score", contentScore.Score, "needsReview", contentScore.NeedsReview)
+	item.MinHash = duplicates.Signature(item.Title, item.Description)
+	item.PossibleDuplicates, err = h.detector.Find(r.Context(), item)
+	if err != nil {
+		logger.Error("error finding duplicate job posts", "error", err)
+	}
+	// A post already in review stays in the queue, with the time it has spent there so far.
+	audit := []models.AuditEntry{item.Audit(models.AuditEdited, identity.Email, before, now)}
+	inReview := item.Status == models.PendingReview
+	if inReview || h.moderated || item.Flagged() {
+		edited := item.Snapshot()
+		if err := item.HoldForReview(now); err != nil {
+			logger.Error("error holding job post for review", "error", err)
+			respond.WithError(w, r, respond.Internal())
+			return
+		}
+		if !inReview {
+			audit = append(audit, item.Audit(models.AuditHeldForReview, models.ModerationActor, edited, now))
+		}
+	}
+
+	err = h.jobPosts.Save(r.Context(), &item, audit...)
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while editing")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+	logger.Info("edited job post", "status", item.Status)
+
+	respond.WithJSON(w, item, http.StatusOK)
+}
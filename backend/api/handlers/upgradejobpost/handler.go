@@ -0,0 +1,221 @@
+package upgradejobpost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/origins"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+	"github.com/stripe/stripe-go/v80"
+)
+
+type Handler struct {
+	logger   *slog.Logger
+	payments payments.Provider
+	jobPosts repository.JobPosts
+	origins  origins.Allowlist
+	access   access.Checker
+}
+
+type UpgradeJobPostRequest struct {
+	PlanType      models.PlanType `json:"planType"`
+	RequestOrigin string          `json:"requestOrigin"`
+}
+
+type UpgradeJobPostResponse struct {
+	URL string `json:"url"`
+	// Amount is the prorated price in pence.
+	Amount int64 `json:"amount"`
+}
+
+func NewHandler(logger *slog.Logger, provider payments.Provider, jobPosts repository.JobPosts, allowedOrigins origins.Allowlist, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:   logger,
+		payments: provider,
+		jobPosts: jobPosts,
+		origins:  allowedOrigins,
+		access:   checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/job-posts/{id}/upgrade")
+
+// Paths on the frontend that Stripe redirects back to, joined to the request's allowed origin.
+const (
+	successPath = "/success"
+	cancelPath  = "/dashboard"
+)
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["id"] == "" {
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
+		return
+	}
+	id := pathValues["id"]
+	logger = logger.With("id", id)
+
+	var request UpgradeJobPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+
+	origin, err := h.origins.Resolve(request.RequestOrigin)
+	if err != nil {
+		logger.Error("request origin not allowed", "error", err)
+		respond.WithError(w, r, respond.OriginNotAllowed(fmt.Sprintf("requestOrigin %q is not an allowed origin", request.RequestOrigin)))
+		return
+	}
+
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.JobPost(r.Context(), identity, item, models.Editor)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter can not edit the job post")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	if item.Status != models.Active {
+		logger.Error("job post can't be upgraded", "status", item.Status)
+		respond.WithError(w, r, respond.InvalidJobStatus(fmt.Sprintf("only %s job posts can be upgraded", models.Active)))
+		return
+	}
+
+	// Paying for two upgrades would charge twice for the same days, so only an abandoned upgrade
+	// checkout can be replaced.
+	now := time.Now()
+	var audit []models.AuditEntry
+	if i, ok := item.PendingPurchase(models.Upgrade); ok {
+		pending := item.Purchases()[i]
+		checkoutSession, err := h.payments.GetCheckout(r.Context(), pending.SessionID)
+		if err != nil {
+			logger.Error("error retrieving session", "error", err, "sessionID", pending.SessionID)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		if checkoutSession.Status != stripe.CheckoutSessionStatusExpired {
+			logger.Error("job post has an upgrade pending", "sessionID", pending.SessionID, "checkoutStatus", checkoutSession.Status)
+			respond.WithError(w, r, respond.InvalidJobStatus("the job post already has an upgrade checkout, pay for it or wait for it to expire"))
+			return
+		}
+		before := item.Snapshot()
+		if err := item.ExpirePurchase(i, now); err != nil {
+			logger.Error("error expiring abandoned upgrade", "error", err, "sessionID", pending.SessionID)
+			respond.WithError(w, r, respond.Internal())
+			return
+		}
+		audit = append(audit, item.Audit(models.AuditPurchaseExpired, models.PaymentsActor, before, now))
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt)
+	if err != nil {
+		logger.Error("error parsing expiresAt", "error", err, "expiresAt", item.ExpiresAt)
+		respond.WithError(w, r, respond.Internal())
+		return
+	}
+	amount, err := models.UpgradePrice(item.PlanType, request.PlanType, expiresAt, now)
+	switch {
+	case errors.Is(err, models.ErrNotAnUpgrade):
+		logger.Error("plan is not an upgrade", "from", item.PlanType, "to", request.PlanType)
+		respond.WithError(w, r, respond.ValidationFailed(fmt.Sprintf("planType %q is not an upgrade from %q", request.PlanType, item.PlanType)))
+		return
+	case errors.Is(err, models.ErrNoTimeRemaining):
+		logger.Error("job post has no time remaining", "expiresAt", item.ExpiresAt)
+		respond.WithError(w, r, respond.InvalidJobStatus("the job post has expired, renew it instead"))
+		return
+	case err != nil:
+		logger.Error("error pricing upgrade", "error", err)
+		respond.WithError(w, r, respond.Internal())
+		return
+	}
+	days := models.RemainingDays(expiresAt, now)
+
+	checkoutSession, err := h.payments.CreateCheckout(r.Context(), payments.CheckoutParams{
+		JobID:         item.JobID,
+		ProductName:   fmt.Sprintf("Upgrade to %s plan for the remaining %d days.", request.PlanType, days),
+		Amount:        amount,
+		CustomerEmail: item.LoginEmail,
+		SuccessURL:    origins.URL(origin, successPath, url.Values{"id": {item.JobID}}),
+		CancelURL:     origins.URL(origin, cancelPath, nil),
+		Metadata:      map[string]string{"purchase_kind": string(models.Upgrade)},
+	})
+	if err != nil {
+		logger.Error("error creating checkout", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	before := item.Snapshot()
+	item.BillingHistory = append(item.Purchases(), models.Purchase{
+		SessionID:    checkoutSession.ID,
+		Kind:         models.Upgrade,
+		PlanType:     request.PlanType,
+		PlanDuration: days,
+		Amount:       amount,
+		Currency:     models.BillingCurrency,
+		Status:       models.PurchasePending,
+		CreatedAt:    now.Format(time.RFC3339),
+	})
+	item.UpdatedAt = now.Format(time.RFC3339)
+
+	audit = append(audit, item.Audit(models.AuditCheckoutStarted, identity.Email, before, now))
+	err = h.jobPosts.Save(r.Context(), &item, audit...)
+	if err != nil {
+		// Nobody can pay for a checkout the post doesn't know about.
+		if expireErr := h.payments.ExpireCheckout(r.Context(), checkoutSession.ID); expireErr != nil {
+			logger.Error("error expiring orphaned checkout", "error", expireErr, "sessionID", checkoutSession.ID)
+		}
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		logger.Error("job post changed while upgrading")
+		respond.WithError(w, r, respond.ConcurrentUpdate())
+		return
+	}
+	if err != nil {
+		logger.Error("error updating item", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	respond.WithJSON(w, UpgradeJobPostResponse{URL: checkoutSession.URL, Amount: amount}, http.StatusCreated)
+}
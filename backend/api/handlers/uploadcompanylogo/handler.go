@@ -0,0 +1,133 @@
+package uploadcompanylogo
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/a-h/pathvars"
+	"github.com/google/uuid"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/access"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/blobstore"
+	"github.com/josepheid/upfront/internal/logos"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	companies repository.Companies
+	uploads   blobstore.S3
+	access    access.Checker
+}
+
+type UploadCompanyLogoRequest struct {
+	ContentType   string `json:"contentType"`
+	ContentLength int64  `json:"contentLength"`
+}
+
+type UploadCompanyLogoResponse struct {
+	UploadID  string `json:"uploadID"`
+	UploadURL string `json:"uploadURL"`
+	// Headers must be sent with the PUT to UploadURL, they are part of its signature.
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt string            `json:"expiresAt"`
+}
+
+func NewHandler(logger *slog.Logger, companies repository.Companies, uploads blobstore.S3, checker access.Checker) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		companies: companies,
+		uploads:   uploads,
+		access:    checker,
+	}, nil
+}
+
+var matcher = pathvars.NewExtractor("*/upfront/companies/{slug}/logo")
+
+// uploadExpiry is how long a presigned upload URL can be used for.
+const uploadExpiry = 15 * time.Minute
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
+	identity, err := auth.FromRequest(r)
+	if err != nil {
+		logger.Error("request is not authenticated", "error", err)
+		respond.WithError(w, r, respond.Unauthenticated())
+		return
+	}
+
+	pathValues, ok := matcher.Extract(r.URL)
+	if !ok || pathValues["slug"] == "" {
+		logger.Error("missing slug parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("slug is required"))
+		return
+	}
+	slug := pathValues["slug"]
+	logger = logger.With("slug", slug)
+
+	var request UploadCompanyLogoRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error("error decoding request body", "error", err)
+		respond.WithError(w, r, respond.InvalidRequestBody())
+		return
+	}
+	logger.Info("Incoming request", "requestBody", request)
+
+	if issues := logos.ValidateUpload(request.ContentType, request.ContentLength); len(issues) > 0 {
+		logger.Error("invalid logo upload", "issues", issues)
+		respond.WithError(w, r, respond.ValidationFailed(issues...))
+		return
+	}
+
+	company, err := h.companies.GetBySlug(r.Context(), slug)
+	if errors.Is(err, repository.ErrCompanyNotFound) {
+		logger.Error("company not found")
+		respond.WithError(w, r, respond.CompanyNotFound())
+		return
+	}
+	if err != nil {
+		logger.Error("error getting company", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	err = h.access.Company(r.Context(), identity, company.CompanyID, models.Editor)
+	if errors.Is(err, access.ErrForbidden) {
+		logger.Error("recruiter can not edit the company")
+		respond.WithError(w, r, respond.Forbidden())
+		return
+	}
+	if err != nil {
+		logger.Error("error checking access", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
+		return
+	}
+
+	// The logo processor picks the upload up from the bucket, the company's logo changes once the
+	// thumbnails have been made.
+	uploadID := uuid.NewString()
+	expiresAt := time.Now().Add(uploadExpiry)
+	uploadURL, err := h.uploads.PresignPut(r.Context(), logos.UploadKey(company.CompanyID, uploadID), request.ContentType, request.ContentLength, uploadExpiry)
+	if err != nil {
+		logger.Error("error presigning logo upload", "error", err)
+		respond.WithError(w, r, respond.Internal())
+		return
+	}
+
+	respond.WithJSON(w, UploadCompanyLogoResponse{
+		UploadID:  uploadID,
+		UploadURL: uploadURL,
+		Headers: map[string]string{
+			"Content-Type":   request.ContentType,
+			"Content-Length": strconv.FormatInt(request.ContentLength, 10),
+		},
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	}, http.StatusCreated)
+}
@@ -10,10 +10,20 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
 	"github.com/josepheid/upfront/api/handlers/validatepurchase"
+	"github.com/josepheid/upfront/internal/blobstore"
+	"github.com/josepheid/upfront/internal/duplicates"
+	"github.com/josepheid/upfront/internal/invoices"
+	"github.com/josepheid/upfront/internal/moderation"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/timeouts"
 )
 
 func main() {
@@ -22,12 +32,18 @@ func main() {
 	region := "eu-west-2"
 
 	ctx := context.Background()
-	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithAPIOptions(requestid.AWSAPIOptions))
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
 	// Create Secrets Manager client
 	svc := secretsmanager.NewFromConfig(config)
 
@@ -65,15 +81,48 @@ func main() {
 		os.Exit(1)
 	}
 
-	ddbc := dynamodb.NewFromConfig(config)
+	ddbc := dynamodb.NewFromConfig(config, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.DynamoDB))
+	})
+
+	cipc := cognitoidentityprovider.NewFromConfig(config, func(o *cognitoidentityprovider.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.Cognito))
+	})
+
+	s3c := s3.NewFromConfig(config, func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.S3))
+	})
+
+	sesc := ses.NewFromConfig(config, func(o *ses.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.SES))
+	})
+
+	blobs, err := blobstore.FromEnv(s3c)
+	if err != nil {
+		logger.Error("invalid blob store configuration", "error", err)
+		os.Exit(1)
+	}
+
+	seller, err := invoices.SellerFromEnv()
+	if err != nil {
+		logger.Error("invalid invoice configuration", "error", err)
+		os.Exit(1)
+	}
+
+	moderated, err := moderation.EnabledFromEnv()
+	if err != nil {
+		logger.Error("invalid moderation configuration", "error", err)
+		os.Exit(1)
+	}
 
-	cipc := cognitoidentityprovider.NewFromConfig(config)
+	invoiceIssuer := invoices.NewIssuer(invoices.NewCounter(ddbc, upfrontTableName), blobs, sesc, seller)
 
-	h, err := validatepurchase.NewHandler(logger, secret, upfrontTableName, ddbc, cipc, userPoolId)
+	companies := repository.NewCompanies(ddbc, upfrontTableName)
+	h, err := validatepurchase.NewHandler(logger, payments.NewProvider(secret, budgets), repository.NewJobPosts(ddbc, upfrontTableName), cipc, userPoolId, invoiceIssuer, companies, duplicates.NewDetector(companies), moderated)
 	if err != nil {
 		logger.Error("could not create handler", slog.Any("error", err))
 		os.Exit(1)
 	}
-	function := httpadapter.New(h).ProxyWithContext
+	function := httpadapter.New(requestid.Middleware(logger, timeouts.Middleware(h))).ProxyWithContext
 	lambda.Start(function)
 }
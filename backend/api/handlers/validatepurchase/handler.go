@@ -2,231 +2,281 @@ package validatepurchase
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/a-h/pathvars"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
-	cognitotypes "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
-	"golang.org/x/exp/rand"
+	"github.com/google/uuid"
 
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/accounts"
+	"github.com/josepheid/upfront/internal/duplicates"
+	"github.com/josepheid/upfront/internal/invoices"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/requestid"
 	"github.com/josepheid/upfront/internal/respond"
 	"github.com/stripe/stripe-go/v80"
-	"github.com/stripe/stripe-go/v80/checkout/session"
 )
 
 type Handler struct {
-	logger     *slog.Logger
-	stripeKey  string
-	tableName  string
-	ddbc       *dynamodb.Client
-	cipc       *cognitoidentityprovider.Client
-	userPoolId string
+	logger   *slog.Logger
+	payments payments.Provider
+	jobPosts repository.JobPosts
+	accounts accounts.Accounts
+	invoices invoices.Issuer
+	// companies and detector give posts checked out without a company one once they are paid for.
+	companies repository.Companies
+	detector  duplicates.Detector
+	// moderated holds new posts for review once they are paid for, instead of publishing them.
+	// Posts the scorer flagged are held either way.
+	moderated bool
 }
 
+// ValidatePurchaseResponse is the paid post as visitors see it, along with its status. The endpoint
+// needs no sign in, so the recruiter's email, billing and moderation are left out.
 type ValidatePurchaseResponse struct {
-	URL string `json:"url"`
+	models.JobPostListing
+	Status models.Status `json:"status"`
 }
 
 var matcher = pathvars.NewExtractor("*/upfront/validate-purchase/{id}")
 
-func NewHandler(logger *slog.Logger, stripeKey string, tableName string, ddbc *dynamodb.Client, cipc *cognitoidentityprovider.Client, userPoolId string) (Handler, error) {
+func NewHandler(logger *slog.Logger, provider payments.Provider, jobPosts repository.JobPosts, cipc *cognitoidentityprovider.Client, userPoolId string, invoiceIssuer invoices.Issuer, companies repository.Companies, detector duplicates.Detector, moderated bool) (Handler, error) {
 	return Handler{
-		logger:     logger,
-		stripeKey:  stripeKey,
-		tableName:  tableName,
-		ddbc:       ddbc,
-		cipc:       cipc,
-		userPoolId: userPoolId,
+		logger:    logger,
+		payments:  provider,
+		jobPosts:  jobPosts,
+		accounts:  accounts.NewAccounts(cipc, userPoolId),
+		invoices:  invoiceIssuer,
+		companies: companies,
+		detector:  detector,
+		moderated: moderated,
 	}, nil
 }
 
 func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := requestid.Logger(r.Context(), h.logger)
 	pathValues, ok := matcher.Extract(r.URL)
 	if !ok {
-		h.logger.Error("missing parameters in path")
-		respond.WithError(w, "missing parameters in path", http.StatusBadRequest)
+		logger.Error("missing parameters in path")
+		respond.WithError(w, r, respond.ValidationFailed("missing parameters in path"))
 		return
 	}
 
 	id, ok := pathValues["id"]
 	if !ok || id == "" {
-		h.logger.Error("missing id parameter in path")
-		respond.WithError(w, "missing id parameter in path", http.StatusBadRequest)
+		logger.Error("missing id parameter in path")
+		respond.WithError(w, r, respond.ValidationFailed("id is required"))
 		return
 	}
-	h.logger = h.logger.With("id", id)
-	h.logger.Info("id extracted")
-	stripe.Key = h.stripeKey
+	logger = logger.With("id", id)
+	logger.Info("id extracted")
 
-	pk, err := attributevalue.Marshal(models.FormatPK(id))
-	if err != nil {
-		h.logger.Error("error marshalling PK", "error", err)
-		respond.WithError(w, "error marshalling PK", http.StatusInternalServerError)
+	item, err := h.jobPosts.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.Error("job not found")
+		respond.WithError(w, r, respond.JobNotFound())
 		return
 	}
-
-	data, err := h.ddbc.Query(context.TODO(), &dynamodb.QueryInput{
-		TableName:              aws.String(h.tableName),
-		KeyConditionExpression: aws.String("PK = :pk"), // Only require the partition key
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":pk": pk, // Use the partition key
-		},
-	})
-
 	if err != nil {
-		h.logger.Error("error getting job", "error", err)
-		respond.WithError(w, "error getting job", http.StatusInternalServerError)
+		logger.Error("error getting job", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
 		return
 	}
 
-	if data.Items[0] == nil {
-		h.logger.Error("job not found", "error", err)
-		respond.WithError(w, "job not found", http.StatusNotFound)
-		return
+	// Every pending purchase is checked, so a renewal is applied the same way as the first payment.
+	now := time.Now()
+	changed := false
+	var paid []int
+	initialPaid := false
+	var audit []models.AuditEntry
+	for i, purchase := range item.Purchases() {
+		if purchase.Status != models.PurchasePending {
+			continue
+		}
+		checkoutSession, err := h.payments.GetCheckout(r.Context(), purchase.SessionID)
+		if err != nil {
+			logger.Error("error retrieving session", "error", err, "sessionID", purchase.SessionID)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
+		before := item.Snapshot()
+		switch {
+		case payments.Paid(checkoutSession):
+			err = item.ApplyPurchase(i, checkoutSession.AmountTotal, now)
+			paid = append(paid, i)
+			initialPaid = initialPaid || purchase.Kind == models.InitialPurchase
+			if err == nil {
+				audit = append(audit, item.Audit(models.AuditPurchasePaid, models.PaymentsActor, before, now))
+			}
+			if err == nil && (h.moderated || item.Flagged()) && purchase.Kind == models.InitialPurchase && item.Status == models.Active {
+				before = item.Snapshot()
+				err = item.HoldForReview(now)
+				if err == nil {
+					audit = append(audit, item.Audit(models.AuditHeldForReview, models.ModerationActor, before, now))
+					logger.Info("holding job post for review")
+				}
+			}
+		case checkoutSession.Status == stripe.CheckoutSessionStatusExpired:
+			err = item.ExpirePurchase(i, now)
+			if err == nil {
+				audit = append(audit, item.Audit(models.AuditPurchaseExpired, models.PaymentsActor, before, now))
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			logger.Error("error applying purchase", "error", err, "sessionID", purchase.SessionID)
+			respond.WithError(w, r, respond.Internal())
+			return
+		}
+		changed = true
 	}
 
-	item := models.JobPostItem{}
-
-	err = attributevalue.UnmarshalMap(data.Items[0], &item)
-	if err != nil {
-		h.logger.Error("error unmarshalling item", "error", err)
-		respond.WithError(w, "error unmarshalling item", http.StatusInternalServerError)
+	if item.Status == models.PendingPayment {
+		logger.Error("checkout session not paid")
+		respond.WithError(w, r, respond.PaymentIncomplete())
 		return
 	}
 
-	params := &stripe.CheckoutSessionParams{}
-	result, err := session.Get(
-		item.SessionID,
-		params,
-	)
+	// The purchase has been paid for by now, so a post that can't be given a company is saved
+	// without one rather than not at all.
+	if initialPaid && item.CompanyID == "" && (item.Status == models.Active || item.Status == models.PendingReview) {
+		if err := h.assignCompany(r.Context(), logger, &item, now); err != nil {
+			logger.Error("error assigning company, saving the job post without one", "error", err)
+		} else {
+			logger.Info("assigned company", "companyID", item.CompanyID)
+		}
+	}
 
-	if err != nil {
-		h.logger.Error("error retrieving session", "error", err)
-		respond.WithError(w, "error retrieving session", http.StatusInternalServerError)
-		return
+	if changed {
+		err = h.jobPosts.Save(r.Context(), &item, audit...)
+		if errors.Is(err, repository.ErrConflict) {
+			logger.Error("job post changed while validating purchase")
+			respond.WithError(w, r, respond.ConcurrentUpdate())
+			return
+		}
+		if err != nil {
+			logger.Error("error updating item", "error", err)
+			respond.WithError(w, r, respond.Upstream(err))
+			return
+		}
 	}
+	h.issueInvoices(r, logger, &item, paid)
+	h.refundLatePayments(r, logger, &item)
 
-	if result.PaymentStatus != stripe.CheckoutSessionPaymentStatusPaid {
-		h.logger.Error("checkout session not paid")
-		respond.WithError(w, "checkout session not paid", http.StatusPaymentRequired)
+	// Create user in cognito user pool as it has been confirmed they have paid for a job post, only if they don't already exist!
+	if err := h.accounts.Ensure(r.Context(), item.LoginEmail); err != nil {
+		logger.Error("error ensuring user in userpool", "error", err)
+		respond.WithError(w, r, respond.Upstream(err))
 		return
 	}
 
-	now := time.Now()
-	updatedAt, expiresAt := now, now.AddDate(0, 0, 90)
-
-	upd := expression.
-		Set(expression.Name("status"), expression.Value(models.Active)).
-		Set(expression.Name("updatedAt"), expression.Value(updatedAt.Format(time.RFC3339))).
-		Set(expression.Name("expiresAt"), expression.Value(expiresAt.Format(time.RFC3339)))
-
-	expr, err := expression.NewBuilder().WithUpdate(upd).Build()
+	respond.WithJSON(w, ValidatePurchaseResponse{JobPostListing: item.Listing(), Status: item.Status}, http.StatusOK)
+}
 
-	if err != nil {
-		h.logger.Error("error creating expression", "error", err)
-		respond.WithError(w, "error creating expression", http.StatusInternalServerError)
-		return
+// companyIDNamespace derives the ID of the company made for posts checked out with an unverified
+// email from the email and the company's name, so the same recruiter's posts share one company and
+// validating a purchase again doesn't make another.
+var companyIDNamespace = uuid.MustParse("3d0a6c1e-5b8f-4f7e-a2c4-9e61b7d05f38")
+
+// assignCompany gives a newly paid post the company named in its form. Only a recruiter who signed
+// in to check out joins an existing company they can edit. Anyone else only proved they could pay,
+// not that they own the email, so they get the company made for that email's earlier unverified
+// posts of the same name, or a new one of their own.
+func (h Handler) assignCompany(ctx context.Context, logger *slog.Logger, item *models.JobPostItem, now time.Time) error {
+	profile := models.CompanyProfile{Name: item.CompanyName, Website: item.CompanyWebsite}
+	var company models.Company
+	var err error
+	if item.LoginVerified {
+		company, err = h.companies.FindOrCreate(ctx, item.LoginEmail, profile, now)
+	} else {
+		id := uuid.NewSHA1(companyIDNamespace, []byte(item.LoginEmail+"/"+models.Slugify(item.CompanyName))).String()
+		company, err = h.companies.GetOrCreate(ctx, id, item.LoginEmail, profile, now)
 	}
-
-	sk, err := attributevalue.Marshal(item.CreatedAt)
 	if err != nil {
-		h.logger.Error("error marshalling SK", "error", err)
-		respond.WithError(w, "error marshalling SK", http.StatusInternalServerError)
-		return
+		return err
 	}
+	company.ApplyTo(&item.JobPostFormProps)
 
-	out, err := h.ddbc.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
-		Key:                       map[string]types.AttributeValue{"PK": pk, "SK": sk},
-		TableName:                 aws.String(h.tableName),
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		UpdateExpression:          expr.Update(),
-		ReturnValues:              types.ReturnValueAllNew,
-	})
+	// Duplicates are only a warning, so the post goes live if they can't be looked for.
+	item.PossibleDuplicates, err = h.detector.Find(ctx, *item)
 	if err != nil {
-		h.logger.Error("error updating item", "error", err)
-		respond.WithError(w, "error updating item", http.StatusInternalServerError)
-		return
+		logger.Error("error finding duplicate job posts", "error", err)
 	}
+	return nil
+}
 
-	itemOut := models.JobPostItem{}
-
-	err = attributevalue.UnmarshalMap(out.Attributes, &itemOut)
-
-	if err != nil {
-		h.logger.Error("error unmarshalling update item output", "error", err)
-		respond.WithError(w, "error unmarshalling update item output", http.StatusInternalServerError)
-		return
+// issueInvoices numbers, saves and emails the invoices of the purchases at paid once the payments
+// are saved, so a failed save never uses up invoice numbers. An invoice that fails keeps the number
+// it was given, if any, and is issued again when the recruiter first downloads it.
+func (h Handler) issueInvoices(r *http.Request, logger *slog.Logger, item *models.JobPostItem, paid []int) {
+	now := time.Now()
+	type issued struct {
+		invoice invoices.Invoice
+		pdf     []byte
 	}
-
-	// Create user in cognito user pool as it has been confirmed they have paid for a job post, only if they don't already exist!
-	_, err = h.cipc.AdminGetUser(context.Background(), &cognitoidentityprovider.AdminGetUserInput{
-		UserPoolId: aws.String(h.userPoolId),
-		Username:   aws.String(strings.ToLower(item.LoginEmail)),
-	})
-
-	if err == nil {
-		h.logger.Info("user already exists!", "email", strings.ToLower(item.LoginEmail))
-		respond.WithJSON(w, itemOut, http.StatusOK)
-		return
+	var toEmail []issued
+	var audit []models.AuditEntry
+	for _, i := range paid {
+		before := item.Snapshot()
+		invoice, pdf, err := h.invoices.Issue(r.Context(), item, i, now)
+		if err != nil {
+			logger.Error("error issuing invoice", "error", err, "sessionID", item.BillingHistory[i].SessionID)
+			continue
+		}
+		logger.Info("issued invoice", "invoiceNumber", invoice.Number)
+		audit = append(audit, item.Audit(models.AuditInvoiced, models.PaymentsActor, before, now))
+		toEmail = append(toEmail, issued{invoice: invoice, pdf: pdf})
 	}
-
-	_, err = h.cipc.AdminCreateUser(context.Background(), &cognitoidentityprovider.AdminCreateUserInput{
-		UserPoolId:             aws.String(h.userPoolId),
-		Username:               aws.String(strings.ToLower(item.LoginEmail)),
-		MessageAction:          cognitotypes.MessageActionTypeSuppress, // Suppress the temporary password email
-		DesiredDeliveryMediums: []cognitotypes.DeliveryMediumType{},    // Don't send any messages
-		UserAttributes: []cognitotypes.AttributeType{
-			{
-				Name:  aws.String("email"),
-				Value: aws.String(strings.ToLower(item.LoginEmail)),
-			},
-			{
-				Name:  aws.String("email_verified"),
-				Value: aws.String("true"),
-			},
-		},
-	})
-
-	if err != nil {
-		h.logger.Error("error creating user in userpool", "error", err)
-		respond.WithError(w, "error creating user in userpool", http.StatusInternalServerError)
+	if len(toEmail) == 0 {
 		return
 	}
-
-	_, err = h.cipc.AdminSetUserPassword(context.Background(), &cognitoidentityprovider.AdminSetUserPasswordInput{
-		UserPoolId: aws.String(h.userPoolId),
-		Username:   aws.String(strings.ToLower(item.LoginEmail)),
-		Password:   aws.String(generateSecureRandomPassword()), // Generate a secure random password
-		Permanent:  true,                                       // This prevents FORCE_CHANGE_PASSWORD status
-	})
-
-	if err != nil {
-		h.logger.Error("error confirming user in userpool", "error", err)
-		respond.WithError(w, "error confirming user in userpool", http.StatusInternalServerError)
+	item.UpdatedAt = now.Format(time.RFC3339)
+	if err := h.jobPosts.Save(r.Context(), item, audit...); err != nil {
+		logger.Error("invoices were issued but not recorded", "error", err)
 		return
 	}
-
-	respond.WithJSON(w, itemOut, http.StatusOK)
+	for _, e := range toEmail {
+		if err := h.invoices.Email(r.Context(), e.invoice, e.pdf); err != nil {
+			logger.Error("error emailing invoice", "error", err, "invoiceNumber", e.invoice.Number)
+		}
+	}
 }
 
-func generateSecureRandomPassword() string {
-	const length = 32
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*()_+-=[]{}|"
-
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+// refundLatePayments issues the refunds ApplyPurchase recorded for purchases paid after the post
+// was cancelled or removed. The payment is already on record, so a refund that fails is only
+// logged and stays pending for support to retry with upfrontctl payments refund.
+func (h Handler) refundLatePayments(r *http.Request, logger *slog.Logger, item *models.JobPostItem) {
+	before := item.Snapshot()
+	refunded := false
+	for _, i := range item.PendingRefunds() {
+		pending := &item.Refunds[i]
+		if pending.Reason != models.LatePaymentReason {
+			continue
+		}
+		result, err := h.payments.Refund(r.Context(), pending.SessionID, pending.Amount)
+		if err != nil {
+			logger.Error("error refunding late payment", "error", err, "sessionID", pending.SessionID)
+			continue
+		}
+		now := time.Now().Format(time.RFC3339)
+		pending.RefundID = result.ID
+		pending.Status = models.RefundIssued
+		pending.IssuedAt = now
+		item.UpdatedAt = now
+		refunded = true
+		logger.Info("refunded late payment", "sessionID", pending.SessionID, "refundID", result.ID, "amount", pending.Amount)
+	}
+	if !refunded {
+		return
+	}
+	err := h.jobPosts.Save(r.Context(), item, item.Audit(models.AuditRefunded, models.PaymentsActor, before, time.Now()))
+	if err != nil {
+		logger.Error("late payment was refunded but not recorded", "error", err)
 	}
-	return string(b)
 }
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreated AuditAction = "Created"
+	// AuditCheckoutStarted is a renewal or upgrade checkout being opened for the post.
+	AuditCheckoutStarted AuditAction = "CheckoutStarted"
+	AuditPurchasePaid    AuditAction = "PurchasePaid"
+	AuditPurchaseExpired AuditAction = "PurchaseExpired"
+	AuditEdited          AuditAction = "Edited"
+	AuditHeldForReview   AuditAction = "HeldForReview"
+	AuditReviewed        AuditAction = "Reviewed"
+	AuditCancelled       AuditAction = "Cancelled"
+	AuditRefunded        AuditAction = "Refunded"
+	AuditExpired         AuditAction = "Expired"
+	AuditExtended        AuditAction = "Extended"
+	AuditTransferred     AuditAction = "Transferred"
+	AuditPaused          AuditAction = "Paused"
+	AuditResumed         AuditAction = "Resumed"
+	AuditFilled          AuditAction = "Filled"
+	AuditInvoiced        AuditAction = "Invoiced"
+)
+
+// Actors for changes no person made.
+const (
+	// SystemActor is a scheduled job.
+	SystemActor = "system"
+	// PaymentsActor is a change made because Stripe reported a checkout as paid or expired.
+	PaymentsActor = "system/payments"
+	// ModerationActor is a post being held for review because moderation is on or it scored high.
+	ModerationActor = "system/moderation"
+	// ReportsActor is a post being held for review after enough visitors reported it.
+	ReportsActor = "system/reports"
+)
+
+// FieldChange is the value of one JSON field of a job post before and after a change. Before is nil
+// for a field that was added, After for one that was removed.
+type FieldChange struct {
+	Field  string `dynamodbav:"field" json:"field"`
+	Before any    `dynamodbav:"before" json:"before"`
+	After  any    `dynamodbav:"after" json:"after"`
+}
+
+// AuditEntry records a change to a job post, stored under the post's partition. Entries are only
+// ever added, so a post's entries are its full history.
+type AuditEntry struct {
+	PK        string        `dynamodbav:"PK" json:"-"`
+	SK        string        `dynamodbav:"SK" json:"-"`
+	JobID     string        `dynamodbav:"jobID" json:"jobID"`
+	Action    AuditAction   `dynamodbav:"action" json:"action"`
+	Actor     string        `dynamodbav:"actor" json:"actor"`
+	RequestID string        `dynamodbav:"requestID,omitempty" json:"requestID,omitempty"`
+	Changes   []FieldChange `dynamodbav:"changes" json:"changes"`
+	CreatedAt string        `dynamodbav:"createdAt" json:"createdAt"`
+}
+
+// AuditSKPrefix starts the sort key of every audit entry under a job post.
+const AuditSKPrefix = "audit/"
+
+// auditTimeFormat keeps the fractional seconds at a fixed width, so sort keys order by time.
+const auditTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// FormatAuditSK returns the sort key of an entry made at now, suffix orders and tells apart entries
+// made at the same time.
+func FormatAuditSK(now time.Time, suffix string) string {
+	return AuditSKPrefix + now.UTC().Format(auditTimeFormat) + "/" + suffix
+}
+
+// Snapshot is a copy of a post's JSON fields, taken before a change so the change can be audited.
+// Unlike a copy of the item it shares no slices with it.
+type Snapshot map[string]any
+
+// Snapshot copies the post's current fields.
+func (item JobPostItem) Snapshot() Snapshot {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil
+	}
+	s := Snapshot{}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil
+	}
+	return s
+}
+
+// Audit records actor changing the post from before to its current fields with action. A new post
+// is audited with a nil before.
+func (item JobPostItem) Audit(action AuditAction, actor string, before Snapshot, now time.Time) AuditEntry {
+	return AuditEntry{
+		PK:        item.PK,
+		JobID:     item.JobID,
+		Action:    action,
+		Actor:     actor,
+		Changes:   Diff(before, item.Snapshot()),
+		CreatedAt: now.Format(time.RFC3339Nano),
+	}
+}
+
+// unaudited fields change with every write, or aren't part of the post.
+var unaudited = map[string]bool{
+	"updatedAt": true,
+	"featured":  true,
+}
+
+// Diff returns the fields that differ between two snapshots, in name order.
+func Diff(before, after Snapshot) []FieldChange {
+	names := map[string]bool{}
+	for k := range before {
+		names[k] = true
+	}
+	for k := range after {
+		names[k] = true
+	}
+	changes := []FieldChange{}
+	for name := range names {
+		if unaudited[name] || reflect.DeepEqual(before[name], after[name]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Before: before[name], After: after[name]})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
@@ -0,0 +1,265 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// priceFactors is the price in pounds of 30 days of each plan.
+var priceFactors = map[PlanType]int{
+	Standard: 35,
+	Premium:  90,
+}
+
+// BillingCurrency is the currency job posts are paid for in.
+const BillingCurrency = GBP
+
+// PlanDurations are the number of days a plan can be bought for.
+var PlanDurations = []int{30, 60, 90, 120, 150, 180}
+
+// Price is the cost in pence of a plan for days.
+func Price(plan PlanType, days int) int64 {
+	return int64(((days * priceFactors[plan]) / 30) * 100)
+}
+
+// ValidatePlan returns the issues with a plan type and duration, if any.
+func ValidatePlan(plan PlanType, days int) []string {
+	var issues []string
+	if _, ok := priceFactors[plan]; !ok {
+		issues = append(issues, fmt.Sprintf("planType must be one of %q or %q", Standard, Premium))
+	}
+	valid := false
+	for _, d := range PlanDurations {
+		valid = valid || d == days
+	}
+	if !valid {
+		issues = append(issues, fmt.Sprintf("planDuration must be one of %v", PlanDurations))
+	}
+	return issues
+}
+
+type PurchaseKind string
+
+const (
+	// InitialPurchase pays for a new job post.
+	InitialPurchase PurchaseKind = "Initial"
+	// Renewal extends a post's ExpiresAt, or reactivates an expired post.
+	Renewal PurchaseKind = "Renewal"
+	// Upgrade moves an active post to a more expensive plan until its ExpiresAt.
+	Upgrade PurchaseKind = "Upgrade"
+)
+
+type PurchaseStatus string
+
+const (
+	PurchasePending PurchaseStatus = "Pending"
+	PurchasePaid    PurchaseStatus = "Paid"
+	// PurchaseExpired is a checkout that was abandoned before it was paid.
+	PurchaseExpired PurchaseStatus = "Expired"
+)
+
+var (
+	// ErrNotAnUpgrade is returned when the new plan doesn't cost more than the current one.
+	ErrNotAnUpgrade = errors.New("plan is not an upgrade")
+	// ErrNoTimeRemaining is returned when a post has already expired, so there is nothing to upgrade.
+	ErrNoTimeRemaining = errors.New("job post has no time remaining")
+)
+
+// UpgradePrice is the cost in pence of moving a post from one plan to another until expiresAt. The
+// price factor difference is charged for every started day remaining, rounded up to the penny.
+func UpgradePrice(from, to PlanType, expiresAt, now time.Time) (int64, error) {
+	fromFactor, ok := priceFactors[from]
+	toFactor, toOK := priceFactors[to]
+	if !ok || !toOK || toFactor <= fromFactor {
+		return 0, ErrNotAnUpgrade
+	}
+	days := RemainingDays(expiresAt, now)
+	if days == 0 {
+		return 0, ErrNoTimeRemaining
+	}
+	pencePer30Days := int64(toFactor-fromFactor) * 100
+	return (int64(days)*pencePer30Days + 29) / 30, nil
+}
+
+// RemainingDays is the number of days, including a part day, left until expiresAt.
+func RemainingDays(expiresAt, now time.Time) int {
+	remaining := expiresAt.Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+	day := 24 * time.Hour
+	return int((remaining + day - 1) / day)
+}
+
+// Purchase is an entry in a job post's billing history.
+type Purchase struct {
+	SessionID string       `dynamodbav:"sessionID" json:"sessionID"`
+	Kind      PurchaseKind `dynamodbav:"kind" json:"kind"`
+	PlanType  PlanType     `dynamodbav:"planType" json:"planType"`
+	// PlanDuration is the number of days bought, for an upgrade it is the days that were remaining.
+	PlanDuration int `dynamodbav:"planDuration" json:"planDuration"`
+	// Amount is the price of the plan until the purchase is paid, then what was actually paid,
+	// which is less when a promotion code was used.
+	Amount    int64          `dynamodbav:"amount" json:"amount"`
+	Currency  Currency       `dynamodbav:"currency" json:"currency"`
+	Status    PurchaseStatus `dynamodbav:"status" json:"status"`
+	CreatedAt string         `dynamodbav:"createdAt" json:"createdAt"`
+	PaidAt    string         `dynamodbav:"paidAt,omitempty" json:"paidAt,omitempty"`
+	// InvoiceNumber is set once the invoice for a paid purchase has been issued.
+	InvoiceNumber string `dynamodbav:"invoiceNumber,omitempty" json:"invoiceNumber,omitempty"`
+}
+
+// Purchases returns the post's billing history. Posts created before billing history was recorded
+// only have a SessionID, so their initial purchase is reconstructed from the post.
+func (item JobPostItem) Purchases() []Purchase {
+	if len(item.BillingHistory) > 0 || item.SessionID == "" {
+		return item.BillingHistory
+	}
+	status := PurchasePending
+	if item.Status != PendingPayment {
+		status = PurchasePaid
+	}
+	return []Purchase{{
+		SessionID:    item.SessionID,
+		Kind:         InitialPurchase,
+		PlanType:     item.PlanType,
+		PlanDuration: item.PlanDuration,
+		Amount:       Price(item.PlanType, item.PlanDuration),
+		Currency:     BillingCurrency,
+		Status:       status,
+		CreatedAt:    item.CreatedAt,
+	}}
+}
+
+// PendingPurchase returns the index of the post's pending purchase of kind, if there is one.
+func (item JobPostItem) PendingPurchase(kind PurchaseKind) (int, bool) {
+	for i, p := range item.Purchases() {
+		if p.Kind == kind && p.Status == PurchasePending {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ApplyPurchase marks the purchase at index i as paid and updates the post accordingly. paid is the
+// amount the checkout took. A post cancelled, removed or filled while the checkout was open can't
+// use what was bought, and neither can an upgrade of a post that stopped being active or ran out of
+// time, so the payment is recorded with a pending refund of all of it, see LatePaymentReason.
+func (item *JobPostItem) ApplyPurchase(i int, paid int64, now time.Time) error {
+	item.BillingHistory = item.Purchases()
+	if i < 0 || i >= len(item.BillingHistory) {
+		return fmt.Errorf("purchase %d not found", i)
+	}
+	p := &item.BillingHistory[i]
+	if p.Status == PurchasePaid {
+		return fmt.Errorf("purchase %s has already been applied", p.SessionID)
+	}
+	if p.Kind != InitialPurchase && p.Kind != Renewal && p.Kind != Upgrade {
+		return fmt.Errorf("unknown purchase kind %q", p.Kind)
+	}
+
+	// The purchase is marked paid first, the lifecycle only lets a paid post go live.
+	p.Status = PurchasePaid
+	p.Amount = min(p.Amount, max(paid, 0))
+	p.PaidAt = now.Format(time.RFC3339)
+	item.UpdatedAt = now.Format(time.RFC3339)
+
+	if item.Status == Cancelled || item.Status == Removed || item.Status == Filled || (p.Kind == Upgrade && !item.upgradable(now)) {
+		if p.Amount > 0 {
+			item.Refunds = append(item.Refunds, Refund{
+				SessionID:   p.SessionID,
+				Amount:      p.Amount,
+				Currency:    p.Currency,
+				Status:      RefundPending,
+				Reason:      LatePaymentReason,
+				RequestedBy: PaymentsActor,
+				CreatedAt:   now.Format(time.RFC3339),
+			})
+		}
+		item.TTL = 0
+		return nil
+	}
+
+	switch p.Kind {
+	case InitialPurchase:
+		item.ExpiresAt = now.AddDate(0, 0, p.PlanDuration).Format(time.RFC3339)
+		if err := item.Transition(Active, now); err != nil {
+			return err
+		}
+	case Renewal:
+		// Time left on an active post is kept, an expired post starts again from now. A post in
+		// review or paused keeps its time and its status.
+		from := now
+		if expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt); err == nil && (item.Status == Active || item.Status == PendingReview || item.Status == Paused) && expiresAt.After(now) {
+			from = expiresAt
+		}
+		item.ExpiresAt = from.AddDate(0, 0, p.PlanDuration).Format(time.RFC3339)
+		if item.Status == Expired {
+			if err := item.Transition(Active, now); err != nil {
+				return err
+			}
+		}
+	case Upgrade:
+		// The remaining time was paid for at the new plan's rate, so ExpiresAt doesn't change.
+		item.PlanType = p.PlanType
+	}
+
+	item.TTL = 0
+	return nil
+}
+
+// upgradable reports whether the post still has the active days an upgrade was priced for.
+func (item JobPostItem) upgradable(now time.Time) bool {
+	expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt)
+	return item.Status == Active && err == nil && expiresAt.After(now)
+}
+
+// ErrNotLive is returned when a support action needs a post that is active or in review.
+var ErrNotLive = errors.New("job post is not active or pending review")
+
+// Extend adds days to a live post without a purchase, as a goodwill gesture.
+func (item *JobPostItem) Extend(days int, now time.Time) error {
+	if item.Status != Active && item.Status != PendingReview {
+		return ErrNotLive
+	}
+	from := now
+	if expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt); err == nil && expiresAt.After(now) {
+		from = expiresAt
+	}
+	item.ExpiresAt = from.AddDate(0, 0, days).Format(time.RFC3339)
+	item.UpdatedAt = now.Format(time.RFC3339)
+	return nil
+}
+
+// Expire ends a live post now, instead of waiting for its ExpiresAt. Nothing is refunded.
+func (item *JobPostItem) Expire(now time.Time) error {
+	if item.Status != Active && item.Status != PendingReview {
+		return ErrNotLive
+	}
+	expiresAt := item.ExpiresAt
+	item.ExpiresAt = now.Format(time.RFC3339)
+	if err := item.Transition(Expired, now); err != nil {
+		item.ExpiresAt = expiresAt
+		return err
+	}
+	item.ReviewRequestedAt = ""
+	item.UpdatedAt = now.Format(time.RFC3339)
+	return nil
+}
+
+// ExpirePurchase marks the pending purchase at index i as abandoned, so its checkout isn't
+// checked again.
+func (item *JobPostItem) ExpirePurchase(i int, now time.Time) error {
+	item.BillingHistory = item.Purchases()
+	if i < 0 || i >= len(item.BillingHistory) {
+		return fmt.Errorf("purchase %d not found", i)
+	}
+	p := &item.BillingHistory[i]
+	if p.Status != PurchasePending {
+		return fmt.Errorf("purchase %s is not pending", p.SessionID)
+	}
+	p.Status = PurchaseExpired
+	item.UpdatedAt = now.Format(time.RFC3339)
+	return nil
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPrice(t *testing.T) {
+	tests := []struct {
+		plan PlanType
+		days int
+		want int64
+	}{
+		{plan: Standard, days: 30, want: 3500},
+		{plan: Standard, days: 60, want: 7000},
+		{plan: Standard, days: 180, want: 21000},
+		{plan: Premium, days: 30, want: 9000},
+		{plan: Premium, days: 90, want: 27000},
+		{plan: PlanType("Gold"), days: 30, want: 0},
+	}
+	for _, tt := range tests {
+		if got := Price(tt.plan, tt.days); got != tt.want {
+			t.Errorf("Price(%s, %d) = %d, want %d", tt.plan, tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestRemainingDays(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		want      int
+	}{
+		{name: "whole days", expiresAt: now.AddDate(0, 0, 10), want: 10},
+		{name: "part day counts as a day", expiresAt: now.AddDate(0, 0, 10).Add(time.Second), want: 11},
+		{name: "expiry day", expiresAt: now.Add(3 * time.Hour), want: 1},
+		{name: "one second left", expiresAt: now.Add(time.Second), want: 1},
+		{name: "expires now", expiresAt: now, want: 0},
+		{name: "already expired", expiresAt: now.Add(-48 * time.Hour), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RemainingDays(tt.expiresAt, now); got != tt.want {
+				t.Errorf("RemainingDays() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgradePrice(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Premium costs 5500 pence more than Standard per 30 days, so 183.33 pence a day.
+	tests := []struct {
+		name      string
+		from, to  PlanType
+		expiresAt time.Time
+		want      int64
+		wantErr   error
+	}{
+		{name: "full plan", from: Standard, to: Premium, expiresAt: now.AddDate(0, 0, 30), want: 5500},
+		{name: "whole days divide evenly", from: Standard, to: Premium, expiresAt: now.AddDate(0, 0, 15), want: 2750},
+		{name: "rounds up to the penny", from: Standard, to: Premium, expiresAt: now.AddDate(0, 0, 10), want: 1834},
+		{name: "part day is charged as a day", from: Standard, to: Premium, expiresAt: now.AddDate(0, 0, 15).Add(time.Minute), want: 2934},
+		{name: "expiry day", from: Standard, to: Premium, expiresAt: now.Add(2 * time.Hour), want: 184},
+		{name: "longer than a plan", from: Standard, to: Premium, expiresAt: now.AddDate(0, 0, 45), want: 8250},
+		{name: "expires now", from: Standard, to: Premium, expiresAt: now, wantErr: ErrNoTimeRemaining},
+		{name: "already expired", from: Standard, to: Premium, expiresAt: now.AddDate(0, 0, -1), wantErr: ErrNoTimeRemaining},
+		{name: "same plan", from: Premium, to: Premium, expiresAt: now.AddDate(0, 0, 10), wantErr: ErrNotAnUpgrade},
+		{name: "downgrade", from: Premium, to: Standard, expiresAt: now.AddDate(0, 0, 10), wantErr: ErrNotAnUpgrade},
+		{name: "unknown plan", from: Standard, to: PlanType("Gold"), expiresAt: now.AddDate(0, 0, 10), wantErr: ErrNotAnUpgrade},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UpgradePrice(tt.from, tt.to, tt.expiresAt, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpgradePrice() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UpgradePrice() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// purchasedPost is a live Standard post bought 10 days before now, with a purchase of kind pending.
+func purchasedPost(now time.Time, status Status, kind PurchaseKind) JobPostItem {
+	item := JobPostItem{
+		Status:    status,
+		ExpiresAt: now.AddDate(0, 0, 20).Format(time.RFC3339),
+		BillingHistory: []Purchase{
+			{SessionID: "cs_initial", Kind: InitialPurchase, PlanType: Standard, PlanDuration: 30, Amount: 3500, Currency: GBP, Status: PurchasePaid, PaidAt: now.AddDate(0, 0, -10).Format(time.RFC3339)},
+			{SessionID: "cs_pending", Kind: kind, PlanType: Premium, PlanDuration: 20, Amount: 3667, Currency: GBP, Status: PurchasePending},
+		},
+	}
+	item.PlanType = Standard
+	item.PlanDuration = 30
+	return item
+}
+
+func TestApplyPurchaseUpgrade(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := purchasedPost(now, Active, Upgrade)
+	expiresAt := item.ExpiresAt
+	if err := item.ApplyPurchase(1, 3667, now); err != nil {
+		t.Fatalf("ApplyPurchase() = %v", err)
+	}
+	if item.PlanType != Premium || item.ExpiresAt != expiresAt || item.Status != Active {
+		t.Errorf("after upgrade plan = %s, expiresAt = %s, status = %s", item.PlanType, item.ExpiresAt, item.Status)
+	}
+	if err := item.ApplyPurchase(1, 3667, now); err == nil {
+		t.Error("applying the same purchase twice succeeded")
+	}
+}
+
+func TestApplyPurchaseAfterCancellation(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		status     Status
+		kind       PurchaseKind
+		lapsed     bool
+		paid       int64
+		wantRefund int64
+	}{
+		{name: "renewal on a cancelled post", status: Cancelled, kind: Renewal, paid: 3667, wantRefund: 3667},
+		{name: "upgrade on a removed post", status: Removed, kind: Upgrade, paid: 3667, wantRefund: 3667},
+		{name: "renewal on a filled post", status: Filled, kind: Renewal, paid: 3667, wantRefund: 3667},
+		{name: "upgrade on a filled post", status: Filled, kind: Upgrade, paid: 3667, wantRefund: 3667},
+		{name: "upgrade on an expired post", status: Expired, kind: Upgrade, paid: 3667, wantRefund: 3667},
+		{name: "upgrade on a paused post", status: Paused, kind: Upgrade, paid: 3667, wantRefund: 3667},
+		{name: "upgrade after the time ran out", status: Active, kind: Upgrade, lapsed: true, paid: 3667, wantRefund: 3667},
+		{name: "initial purchase on a cancelled post", status: Cancelled, kind: InitialPurchase, paid: 3667, wantRefund: 3667},
+		{name: "discounted payment is refunded as paid", status: Cancelled, kind: Renewal, paid: 1000, wantRefund: 1000},
+		{name: "free checkout has nothing to refund", status: Cancelled, kind: Upgrade, paid: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := purchasedPost(now, tt.status, tt.kind)
+			if tt.lapsed {
+				item.ExpiresAt = now.Add(-time.Hour).Format(time.RFC3339)
+			}
+			expiresAt := item.ExpiresAt
+			if err := item.ApplyPurchase(1, tt.paid, now); err != nil {
+				t.Fatalf("ApplyPurchase() = %v, want the late payment recorded", err)
+			}
+			if p := item.BillingHistory[1]; p.Status != PurchasePaid || p.Amount != tt.paid {
+				t.Errorf("purchase = %+v, want it paid with %d", p, tt.paid)
+			}
+			if item.Status != tt.status || item.PlanType != Standard || item.ExpiresAt != expiresAt {
+				t.Errorf("post changed to status %s, plan %s, expiresAt %s", item.Status, item.PlanType, item.ExpiresAt)
+			}
+			if tt.wantRefund == 0 {
+				if len(item.Refunds) != 0 {
+					t.Errorf("refunds = %+v, want none", item.Refunds)
+				}
+				return
+			}
+			if len(item.Refunds) != 1 {
+				t.Fatalf("refunds = %+v, want one", item.Refunds)
+			}
+			refund := item.Refunds[0]
+			if refund.SessionID != "cs_pending" || refund.Amount != tt.wantRefund || refund.Status != RefundPending || refund.Reason != LatePaymentReason {
+				t.Errorf("refund = %+v, want a pending refund of %d for the late payment", refund, tt.wantRefund)
+			}
+			if got := item.PendingRefunds(); len(got) != 1 {
+				t.Errorf("PendingRefunds() = %v, want the late payment", got)
+			}
+		})
+	}
+}
+
+func TestPendingPurchase(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := purchasedPost(now, Active, Upgrade)
+	if i, ok := item.PendingPurchase(Upgrade); !ok || i != 1 {
+		t.Errorf("PendingPurchase(Upgrade) = %d, %v, want 1, true", i, ok)
+	}
+	if _, ok := item.PendingPurchase(Renewal); ok {
+		t.Error("PendingPurchase(Renewal) found a purchase")
+	}
+	if err := item.ExpirePurchase(1, now); err != nil {
+		t.Fatalf("ExpirePurchase() = %v", err)
+	}
+	if _, ok := item.PendingPurchase(Upgrade); ok {
+		t.Error("PendingPurchase(Upgrade) found an expired purchase")
+	}
+}
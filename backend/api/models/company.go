@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Company is a company profile, job posts reference it by CompanyID. Its ID is stored as "id" so
+// the profile itself isn't in the companyIndex GSI alongside the company's posts.
+type Company struct {
+	PK          string `dynamodbav:"PK" json:"-"`
+	SK          string `dynamodbav:"SK" json:"-"`
+	CompanyID   string `dynamodbav:"id" json:"companyID"`
+	Slug        string `dynamodbav:"slug" json:"slug"`
+	Name        string `dynamodbav:"name" json:"name"`
+	Description string `dynamodbav:"description" json:"description"`
+	Website     string `dynamodbav:"website" json:"website"`
+	// LogoURL is the largest of LogoThumbnails, it is only set by processing an uploaded logo.
+	LogoURL        *string         `dynamodbav:"logoURL" json:"logoURL,omitempty"`
+	LogoThumbnails []LogoThumbnail `dynamodbav:"logoThumbnails,omitempty" json:"logoThumbnails,omitempty"`
+	CreatedAt      string          `dynamodbav:"createdAt" json:"createdAt"`
+	UpdatedAt      string          `dynamodbav:"updatedAt" json:"updatedAt"`
+}
+
+// LogoThumbnail is a square copy of a company's logo that we host.
+type LogoThumbnail struct {
+	Size int    `dynamodbav:"size" json:"size"`
+	URL  string `dynamodbav:"url" json:"url"`
+}
+
+// CompanyProfile is the part of a company a recruiter can edit, logos are uploaded separately.
+type CompanyProfile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Website     string `json:"website"`
+}
+
+// MaxCompanyDescriptionLength keeps profiles to a short summary.
+const MaxCompanyDescriptionLength = 5000
+
+// Validate returns the issues with a profile, if any.
+func (p CompanyProfile) Validate() []string {
+	var issues []string
+	if strings.TrimSpace(p.Name) == "" {
+		issues = append(issues, "name is required")
+	} else if Slugify(p.Name) == "" {
+		issues = append(issues, "name must contain at least one letter or number")
+	}
+	if len(p.Description) > MaxCompanyDescriptionLength {
+		issues = append(issues, fmt.Sprintf("description must be at most %d characters", MaxCompanyDescriptionLength))
+	}
+	if !isWebURL(p.Website) {
+		issues = append(issues, "website must be an http or https URL")
+	}
+	return issues
+}
+
+// Apply copies the profile onto the company.
+func (c *Company) Apply(p CompanyProfile) {
+	c.Name = strings.TrimSpace(p.Name)
+	c.Description = p.Description
+	c.Website = p.Website
+}
+
+// ApplyTo copies the company's details onto a job post, posts keep a copy so listings don't need
+// to read every company.
+func (c Company) ApplyTo(props *JobPostFormProps) {
+	props.CompanyID = c.CompanyID
+	props.CompanyName = c.Name
+	props.CompanyWebsite = c.Website
+	props.CompanyLogoURL = c.LogoURL
+}
+
+func FormatCompanyPK(id string) string {
+	return fmt.Sprintf("company/%s", id)
+}
+
+// FormatCompanySlugPK is the key of the item that reserves a slug for a company.
+func FormatCompanySlugPK(slug string) string {
+	return fmt.Sprintf("companySlug/%s", slug)
+}
+
+// CompanySlug is the item that reserves a slug, it maps the slug to the company's ID.
+type CompanySlug struct {
+	PK        string `dynamodbav:"PK"`
+	SK        string `dynamodbav:"SK"`
+	CompanyID string `dynamodbav:"id"`
+}
+
+// Slugify turns a company name into a URL path segment, e.g. "Acme & Co." becomes "acme-co".
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+func isWebURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DraftTTL is how long a draft is kept after it was last saved.
+const DraftTTL = 30 * 24 * time.Hour
+
+// ErrNotDraft is returned when changing a draft that has been checked out, or returning a post
+// that was never a draft to one.
+var ErrNotDraft = errors.New("job post is not a draft")
+
+// ValidateDraft returns the issues that stop form being saved as a draft. Drafts can be
+// incomplete, the rest of the form is only checked at checkout.
+func ValidateDraft(form JobPostFormProps) []string {
+	if !strings.Contains(form.LoginEmail, "@") {
+		return []string{"loginEmail is required"}
+	}
+	return nil
+}
+
+// NewDraft returns a draft with id holding form. Drafts stay out of the allJobsIndex, so nothing
+// that lists or processes posts sees them until they are checked out.
+func NewDraft(id string, form JobPostFormProps, now time.Time) JobPostItem {
+	form.LoginEmail = strings.ToLower(strings.TrimSpace(form.LoginEmail))
+	return JobPostItem{
+		JobPostFormProps: form,
+		PK:               FormatPK(id),
+		SK:               now.Format(time.RFC3339),
+		JobID:            id,
+		CreatedAt:        now.Format(time.RFC3339),
+		UpdatedAt:        now.Format(time.RFC3339),
+		Status:           Draft,
+		TTL:              now.Add(DraftTTL).Unix(),
+	}
+}
+
+// IsDraft reports whether item is a draft that is still kept. DynamoDB can take a while to delete
+// expired items, so the TTL is checked too.
+func (item JobPostItem) IsDraft(now time.Time) bool {
+	return item.Status == Draft && (item.TTL == 0 || now.Unix() < item.TTL)
+}
+
+// EditDraft replaces the draft's form, keeping the email it is tied to.
+func (item *JobPostItem) EditDraft(form JobPostFormProps, now time.Time) error {
+	if item.Status != Draft {
+		return ErrNotDraft
+	}
+	form.LoginEmail = item.LoginEmail
+	item.JobPostFormProps = form
+	item.UpdatedAt = now.Format(time.RFC3339)
+	item.TTL = now.Add(DraftTTL).Unix()
+	return nil
+}
+
+// ReturnToDraft makes a draft whose checkout was abandoned a draft again, kept for DraftTTL from
+// now. Its pending purchases are expired so their checkouts aren't checked again, and it leaves
+// the allJobsIndex.
+func (item *JobPostItem) ReturnToDraft(now time.Time) error {
+	next := *item
+	if err := next.Transition(Draft, now); err != nil {
+		return err
+	}
+	// The purchases are copied so a failure leaves the post unchanged.
+	next.BillingHistory = append([]Purchase(nil), item.Purchases()...)
+	for i, p := range next.BillingHistory {
+		if p.Status != PurchasePending {
+			continue
+		}
+		if err := next.ExpirePurchase(i, now); err != nil {
+			return err
+		}
+	}
+	next.AllJobs = ""
+	next.SessionID = ""
+	next.FromDraft = false
+	next.TTL = now.Add(DraftTTL).Unix()
+	*item = next
+	return nil
+}
+
+// DraftForm is a draft as it is returned to whoever saved it, its ID and form. Its key, billing and
+// moderation fields are left out like they are from listings.
+type DraftForm struct {
+	JobID string `json:"jobID"`
+	JobPostFormProps
+}
+
+// Form returns the draft as it is returned to whoever saved it.
+func (item JobPostItem) Form() DraftForm {
+	return DraftForm{JobID: item.JobID, JobPostFormProps: item.JobPostFormProps}
+}
+
+// DraftPreview is how a draft would look on the listing if it were paid for now.
+type DraftPreview struct {
+	JobPost  JobPostItem `json:"jobPost"`
+	Price    int64       `json:"price"`
+	Currency Currency    `json:"currency"`
+	// Issues must be fixed before the draft can be checked out, it is ready when there are none.
+	Issues []string `json:"issues"`
+}
+
+// Preview returns how the draft would look once paid for at now.
+func (item JobPostItem) Preview(now time.Time) DraftPreview {
+	post := item
+	post.Status = Active
+	post.ExpiresAt = now.AddDate(0, 0, item.PlanDuration).Format(time.RFC3339)
+	post.Featured = item.PlanType == Premium
+	post.TTL = 0
+
+	issues := append(ValidatePlan(item.PlanType, item.PlanDuration), item.Details().Validate()...)
+	if item.CompanyID == "" && Slugify(item.CompanyName) == "" {
+		issues = append(issues, "companyName must contain at least one letter or number")
+	}
+	preview := DraftPreview{JobPost: post, Currency: BillingCurrency, Issues: issues}
+	if len(ValidatePlan(item.PlanType, item.PlanDuration)) == 0 {
+		preview.Price = Price(item.PlanType, item.PlanDuration)
+	}
+	if preview.Issues == nil {
+		preview.Issues = []string{}
+	}
+	return preview
+}
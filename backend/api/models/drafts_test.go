@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReturnToDraft(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	checkout := func(status PurchaseStatus) JobPostItem {
+		return JobPostItem{
+			JobID:          "job-1",
+			SessionID:      "cs_1",
+			AllJobs:        "ALL_JOBS",
+			Status:         PendingPayment,
+			FromDraft:      true,
+			TTL:            now.Add(-time.Hour).Unix(),
+			BillingHistory: []Purchase{{SessionID: "cs_1", Kind: InitialPurchase, Status: status}},
+		}
+	}
+
+	item := checkout(PurchasePending)
+	if err := item.ReturnToDraft(now); err != nil {
+		t.Fatalf("ReturnToDraft() = %v", err)
+	}
+	if !item.IsDraft(now) {
+		t.Errorf("post is %s with ttl %d, want a draft", item.Status, item.TTL)
+	}
+	if item.TTL != now.Add(DraftTTL).Unix() {
+		t.Errorf("ttl = %d, want %d", item.TTL, now.Add(DraftTTL).Unix())
+	}
+	if item.AllJobs != "" || item.SessionID != "" || item.FromDraft {
+		t.Errorf("draft kept allJobs %q, sessionID %q and fromDraft %v", item.AllJobs, item.SessionID, item.FromDraft)
+	}
+	if got := item.BillingHistory[0].Status; got != PurchaseExpired {
+		t.Errorf("purchase status = %s, want %s", got, PurchaseExpired)
+	}
+
+	paid := checkout(PurchasePaid)
+	err := paid.ReturnToDraft(now)
+	if !errors.Is(err, ErrPaid) {
+		t.Fatalf("ReturnToDraft() of a paid checkout = %v, want %v", err, ErrPaid)
+	}
+	if !reflect.DeepEqual(paid, checkout(PurchasePaid)) {
+		t.Error("a failed ReturnToDraft() changed the post")
+	}
+}
+
+func TestDraftForm(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := NewDraft("job-1", JobPostFormProps{Title: "Backend Engineer", LoginEmail: "owner@acme.com"}, now)
+
+	data, err := json.Marshal(item.Form())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, private := range []string{"PK", "SK", "status", "createdAt", "updatedAt", "sessionID", "clickedApplyCount"} {
+		if _, ok := fields[private]; ok {
+			t.Errorf("Form() includes %s", private)
+		}
+	}
+	if fields["jobID"] != "job-1" || fields["title"] != "Backend Engineer" || fields["loginEmail"] != "owner@acme.com" {
+		t.Errorf("Form() = %s, want the draft's ID and form", data)
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+// PossibleDuplicate is another post from the same company whose title and description are nearly
+// the same as a post's.
+type PossibleDuplicate struct {
+	JobID     string `dynamodbav:"jobID" json:"jobID"`
+	Title     string `dynamodbav:"title" json:"title"`
+	Status    Status `dynamodbav:"status" json:"status"`
+	CreatedAt string `dynamodbav:"createdAt" json:"createdAt"`
+	// Similarity is the estimated share of the two posts' text they have in common, from 0 to 1.
+	Similarity float64 `dynamodbav:"similarity" json:"similarity"`
+}
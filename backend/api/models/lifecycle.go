@@ -0,0 +1,203 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Statuses lists every status a post can have, in the order a post usually goes through them.
+var Statuses = []Status{Draft, PendingPayment, PendingReview, Active, Paused, Expired, Filled, Cancelled, Removed}
+
+var (
+	// ErrInvalidTransition is returned when a post can never move between two statuses, such as out
+	// of Cancelled or Removed.
+	ErrInvalidTransition = errors.New("transition is not allowed")
+	// ErrNotPaid is returned when a post would go live without a paid purchase.
+	ErrNotPaid = errors.New("job post has no paid purchase")
+	// ErrPaid is returned when a checked out draft would go back to being a draft after it was paid.
+	ErrPaid = errors.New("job post has a paid purchase")
+	// ErrNoTimeLeft is returned when an expired post would go live again without paid time left.
+	ErrNoTimeLeft = errors.New("job post has no paid time left")
+	// ErrNotLapsed is returned when a post would expire with paid time left.
+	ErrNotLapsed = errors.New("job post has paid time left")
+	// ErrPauseLimit is returned when pausing a post that has used all of its pause time.
+	ErrPauseLimit = errors.New("job post has used all of its pause time")
+	// ErrNotReviewed is returned when a post in review would leave it without a moderator deciding.
+	ErrNotReviewed = errors.New("job post has no matching review")
+)
+
+// TransitionError is returned when a post can't move from one status to another, Err says why.
+type TransitionError struct {
+	From Status
+	To   Status
+	Err  error
+}
+
+func (e TransitionError) Error() string {
+	return fmt.Sprintf("job post can't go from %s to %s: %v", e.From, e.To, e.Err)
+}
+
+func (e TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// guard checks that a post may make a transition now, a nil guard always allows it.
+type guard func(item JobPostItem, now time.Time) error
+
+// transitions lists the statuses a post can move to from each status. Filled, Cancelled and
+// Removed are final, a post in them can't change status again.
+var transitions = map[Status]map[Status]guard{
+	Draft: {
+		PendingPayment: nil,
+	},
+	PendingPayment: {
+		Draft:         abandoned,
+		Active:        paid,
+		PendingReview: paid,
+		Cancelled:     nil,
+	},
+	PendingReview: {
+		Active:    reviewed(Approved),
+		Removed:   reviewed(Rejected),
+		Expired:   lapsed,
+		Cancelled: nil,
+	},
+	Active: {
+		PendingReview: nil,
+		Paused:        pausable,
+		Expired:       lapsed,
+		Filled:        nil,
+		Cancelled:     nil,
+	},
+	Paused: {
+		Active:        resumable,
+		PendingReview: nil,
+		Expired:       pauseLapsed,
+		Filled:        nil,
+		Cancelled:     nil,
+	},
+	Expired: {
+		Active:    timeLeft,
+		Filled:    nil,
+		Cancelled: nil,
+	},
+}
+
+// paid allows a post to leave PendingPayment once one of its purchases is paid.
+func paid(item JobPostItem, now time.Time) error {
+	for _, p := range item.Purchases() {
+		if p.Status == PurchasePaid {
+			return nil
+		}
+	}
+	return ErrNotPaid
+}
+
+// abandoned allows a checked out draft to go back to being a draft while none of its purchases
+// is paid. Posts that were never drafts are deleted instead.
+func abandoned(item JobPostItem, now time.Time) error {
+	if !item.FromDraft {
+		return ErrNotDraft
+	}
+	if paid(item, now) == nil {
+		return ErrPaid
+	}
+	return nil
+}
+
+// timeLeft allows an expired post to go live again once a renewal has moved its ExpiresAt.
+func timeLeft(item JobPostItem, now time.Time) error {
+	expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt)
+	if err != nil || !expiresAt.After(now) {
+		return ErrNoTimeLeft
+	}
+	return nil
+}
+
+// lapsed allows a post to expire once its ExpiresAt has passed.
+func lapsed(item JobPostItem, now time.Time) error {
+	expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt)
+	if err == nil && expiresAt.After(now) {
+		return ErrNotLapsed
+	}
+	return nil
+}
+
+// resumable allows a paused post to go live again while it has paid time left, once the time it
+// was paused is credited back.
+func resumable(item JobPostItem, now time.Time) error {
+	item.endPause(item.pauseCredit(now))
+	return timeLeft(item, now)
+}
+
+// pauseLapsed allows a paused post to expire once its paid time has run out, even with the time it
+// was paused credited back.
+func pauseLapsed(item JobPostItem, now time.Time) error {
+	item.endPause(item.pauseCredit(now))
+	return lapsed(item, now)
+}
+
+// pausable allows a live post to be paused while it has paid time and pause time left.
+func pausable(item JobPostItem, now time.Time) error {
+	if err := timeLeft(item, now); err != nil {
+		return err
+	}
+	if item.PauseLeft() <= 0 {
+		return ErrPauseLimit
+	}
+	return nil
+}
+
+// reviewed allows a post to leave review once a moderator's latest decision is decision.
+func reviewed(decision ReviewDecision) guard {
+	return func(item JobPostItem, now time.Time) error {
+		if len(item.Reviews) == 0 || item.Reviews[len(item.Reviews)-1].Decision != decision {
+			return ErrNotReviewed
+		}
+		return nil
+	}
+}
+
+// CanTransition reports whether the lifecycle has a transition from one status to the other,
+// regardless of its guard. A post can always stay in its status.
+func CanTransition(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	_, ok := transitions[from][to]
+	return ok
+}
+
+// Sources returns the statuses a post can be in to move to status to, to included. Writes are
+// conditioned on the stored post being in one of them.
+func Sources(to Status) []Status {
+	var sources []Status
+	for _, from := range Statuses {
+		if CanTransition(from, to) {
+			sources = append(sources, from)
+		}
+	}
+	return sources
+}
+
+// Transition moves the post to status to if the lifecycle allows it from its status and the
+// transition's guard passes, otherwise it returns a TransitionError and the post is unchanged.
+// Staying in the same status is always allowed.
+func (item *JobPostItem) Transition(to Status, now time.Time) error {
+	if item.Status == to {
+		return nil
+	}
+	g, ok := transitions[item.Status][to]
+	if !ok {
+		return TransitionError{From: item.Status, To: to, Err: ErrInvalidTransition}
+	}
+	if g != nil {
+		if err := g(*item, now); err != nil {
+			return TransitionError{From: item.Status, To: to, Err: err}
+		}
+	}
+	item.Status = to
+	item.UpdatedAt = now.Format(time.RFC3339)
+	return nil
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTransition(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	future := now.AddDate(0, 0, 10).Format(time.RFC3339)
+	past := now.AddDate(0, 0, -1).Format(time.RFC3339)
+	paidPurchase := []Purchase{{SessionID: "cs_1", Status: PurchasePaid}}
+	pendingPurchase := []Purchase{{SessionID: "cs_1", Status: PurchasePending}}
+	tests := []struct {
+		name    string
+		item    JobPostItem
+		to      Status
+		wantErr error
+	}{
+		{name: "draft checked out", item: JobPostItem{Status: Draft}, to: PendingPayment},
+		{name: "draft can't go live", item: JobPostItem{Status: Draft, BillingHistory: paidPurchase}, to: Active, wantErr: ErrInvalidTransition},
+		{name: "paid goes live", item: JobPostItem{Status: PendingPayment, BillingHistory: paidPurchase}, to: Active},
+		{name: "paid held for review", item: JobPostItem{Status: PendingPayment, BillingHistory: paidPurchase}, to: PendingReview},
+		{name: "unpaid can't go live", item: JobPostItem{Status: PendingPayment, BillingHistory: pendingPurchase}, to: Active, wantErr: ErrNotPaid},
+		{name: "unpaid cancelled", item: JobPostItem{Status: PendingPayment}, to: Cancelled},
+		{name: "abandoned checkout of a draft", item: JobPostItem{Status: PendingPayment, FromDraft: true, BillingHistory: pendingPurchase}, to: Draft},
+		{name: "paid checkout of a draft", item: JobPostItem{Status: PendingPayment, FromDraft: true, BillingHistory: paidPurchase}, to: Draft, wantErr: ErrPaid},
+		{name: "never a draft", item: JobPostItem{Status: PendingPayment, BillingHistory: pendingPurchase}, to: Draft, wantErr: ErrNotDraft},
+		{name: "approved", item: JobPostItem{Status: PendingReview, Reviews: []Review{{Decision: Approved}}}, to: Active},
+		{name: "not reviewed", item: JobPostItem{Status: PendingReview}, to: Active, wantErr: ErrNotReviewed},
+		{name: "approval can't remove", item: JobPostItem{Status: PendingReview, Reviews: []Review{{Decision: Approved}}}, to: Removed, wantErr: ErrNotReviewed},
+		{name: "latest review counts", item: JobPostItem{Status: PendingReview, Reviews: []Review{{Decision: Approved}, {Decision: Rejected}}}, to: Removed},
+		{name: "lapsed in review", item: JobPostItem{Status: PendingReview, ExpiresAt: past}, to: Expired},
+		{name: "live expires", item: JobPostItem{Status: Active, ExpiresAt: past}, to: Expired},
+		{name: "live with time left", item: JobPostItem{Status: Active, ExpiresAt: future}, to: Expired, wantErr: ErrNotLapsed},
+		{name: "paused", item: JobPostItem{Status: Active, ExpiresAt: future, JobPostFormProps: JobPostFormProps{PlanType: Standard}}, to: Paused},
+		{name: "paused without time left", item: JobPostItem{Status: Active, ExpiresAt: past, JobPostFormProps: JobPostFormProps{PlanType: Standard}}, to: Paused, wantErr: ErrNoTimeLeft},
+		{name: "paused without pause left", item: JobPostItem{Status: Active, ExpiresAt: future, PausedSeconds: int64(MaxPause(Standard) / time.Second), JobPostFormProps: JobPostFormProps{PlanType: Standard}}, to: Paused, wantErr: ErrPauseLimit},
+		{name: "paused filled", item: JobPostItem{Status: Paused}, to: Filled},
+		{name: "paused held for review", item: JobPostItem{Status: Paused}, to: PendingReview},
+		{name: "paused resumed", item: JobPostItem{Status: Paused, ExpiresAt: future}, to: Active},
+		{name: "paused resumed without time left", item: JobPostItem{Status: Paused, ExpiresAt: past}, to: Active, wantErr: ErrNoTimeLeft},
+		{name: "paused resumed with the pause credited", item: JobPostItem{Status: Paused, ExpiresAt: past, PausedAt: now.AddDate(0, 0, -3).Format(time.RFC3339), JobPostFormProps: JobPostFormProps{PlanType: Standard}}, to: Active},
+		{name: "paused expires", item: JobPostItem{Status: Paused, ExpiresAt: past}, to: Expired},
+		{name: "paused with the pause credited can't expire", item: JobPostItem{Status: Paused, ExpiresAt: past, PausedAt: now.AddDate(0, 0, -3).Format(time.RFC3339), JobPostFormProps: JobPostFormProps{PlanType: Standard}}, to: Expired, wantErr: ErrNotLapsed},
+		{name: "renewed", item: JobPostItem{Status: Expired, ExpiresAt: future}, to: Active},
+		{name: "expired without renewal", item: JobPostItem{Status: Expired, ExpiresAt: past}, to: Active, wantErr: ErrNoTimeLeft},
+		{name: "filled is final", item: JobPostItem{Status: Filled}, to: Active, wantErr: ErrInvalidTransition},
+		{name: "cancelled is final", item: JobPostItem{Status: Cancelled}, to: Active, wantErr: ErrInvalidTransition},
+		{name: "removed is final", item: JobPostItem{Status: Removed}, to: Cancelled, wantErr: ErrInvalidTransition},
+		{name: "staying is allowed", item: JobPostItem{Status: Cancelled}, to: Cancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := tt.item
+			from := item.Status
+			err := item.Transition(tt.to, now)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Transition(%s) = %v", tt.to, err)
+				}
+				if item.Status != tt.to {
+					t.Errorf("status = %s, want %s", item.Status, tt.to)
+				}
+				if from != tt.to && item.UpdatedAt != now.Format(time.RFC3339) {
+					t.Errorf("updatedAt = %q, want %q", item.UpdatedAt, now.Format(time.RFC3339))
+				}
+				return
+			}
+			var transitionErr TransitionError
+			if !errors.As(err, &transitionErr) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Transition(%s) = %v, want a TransitionError for %v", tt.to, err, tt.wantErr)
+			}
+			if transitionErr.From != from || transitionErr.To != tt.to {
+				t.Errorf("TransitionError is %s to %s, want %s to %s", transitionErr.From, transitionErr.To, from, tt.to)
+			}
+			if !reflect.DeepEqual(item, tt.item) {
+				t.Errorf("a failed Transition(%s) changed the post", tt.to)
+			}
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	tests := []struct {
+		to   Status
+		want []Status
+	}{
+		{to: Draft, want: []Status{Draft, PendingPayment}},
+		{to: PendingPayment, want: []Status{Draft, PendingPayment}},
+		{to: PendingReview, want: []Status{PendingPayment, PendingReview, Active, Paused}},
+		{to: Active, want: []Status{PendingPayment, PendingReview, Active, Paused, Expired}},
+		{to: Paused, want: []Status{Active, Paused}},
+		{to: Expired, want: []Status{PendingReview, Active, Paused, Expired}},
+		{to: Filled, want: []Status{Active, Paused, Expired, Filled}},
+		{to: Cancelled, want: []Status{PendingPayment, PendingReview, Active, Paused, Expired, Cancelled}},
+		{to: Removed, want: []Status{PendingReview, Removed}},
+	}
+	for _, tt := range tests {
+		if got := Sources(tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Sources(%s) = %v, want %v", tt.to, got, tt.want)
+		}
+	}
+	// Every status is covered, so a new one can't be left out of the lifecycle by accident.
+	if len(tests) != len(Statuses) {
+		t.Errorf("tested %d statuses, want all %d", len(tests), len(Statuses))
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+// JobPostListing is what visitors see of a post. It leaves out the recruiter's details, billing and
+// moderation, which only the post's company and admins see.
+type JobPostListing struct {
+	JobID           string   `json:"jobID"`
+	CompanyLogoURL  *string  `json:"companyLogoURL,omitempty"`
+	CompanyName     string   `json:"companyName"`
+	CompanyWebsite  string   `json:"companyWebsite"`
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	HowToApply      string   `json:"howToApply"`
+	Location        string   `json:"location"`
+	Currency        Currency `json:"currency"`
+	MinSalary       int      `json:"minSalary"`
+	MaxSalary       int      `json:"maxSalary"`
+	MinYOE          int      `json:"minYOE"`
+	VisaSponsorship bool     `json:"visaSponsorship"`
+	PlanType        PlanType `json:"planType"`
+	CreatedAt       string   `json:"createdAt"`
+	ExpiresAt       string   `json:"expiresAt"`
+	Featured        bool     `json:"featured"`
+}
+
+// Listing returns the post as visitors see it.
+func (item JobPostItem) Listing() JobPostListing {
+	return JobPostListing{
+		JobID:           item.JobID,
+		CompanyLogoURL:  item.CompanyLogoURL,
+		CompanyName:     item.CompanyName,
+		CompanyWebsite:  item.CompanyWebsite,
+		Title:           item.Title,
+		Description:     item.Description,
+		HowToApply:      item.HowToApply,
+		Location:        item.Location,
+		Currency:        item.Currency,
+		MinSalary:       item.MinSalary,
+		MaxSalary:       item.MaxSalary,
+		MinYOE:          item.MinYOE,
+		VisaSponsorship: item.VisaSponsorship,
+		PlanType:        item.PlanType,
+		CreatedAt:       item.CreatedAt,
+		ExpiresAt:       item.ExpiresAt,
+		Featured:        item.Featured,
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestListing(t *testing.T) {
+	item := JobPostItem{
+		JobID:           "job-1",
+		SessionID:       "cs_1",
+		Status:          Active,
+		LoginVerified:   true,
+		BillingHistory:  []Purchase{{SessionID: "cs_1", Amount: 3500}},
+		Refunds:         []Refund{{SessionID: "cs_1", Amount: 3500}},
+		CancelledBy:     "owner@acme.com",
+		ContentScore:    &ContentScore{Score: 10},
+		ReportCount:     2,
+		OpenReportCount: 1,
+		Reviews:         []Review{{Decision: Approved, ReviewedBy: "admin@upfront.com"}},
+		Featured:        true,
+	}
+	item.Title = "Backend Engineer"
+	item.LoginEmail = "owner@acme.com"
+	item.PossibleDuplicates = []PossibleDuplicate{{JobID: "job-2"}}
+
+	data, err := json.Marshal(item.Listing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, private := range []string{"PK", "SK", "allJobs", "sessionID", "loginEmail", "status", "loginVerified", "billingHistory", "refunds", "cancelledBy", "contentScore", "possibleDuplicates", "reportCount", "openReportCount", "reviews", "clickedApplyCount", "successURL", "cancelURL"} {
+		if _, ok := fields[private]; ok {
+			t.Errorf("Listing() includes %s", private)
+		}
+	}
+	if fields["jobID"] != "job-1" || fields["title"] != "Backend Engineer" || fields["featured"] != true {
+		t.Errorf("Listing() = %s, want the post's listing fields", data)
+	}
+}
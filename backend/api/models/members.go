@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Role is what a member can do with their company and its job posts.
+type Role string
+
+const (
+	// Owner can also manage members and transfer job posts between them.
+	Owner Role = "owner"
+	// Editor can edit the company profile and renew, upgrade and cancel job posts.
+	Editor Role = "editor"
+	// Viewer can see the company's job posts and invoices.
+	Viewer Role = "viewer"
+)
+
+// Roles are every role, most powerful first.
+var Roles = []Role{Owner, Editor, Viewer}
+
+var roleRanks = map[Role]int{
+	Viewer: 1,
+	Editor: 2,
+	Owner:  3,
+}
+
+// Valid reports whether r is one of Roles.
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast reports whether r can do everything min can.
+func (r Role) AtLeast(min Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[min]
+}
+
+type MemberStatus string
+
+const (
+	// Invited members have been emailed an invitation they haven't accepted yet.
+	Invited      MemberStatus = "Invited"
+	ActiveMember MemberStatus = "Active"
+)
+
+// InvitationTTL is how long an invitation can be accepted for.
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Member is a recruiter's membership of a company, stored in the company's partition.
+type Member struct {
+	PK string `dynamodbav:"PK" json:"-"`
+	SK string `dynamodbav:"SK" json:"-"`
+	// CompanyID isn't stored as companyID, so members aren't in the companyIndex GSI.
+	CompanyID string       `dynamodbav:"memberOf" json:"companyID"`
+	Email     string       `dynamodbav:"memberEmail" json:"email"`
+	Role      Role         `dynamodbav:"role" json:"role"`
+	Status    MemberStatus `dynamodbav:"status" json:"status"`
+	InvitedBy string       `dynamodbav:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+	InvitedAt string       `dynamodbav:"invitedAt,omitempty" json:"invitedAt,omitempty"`
+	JoinedAt  string       `dynamodbav:"joinedAt,omitempty" json:"joinedAt,omitempty"`
+	// TTL is when DynamoDB deletes an invitation that wasn't accepted, in epoch seconds.
+	TTL int64 `dynamodbav:"ttl,omitempty" json:"-"`
+}
+
+// NewInvitation invites email to join a company with role.
+func NewInvitation(companyID, email string, role Role, invitedBy string, now time.Time) Member {
+	email = strings.ToLower(email)
+	return Member{
+		PK:        FormatCompanyPK(companyID),
+		SK:        FormatMemberSK(email),
+		CompanyID: companyID,
+		Email:     email,
+		Role:      role,
+		Status:    Invited,
+		InvitedBy: strings.ToLower(invitedBy),
+		InvitedAt: now.Format(time.RFC3339),
+		TTL:       now.Add(InvitationTTL).Unix(),
+	}
+}
+
+// NewOwner makes email the first member of a new company.
+func NewOwner(companyID, email string, now time.Time) Member {
+	email = strings.ToLower(email)
+	return Member{
+		PK:        FormatCompanyPK(companyID),
+		SK:        FormatMemberSK(email),
+		CompanyID: companyID,
+		Email:     email,
+		Role:      Owner,
+		Status:    ActiveMember,
+		JoinedAt:  now.Format(time.RFC3339),
+	}
+}
+
+// Active reports whether the member has joined, invitations don't grant any access.
+func (m Member) Active() bool {
+	return m.Status == ActiveMember
+}
+
+// InvitationExpired reports whether an invitation can no longer be accepted. DynamoDB can take a
+// while to delete expired items, so the TTL is checked too.
+func (m Member) InvitationExpired(now time.Time) bool {
+	return m.Status == Invited && m.TTL != 0 && now.Unix() >= m.TTL
+}
+
+// MemberSKPrefix begins the sort key of every member of a company.
+const MemberSKPrefix = "member/"
+
+func FormatMemberSK(email string) string {
+	return fmt.Sprintf("%s%s", MemberSKPrefix, strings.ToLower(email))
+}
@@ -1,6 +1,12 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
 
 type Currency string
 
@@ -16,6 +22,8 @@ const (
 	JPY Currency = "JPY"
 )
 
+var Currencies = []Currency{GBP, USD, EUR, AUD, CAD, SGD, CHF, INR, JPY}
+
 type PlanType string
 
 const (
@@ -25,13 +33,27 @@ const (
 
 type Status string
 
+// The statuses a post moves between are listed in lifecycle.go.
 const (
+	// Draft posts are saved by a recruiter but not yet submitted for payment.
+	Draft          Status = "Draft"
 	Active         Status = "Active"
 	Expired        Status = "Expired"
 	PendingPayment Status = "PendingPayment"
+	Cancelled      Status = "Cancelled"
+	// PendingReview posts are paid for and waiting for a moderator, see HoldForReview.
+	PendingReview Status = "PendingReview"
+	// Removed posts were rejected by a moderator.
+	Removed Status = "Removed"
+	// Paused posts are taken off the listing by their recruiter, their paid time stops meanwhile.
+	Paused Status = "Paused"
+	// Filled posts were closed by their recruiter after hiring someone, see Fill.
+	Filled Status = "Filled"
 )
 
 type JobPostFormProps struct {
+	// CompanyID references the post's company, the other company fields are copied from it.
+	CompanyID       string   `json:"companyID,omitempty" dynamodbav:"companyID,omitempty"`
 	CompanyLogoURL  *string  `json:"companyLogoURL,omitempty" dynamodbav:"companyLogoURL"`
 	CompanyName     string   `json:"companyName" dynamodbav:"companyName"`
 	CompanyWebsite  string   `json:"companyWebsite" dynamodbav:"companyWebsite"`
@@ -55,7 +77,7 @@ type JobPostItem struct {
 	JobPostFormProps
 	PK                string `dynamodbav:"PK" json:"PK"`
 	SK                string `dynamodbav:"SK" json:"SK"`
-	AllJobs           string `dynamodbav:"allJobs" json:"allJobs"`
+	AllJobs           string `dynamodbav:"allJobs,omitempty" json:"allJobs"`
 	JobID             string `dynamodbav:"jobID" json:"jobID"`
 	SessionID         string `dynamodbav:"sessionID" json:"sessionID"`
 	CreatedAt         string `dynamodbav:"createdAt" json:"createdAt"`
@@ -63,8 +85,56 @@ type JobPostItem struct {
 	ExpiresAt         string `dynamodbav:"expiresAt" json:"expiresAt"`
 	ClickedApplyCount int    `dynamodbav:"clickedApplyCount" json:"clickedApplyCount"`
 	Status            Status `dynamodbav:"status" json:"status"`
+	// Version is moved on by every write of the post, writes are conditioned on it so none is lost
+	// to another made since the post was read.
+	Version int64 `dynamodbav:"version" json:"-"`
+	// LoginVerified is set when the post was checked out by a signed in recruiter, so LoginEmail is
+	// known to be theirs and the post can join a company they belong to.
+	LoginVerified bool `dynamodbav:"loginVerified,omitempty" json:"loginVerified,omitempty"`
+	// FromDraft is set when the post was checked out from a draft, it goes back to being one if the
+	// checkout is abandoned.
+	FromDraft bool `dynamodbav:"fromDraft,omitempty" json:"-"`
+	// BillingHistory records every purchase made for the post, oldest first.
+	BillingHistory []Purchase `dynamodbav:"billingHistory,omitempty" json:"billingHistory,omitempty"`
+	// Refunds records every refund issued for the post's purchases.
+	Refunds     []Refund `dynamodbav:"refunds,omitempty" json:"refunds,omitempty"`
+	CancelledAt string   `dynamodbav:"cancelledAt,omitempty" json:"cancelledAt,omitempty"`
+	CancelledBy string   `dynamodbav:"cancelledBy,omitempty" json:"cancelledBy,omitempty"`
+	// ContentScore is set when the post is created or edited.
+	ContentScore *ContentScore `dynamodbav:"contentScore,omitempty" json:"contentScore,omitempty"`
+	// MinHash is the signature duplicates are found with, see the duplicates package.
+	MinHash []uint32 `dynamodbav:"minHash,omitempty" json:"-"`
+	// PossibleDuplicates are the company's other posts that were nearly the same as this one when
+	// it was created or last edited.
+	PossibleDuplicates []PossibleDuplicate `dynamodbav:"possibleDuplicates,omitempty" json:"possibleDuplicates,omitempty"`
+	// ReportCount is the number of visitors who have reported the post, OpenReportCount those whose
+	// reports haven't been resolved by an admin.
+	ReportCount     int `dynamodbav:"reportCount,omitempty" json:"reportCount,omitempty"`
+	OpenReportCount int `dynamodbav:"openReportCount,omitempty" json:"openReportCount,omitempty"`
+	// ReviewRequestedAt is when the post last entered PendingReview.
+	ReviewRequestedAt string `dynamodbav:"reviewRequestedAt,omitempty" json:"reviewRequestedAt,omitempty"`
+	// PausedAt is when the post was last paused, it is cleared on resume. PausedSeconds is the
+	// paused time credited back to the post so far, see MaxPause.
+	PausedAt      string `dynamodbav:"pausedAt,omitempty" json:"pausedAt,omitempty"`
+	PausedSeconds int64  `dynamodbav:"pausedSeconds,omitempty" json:"pausedSeconds,omitempty"`
+	// FilledAt is when the recruiter marked the post filled, HireChannel where the hire came from
+	// if they said.
+	FilledAt    string      `dynamodbav:"filledAt,omitempty" json:"filledAt,omitempty"`
+	HireChannel HireChannel `dynamodbav:"hireChannel,omitempty" json:"hireChannel,omitempty"`
+	// AwaitingChanges is set while a moderator is waiting for the recruiter to change the post.
+	AwaitingChanges bool `dynamodbav:"awaitingChanges,omitempty" json:"awaitingChanges,omitempty"`
+	// Reviews records every moderation decision on the post, oldest first.
+	Reviews []Review `dynamodbav:"reviews,omitempty" json:"reviews,omitempty"`
+	// Featured is set by the public listing on the Premium posts it pins to the top, it isn't stored.
+	Featured bool `dynamodbav:"-" json:"featured"`
+	// TTL is when DynamoDB deletes an unpaid post, in epoch seconds. It is removed on activation.
+	TTL int64 `dynamodbav:"ttl,omitempty" json:"-"`
 }
 
+// PendingPaymentTTL is how long an unpaid post is kept. Checkout sessions expire after 24 hours
+// and the reconciliation job normally removes abandoned posts long before this.
+const PendingPaymentTTL = 7 * 24 * time.Hour
+
 func FormatPK(id string) string {
 	return fmt.Sprintf("job/%s", id)
 }
@@ -72,3 +142,66 @@ func FormatPK(id string) string {
 func FormatSK(email string) string {
 	return fmt.Sprintf("email/%s", email)
 }
+
+// JobPostDetails are the parts of a post its recruiter can edit after paying for it.
+type JobPostDetails struct {
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	HowToApply      string   `json:"howToApply"`
+	Location        string   `json:"location"`
+	Currency        Currency `json:"currency"`
+	MinSalary       int      `json:"minSalary"`
+	MaxSalary       int      `json:"maxSalary"`
+	MinYOE          int      `json:"minYOE"`
+	VisaSponsorship bool     `json:"visaSponsorship"`
+}
+
+// Validate returns the issues with the details, if any.
+func (d JobPostDetails) Validate() []string {
+	var issues []string
+	for field, v := range map[string]string{"title": d.Title, "description": d.Description, "howToApply": d.HowToApply} {
+		if strings.TrimSpace(v) == "" {
+			issues = append(issues, fmt.Sprintf("%s is required", field))
+		}
+	}
+	if !slices.Contains(Currencies, d.Currency) {
+		issues = append(issues, fmt.Sprintf("currency must be one of %v", Currencies))
+	}
+	if d.MinSalary < 0 || d.MinYOE < 0 {
+		issues = append(issues, "minSalary and minYOE can't be negative")
+	}
+	if d.MaxSalary < d.MinSalary {
+		issues = append(issues, "maxSalary can't be less than minSalary")
+	}
+	sort.Strings(issues)
+	return issues
+}
+
+// Details returns the post's editable details.
+func (item JobPostItem) Details() JobPostDetails {
+	return JobPostDetails{
+		Title:           item.Title,
+		Description:     item.Description,
+		HowToApply:      item.HowToApply,
+		Location:        item.Location,
+		Currency:        item.Currency,
+		MinSalary:       item.MinSalary,
+		MaxSalary:       item.MaxSalary,
+		MinYOE:          item.MinYOE,
+		VisaSponsorship: item.VisaSponsorship,
+	}
+}
+
+// Edit replaces the post's editable details.
+func (item *JobPostItem) Edit(d JobPostDetails, now time.Time) {
+	item.Title = d.Title
+	item.Description = d.Description
+	item.HowToApply = d.HowToApply
+	item.Location = d.Location
+	item.Currency = d.Currency
+	item.MinSalary = d.MinSalary
+	item.MaxSalary = d.MaxSalary
+	item.MinYOE = d.MinYOE
+	item.VisaSponsorship = d.VisaSponsorship
+	item.UpdatedAt = now.Format(time.RFC3339)
+}
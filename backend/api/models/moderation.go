@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type ReviewDecision string
+
+const (
+	Approved ReviewDecision = "Approved"
+	// Rejected takes the post down for good and refunds it in full.
+	Rejected ReviewDecision = "Rejected"
+	// ChangesRequested keeps the post in review until the recruiter edits it.
+	ChangesRequested ReviewDecision = "ChangesRequested"
+)
+
+// Review is a moderator's decision on a post that was pending review.
+type Review struct {
+	Decision   ReviewDecision `dynamodbav:"decision" json:"decision"`
+	Reason     string         `dynamodbav:"reason,omitempty" json:"reason,omitempty"`
+	ReviewedBy string         `dynamodbav:"reviewedBy" json:"reviewedBy"`
+	ReviewedAt string         `dynamodbav:"reviewedAt" json:"reviewedAt"`
+}
+
+// ErrNotPendingReview is returned when reviewing a post that isn't waiting for a moderator.
+var ErrNotPendingReview = errors.New("job post is not pending review")
+
+// HoldForReview takes the post off the listing until a moderator approves it, or puts a post the
+// recruiter has changed back in the queue. The post's paid time stops while it waits, approving it
+// adds the time spent in review to ExpiresAt. A paused post's pause ends, it is credited as if it
+// had resumed, and approving it puts it live.
+func (item *JobPostItem) HoldForReview(now time.Time) error {
+	held := item.Status == PendingReview
+	paused := item.Status == Paused
+	credit := item.pauseCredit(now)
+	if err := item.Transition(PendingReview, now); err != nil {
+		return err
+	}
+	if paused {
+		item.endPause(credit)
+	}
+	if !held {
+		item.ReviewRequestedAt = now.Format(time.RFC3339)
+	}
+	item.AwaitingChanges = false
+	item.UpdatedAt = now.Format(time.RFC3339)
+	return nil
+}
+
+// Review records a moderator's decision. An approved post goes live, a rejected one is removed and
+// every purchase is recorded as a pending refund, see Cancel.
+func (item *JobPostItem) Review(now time.Time, by string, decision ReviewDecision, reason string) error {
+	if item.Status != PendingReview {
+		return ErrNotPendingReview
+	}
+	item.Reviews = append(item.Reviews, Review{
+		Decision:   decision,
+		Reason:     reason,
+		ReviewedBy: by,
+		ReviewedAt: now.Format(time.RFC3339),
+	})
+	item.AwaitingChanges = decision == ChangesRequested
+	switch decision {
+	case Approved:
+		reviewRequestedAt, err := time.Parse(time.RFC3339, item.ReviewRequestedAt)
+		expiresAt, expiresErr := time.Parse(time.RFC3339, item.ExpiresAt)
+		if err == nil && expiresErr == nil && now.After(reviewRequestedAt) {
+			item.ExpiresAt = expiresAt.Add(now.Sub(reviewRequestedAt)).Format(time.RFC3339)
+		}
+		if err := item.Transition(Active, now); err != nil {
+			return err
+		}
+		item.ReviewRequestedAt = ""
+	case Rejected:
+		// Refunds are worked out while the post is still in review, see RefundsDue.
+		refunds := item.RefundsDue(now, true)
+		if err := item.Transition(Removed, now); err != nil {
+			return err
+		}
+		for _, r := range refunds {
+			r.Reason = reason
+			r.RequestedBy = by
+			item.Refunds = append(item.Refunds, r)
+		}
+		item.BillingHistory = item.Purchases()
+	}
+	item.UpdatedAt = now.Format(time.RFC3339)
+	return nil
+}
+
+// LiveSince returns when a post paid for at paidAt went live, which for a moderated post is the
+// first approval after paidAt.
+func (item JobPostItem) LiveSince(paidAt time.Time) time.Time {
+	for _, r := range item.Reviews {
+		if r.Decision != Approved {
+			continue
+		}
+		if reviewedAt, err := time.Parse(time.RFC3339, r.ReviewedAt); err == nil && reviewedAt.After(paidAt) {
+			return reviewedAt
+		}
+	}
+	return paidAt
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// HireChannel is where the person hired for a filled post came from.
+type HireChannel string
+
+const (
+	// HireUpfront is a hire who applied through the post on this board.
+	HireUpfront       HireChannel = "Upfront"
+	HireOtherJobBoard HireChannel = "OtherJobBoard"
+	HireReferral      HireChannel = "Referral"
+	HireAgency        HireChannel = "Agency"
+	HireInternal      HireChannel = "Internal"
+	HireOther         HireChannel = "Other"
+)
+
+var HireChannels = []HireChannel{HireUpfront, HireOtherJobBoard, HireReferral, HireAgency, HireInternal, HireOther}
+
+// ErrAlreadyFilled is returned when filling a post that was filled already.
+var ErrAlreadyFilled = errors.New("job post is already filled")
+
+// FilledGracePeriod is how long a filled post can still be looked up, shown as position filled, so
+// links to it don't break straight away.
+const FilledGracePeriod = 14 * 24 * time.Hour
+
+// ValidateHireChannel returns the issues with a hire channel, which is optional.
+func ValidateHireChannel(channel HireChannel) []string {
+	if channel == "" || slices.Contains(HireChannels, channel) {
+		return nil
+	}
+	return []string{fmt.Sprintf("hireChannel must be one of %v", HireChannels)}
+}
+
+// Fill closes the post early because the role was filled, recording where the hire came from if
+// channel is set. Nothing is refunded.
+func (item *JobPostItem) Fill(now time.Time, channel HireChannel) error {
+	if item.Status == Filled {
+		return ErrAlreadyFilled
+	}
+	if err := item.Transition(Filled, now); err != nil {
+		return err
+	}
+	item.FilledAt = now.Format(time.RFC3339)
+	item.HireChannel = channel
+	item.PausedAt = ""
+	return nil
+}
+
+// ShowsAsFilled reports whether the post was filled within FilledGracePeriod of now.
+func (item JobPostItem) ShowsAsFilled(now time.Time) bool {
+	filledAt, err := time.Parse(time.RFC3339, item.FilledAt)
+	return item.Status == Filled && err == nil && now.Sub(filledAt) < FilledGracePeriod
+}
+
+// TimeToFill returns how long the post was up before it was filled, from when it first went live.
+func (item JobPostItem) TimeToFill() (time.Duration, bool) {
+	filledAt, err := time.Parse(time.RFC3339, item.FilledAt)
+	if item.Status != Filled || err != nil {
+		return 0, false
+	}
+	for _, p := range item.Purchases() {
+		if p.Kind != InitialPurchase || p.Status != PurchasePaid {
+			continue
+		}
+		paidAt, err := time.Parse(time.RFC3339, p.PaidAt)
+		if err != nil {
+			return 0, false
+		}
+		if d := filledAt.Sub(item.LiveSince(paidAt)); d >= 0 {
+			return d, true
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+// HiringStats sums up the outcomes of a set of posts.
+type HiringStats struct {
+	Posts    int            `json:"posts"`
+	ByStatus map[Status]int `json:"byStatus"`
+	Filled   int            `json:"filled"`
+	// ByHireChannel counts the filled posts whose recruiter said where the hire came from.
+	ByHireChannel map[HireChannel]int `json:"byHireChannel"`
+	ApplyClicks   int                 `json:"applyClicks"`
+	// AverageDaysToFill and MedianDaysToFill are over the filled posts, see TimeToFill. They are
+	// null when none has been filled.
+	AverageDaysToFill *float64 `json:"averageDaysToFill"`
+	MedianDaysToFill  *float64 `json:"medianDaysToFill"`
+}
+
+// Stats sums up the outcomes of posts.
+func Stats(posts []JobPostItem) HiringStats {
+	stats := HiringStats{
+		ByStatus:      map[Status]int{},
+		ByHireChannel: map[HireChannel]int{},
+	}
+	var days []float64
+	for _, p := range posts {
+		stats.Posts++
+		stats.ByStatus[p.Status]++
+		stats.ApplyClicks += p.ClickedApplyCount
+		if p.Status != Filled {
+			continue
+		}
+		stats.Filled++
+		if p.HireChannel != "" {
+			stats.ByHireChannel[p.HireChannel]++
+		}
+		if d, ok := p.TimeToFill(); ok {
+			days = append(days, d.Hours()/24)
+		}
+	}
+	if len(days) == 0 {
+		return stats
+	}
+	slices.Sort(days)
+	var total float64
+	for _, d := range days {
+		total += d
+	}
+	average := total / float64(len(days))
+	median := days[len(days)/2]
+	if len(days)%2 == 0 {
+		median = (days[len(days)/2-1] + days[len(days)/2]) / 2
+	}
+	stats.AverageDaysToFill = &average
+	stats.MedianDaysToFill = &median
+	return stats
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// filledPost is a post paid for at paidAt and filled daysLater days after, through channel.
+func filledPost(paidAt time.Time, daysLater int, channel HireChannel) JobPostItem {
+	return JobPostItem{
+		Status:      Filled,
+		FilledAt:    paidAt.AddDate(0, 0, daysLater).Format(time.RFC3339),
+		HireChannel: channel,
+		BillingHistory: []Purchase{
+			{SessionID: "cs_initial", Kind: InitialPurchase, Status: PurchasePaid, PaidAt: paidAt.Format(time.RFC3339)},
+		},
+	}
+}
+
+func TestFill(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := JobPostItem{Status: Paused, PausedAt: now.Add(-time.Hour).Format(time.RFC3339)}
+	if err := item.Fill(now, HireReferral); err != nil {
+		t.Fatalf("Fill() = %v", err)
+	}
+	if item.Status != Filled || item.FilledAt != now.Format(time.RFC3339) || item.HireChannel != HireReferral || item.PausedAt != "" {
+		t.Errorf("after Fill() status = %s, filledAt = %q, hireChannel = %q, pausedAt = %q", item.Status, item.FilledAt, item.HireChannel, item.PausedAt)
+	}
+	if err := item.Fill(now, ""); !errors.Is(err, ErrAlreadyFilled) {
+		t.Errorf("Fill() of a filled post = %v, want ErrAlreadyFilled", err)
+	}
+	unpaid := JobPostItem{Status: PendingPayment}
+	if err := unpaid.Fill(now, ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Fill() of an unpaid post = %v, want ErrInvalidTransition", err)
+	}
+}
+
+func TestShowsAsFilled(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		status   Status
+		filledAt time.Time
+		want     bool
+	}{
+		{name: "just filled", status: Filled, filledAt: now, want: true},
+		{name: "within the grace period", status: Filled, filledAt: now.Add(-FilledGracePeriod + time.Hour), want: true},
+		{name: "after the grace period", status: Filled, filledAt: now.Add(-FilledGracePeriod), want: false},
+		{name: "not filled", status: Cancelled, filledAt: now, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := JobPostItem{Status: tt.status, FilledAt: tt.filledAt.Format(time.RFC3339)}
+			if got := item.ShowsAsFilled(now); got != tt.want {
+				t.Errorf("ShowsAsFilled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeToFill(t *testing.T) {
+	paidAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	reviewed := filledPost(paidAt, 10, "")
+	// Approved two days after payment, so it was only up for eight days.
+	reviewed.Reviews = []Review{
+		{Decision: Rejected, ReviewedAt: paidAt.Add(-day).Format(time.RFC3339)},
+		{Decision: Approved, ReviewedAt: paidAt.Add(2 * day).Format(time.RFC3339)},
+	}
+	renewed := filledPost(paidAt, 40, "")
+	renewed.BillingHistory = append(renewed.BillingHistory, Purchase{SessionID: "cs_renewal", Kind: Renewal, Status: PurchasePaid, PaidAt: paidAt.AddDate(0, 0, 30).Format(time.RFC3339)})
+	unpaid := filledPost(paidAt, 10, "")
+	unpaid.BillingHistory[0].Status = PurchaseExpired
+	notFilled := filledPost(paidAt, 10, "")
+	notFilled.Status = Active
+	before := filledPost(paidAt, -1, "")
+
+	tests := []struct {
+		name   string
+		item   JobPostItem
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "from payment", item: filledPost(paidAt, 10, ""), want: 10 * day, wantOK: true},
+		{name: "from approval", item: reviewed, want: 8 * day, wantOK: true},
+		{name: "renewals don't restart it", item: renewed, want: 40 * day, wantOK: true},
+		{name: "not filled", item: notFilled},
+		{name: "never paid", item: unpaid},
+		{name: "filled before it went live", item: before},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.item.TimeToFill()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("TimeToFill() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	paidAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	active := JobPostItem{Status: Active, ClickedApplyCount: 7}
+	filled := []JobPostItem{
+		filledPost(paidAt, 4, HireUpfront),
+		filledPost(paidAt, 10, HireUpfront),
+		filledPost(paidAt, 1, ""),
+		filledPost(paidAt, 21, HireReferral),
+	}
+	filled[0].ClickedApplyCount = 3
+	posts := append([]JobPostItem{active, {Status: Expired}}, filled...)
+
+	stats := Stats(posts)
+	if stats.Posts != 6 || stats.Filled != 4 || stats.ApplyClicks != 10 {
+		t.Errorf("Stats() posts = %d, filled = %d, applyClicks = %d, want 6, 4, 10", stats.Posts, stats.Filled, stats.ApplyClicks)
+	}
+	if want := map[Status]int{Active: 1, Expired: 1, Filled: 4}; !reflect.DeepEqual(stats.ByStatus, want) {
+		t.Errorf("Stats() byStatus = %v, want %v", stats.ByStatus, want)
+	}
+	if want := map[HireChannel]int{HireUpfront: 2, HireReferral: 1}; !reflect.DeepEqual(stats.ByHireChannel, want) {
+		t.Errorf("Stats() byHireChannel = %v, want %v", stats.ByHireChannel, want)
+	}
+	// 1, 4, 10 and 21 days.
+	if stats.AverageDaysToFill == nil || *stats.AverageDaysToFill != 9 {
+		t.Errorf("Stats() averageDaysToFill = %v, want 9", stats.AverageDaysToFill)
+	}
+	if stats.MedianDaysToFill == nil || *stats.MedianDaysToFill != 7 {
+		t.Errorf("Stats() medianDaysToFill = %v, want 7", stats.MedianDaysToFill)
+	}
+
+	odd := Stats(filled[:3])
+	if odd.MedianDaysToFill == nil || *odd.MedianDaysToFill != 4 {
+		t.Errorf("Stats() of three posts medianDaysToFill = %v, want 4", odd.MedianDaysToFill)
+	}
+
+	none := Stats([]JobPostItem{active})
+	if none.AverageDaysToFill != nil || none.MedianDaysToFill != nil {
+		t.Errorf("Stats() with nothing filled = %v, %v, want no time to fill", none.AverageDaysToFill, none.MedianDaysToFill)
+	}
+}
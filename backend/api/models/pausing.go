@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAlreadyPaused is returned when pausing a paused post.
+	ErrAlreadyPaused = errors.New("job post is already paused")
+	// ErrNotPaused is returned when resuming a post that isn't paused.
+	ErrNotPaused = errors.New("job post is not paused")
+)
+
+// maxPause is the total time a post of each plan can spend paused over its life.
+var maxPause = map[PlanType]time.Duration{
+	Standard: 14 * 24 * time.Hour,
+	Premium:  30 * 24 * time.Hour,
+}
+
+// MaxPause returns the total time a post on plan can spend paused.
+func MaxPause(plan PlanType) time.Duration {
+	return maxPause[plan]
+}
+
+// PauseLeft returns how much more paused time the post can have credited back.
+func (item JobPostItem) PauseLeft() time.Duration {
+	return max(MaxPause(item.PlanType)-time.Duration(item.PausedSeconds)*time.Second, 0)
+}
+
+// pauseCredit returns how much of the current pause would be credited back if the post resumed
+// at now, which is the time paused so far up to the pause time left.
+func (item JobPostItem) pauseCredit(now time.Time) time.Duration {
+	pausedAt, err := time.Parse(time.RFC3339, item.PausedAt)
+	if err != nil || !now.After(pausedAt) {
+		return 0
+	}
+	return min(now.Sub(pausedAt), item.PauseLeft())
+}
+
+// Pause takes a live post off the listing at the recruiter's request, its paid time stops until it
+// is resumed.
+func (item *JobPostItem) Pause(now time.Time) error {
+	if item.Status == Paused {
+		return ErrAlreadyPaused
+	}
+	if err := item.Transition(Paused, now); err != nil {
+		return err
+	}
+	item.PausedAt = now.Format(time.RFC3339)
+	return nil
+}
+
+// Resume puts a paused post back on the listing and adds the time it was paused to ExpiresAt, up
+// to the pause time its plan has left. A post paused for longer has lost the rest, and can't be
+// resumed once that leaves it no paid time, it is expired by ExpirePaused instead.
+func (item *JobPostItem) Resume(now time.Time) error {
+	if item.Status != Paused {
+		return ErrNotPaused
+	}
+	credit := item.pauseCredit(now)
+	if err := item.Transition(Active, now); err != nil {
+		return err
+	}
+	item.endPause(credit)
+	return nil
+}
+
+// ExpirePaused ends a paused post whose paid time has run out even with the time it was paused
+// credited back, so it can be renewed.
+func (item *JobPostItem) ExpirePaused(now time.Time) error {
+	if item.Status != Paused {
+		return ErrNotPaused
+	}
+	credit := item.pauseCredit(now)
+	if err := item.Transition(Expired, now); err != nil {
+		return err
+	}
+	item.endPause(credit)
+	return nil
+}
+
+// endPause adds credit, worked out before the post left Paused, to ExpiresAt and clears PausedAt.
+func (item *JobPostItem) endPause(credit time.Duration) {
+	if expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt); err == nil {
+		item.ExpiresAt = expiresAt.Add(credit).Format(time.RFC3339)
+	}
+	item.PausedSeconds += int64(credit / time.Second)
+	item.PausedAt = ""
+}
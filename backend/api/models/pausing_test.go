@@ -0,0 +1,156 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// livePost is a live post on plan with 20 days of paid time left at now.
+func livePost(now time.Time, plan PlanType) JobPostItem {
+	item := JobPostItem{Status: Active, ExpiresAt: now.AddDate(0, 0, 20).Format(time.RFC3339)}
+	item.PlanType = plan
+	return item
+}
+
+func TestPauseLeft(t *testing.T) {
+	tests := []struct {
+		name   string
+		plan   PlanType
+		paused time.Duration
+		want   time.Duration
+	}{
+		{name: "standard unused", plan: Standard, want: 14 * 24 * time.Hour},
+		{name: "premium unused", plan: Premium, want: 30 * 24 * time.Hour},
+		{name: "part used", plan: Standard, paused: 4 * 24 * time.Hour, want: 10 * 24 * time.Hour},
+		{name: "all used", plan: Standard, paused: 14 * 24 * time.Hour, want: 0},
+		{name: "never negative", plan: Standard, paused: 20 * 24 * time.Hour, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := JobPostItem{PausedSeconds: int64(tt.paused / time.Second)}
+			item.PlanType = tt.plan
+			if got := item.PauseLeft(); got != tt.want {
+				t.Errorf("PauseLeft() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		plan       PlanType
+		used       time.Duration
+		pausedFor  time.Duration
+		wantCredit time.Duration
+	}{
+		{name: "credited in full", plan: Standard, pausedFor: 3 * 24 * time.Hour, wantCredit: 3 * 24 * time.Hour},
+		{name: "credited up to the allowance", plan: Standard, pausedFor: 20 * 24 * time.Hour, wantCredit: 14 * 24 * time.Hour},
+		{name: "credited up to what is left", plan: Standard, used: 12 * 24 * time.Hour, pausedFor: 5 * 24 * time.Hour, wantCredit: 2 * 24 * time.Hour},
+		{name: "premium allowance", plan: Premium, pausedFor: 20 * 24 * time.Hour, wantCredit: 20 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := livePost(now, tt.plan)
+			item.PausedSeconds = int64(tt.used / time.Second)
+			if err := item.Pause(now); err != nil {
+				t.Fatalf("Pause() = %v", err)
+			}
+			if item.Status != Paused || item.PausedAt != now.Format(time.RFC3339) {
+				t.Errorf("after Pause() status = %s, pausedAt = %q", item.Status, item.PausedAt)
+			}
+			if err := item.Resume(now.Add(tt.pausedFor)); err != nil {
+				t.Fatalf("Resume() = %v", err)
+			}
+			wantExpiresAt := now.AddDate(0, 0, 20).Add(tt.wantCredit).Format(time.RFC3339)
+			if item.Status != Active || item.ExpiresAt != wantExpiresAt || item.PausedAt != "" {
+				t.Errorf("after Resume() status = %s, expiresAt = %s, pausedAt = %q, want Active, %s", item.Status, item.ExpiresAt, item.PausedAt, wantExpiresAt)
+			}
+			if want := int64((tt.used + tt.wantCredit) / time.Second); item.PausedSeconds != want {
+				t.Errorf("pausedSeconds = %d, want %d", item.PausedSeconds, want)
+			}
+		})
+	}
+}
+
+func TestPauseErrors(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	item := livePost(now, Standard)
+	if err := item.Resume(now); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("Resume() of a live post = %v, want ErrNotPaused", err)
+	}
+	if err := item.Pause(now); err != nil {
+		t.Fatalf("Pause() = %v", err)
+	}
+	if err := item.Pause(now); !errors.Is(err, ErrAlreadyPaused) {
+		t.Errorf("Pause() of a paused post = %v, want ErrAlreadyPaused", err)
+	}
+
+	used := livePost(now, Standard)
+	used.PausedSeconds = int64(MaxPause(Standard) / time.Second)
+	if err := used.Pause(now); !errors.Is(err, ErrPauseLimit) {
+		t.Errorf("Pause() with no pause time left = %v, want ErrPauseLimit", err)
+	}
+
+	expired := livePost(now, Standard)
+	expired.ExpiresAt = now.Add(-time.Hour).Format(time.RFC3339)
+	if err := expired.Pause(now); !errors.Is(err, ErrNoTimeLeft) {
+		t.Errorf("Pause() with no paid time left = %v, want ErrNoTimeLeft", err)
+	}
+
+	review := JobPostItem{Status: PendingReview}
+	if err := review.Pause(now); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Pause() of a post in review = %v, want ErrInvalidTransition", err)
+	}
+}
+
+func TestResumeWithoutTimeLeft(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := livePost(now, Standard)
+	if err := item.Pause(now); err != nil {
+		t.Fatalf("Pause() = %v", err)
+	}
+	// 20 days were left and 14 days of pause can be credited, so after 40 days the time has run out.
+	later := now.AddDate(0, 0, 40)
+	paused := item
+	if err := item.Resume(later); !errors.Is(err, ErrNoTimeLeft) {
+		t.Fatalf("Resume() after the paid time ran out = %v, want ErrNoTimeLeft", err)
+	}
+	if item.Status != Paused || item.ExpiresAt != paused.ExpiresAt || item.PausedAt != paused.PausedAt {
+		t.Errorf("a failed Resume() changed the post to status %s, expiresAt %s, pausedAt %q", item.Status, item.ExpiresAt, item.PausedAt)
+	}
+
+	if err := item.ExpirePaused(now.AddDate(0, 0, 30)); !errors.Is(err, ErrNotLapsed) {
+		t.Errorf("ExpirePaused() with time left once credited = %v, want ErrNotLapsed", err)
+	}
+	if err := item.ExpirePaused(later); err != nil {
+		t.Fatalf("ExpirePaused() = %v", err)
+	}
+	wantExpiresAt := now.AddDate(0, 0, 34).Format(time.RFC3339)
+	if item.Status != Expired || item.ExpiresAt != wantExpiresAt || item.PausedAt != "" {
+		t.Errorf("after ExpirePaused() status = %s, expiresAt = %s, pausedAt = %q, want Expired, %s", item.Status, item.ExpiresAt, item.PausedAt, wantExpiresAt)
+	}
+}
+
+func TestHoldForReviewPaused(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := livePost(now, Standard)
+	if err := item.Pause(now); err != nil {
+		t.Fatalf("Pause() = %v", err)
+	}
+	// An edit held for review ends the pause, crediting it like a resume.
+	held := now.Add(2 * 24 * time.Hour)
+	if err := item.HoldForReview(held); err != nil {
+		t.Fatalf("HoldForReview() = %v", err)
+	}
+	wantExpiresAt := now.AddDate(0, 0, 22).Format(time.RFC3339)
+	if item.Status != PendingReview || item.PausedAt != "" || item.ExpiresAt != wantExpiresAt {
+		t.Errorf("after HoldForReview() status = %s, pausedAt = %q, expiresAt = %s, want PendingReview, none, %s", item.Status, item.PausedAt, item.ExpiresAt, wantExpiresAt)
+	}
+	if item.PausedSeconds != int64(2*24*time.Hour/time.Second) || item.ReviewRequestedAt != held.Format(time.RFC3339) {
+		t.Errorf("pausedSeconds = %d, reviewRequestedAt = %q", item.PausedSeconds, item.ReviewRequestedAt)
+	}
+}
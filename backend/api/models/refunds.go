@@ -0,0 +1,186 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type RefundStatus string
+
+const (
+	// RefundPending is recorded before the refund is sent to Stripe, so a failed attempt is retried
+	// with the same amount.
+	RefundPending RefundStatus = "Pending"
+	RefundIssued  RefundStatus = "Issued"
+)
+
+// Refund is an entry in a job post's refund history, kept for accounting.
+type Refund struct {
+	SessionID   string       `dynamodbav:"sessionID" json:"sessionID"`
+	RefundID    string       `dynamodbav:"refundID,omitempty" json:"refundID,omitempty"`
+	Amount      int64        `dynamodbav:"amount" json:"amount"`
+	Currency    Currency     `dynamodbav:"currency" json:"currency"`
+	Status      RefundStatus `dynamodbav:"status" json:"status"`
+	Reason      string       `dynamodbav:"reason,omitempty" json:"reason,omitempty"`
+	RequestedBy string       `dynamodbav:"requestedBy" json:"requestedBy"`
+	CreatedAt   string       `dynamodbav:"createdAt" json:"createdAt"`
+	IssuedAt    string       `dynamodbav:"issuedAt,omitempty" json:"issuedAt,omitempty"`
+}
+
+// LatePaymentReason is the reason given for refunding a purchase that was paid after its post could
+// no longer use it, see ApplyPurchase.
+const LatePaymentReason = "paid after the job post could no longer use it"
+
+// RefundWindow is how long after paying a recruiter can cancel for a full refund.
+const RefundWindow = 24 * time.Hour
+
+var (
+	// ErrAlreadyCancelled is returned when cancelling a post that was already cancelled.
+	ErrAlreadyCancelled = errors.New("job post is already cancelled")
+	// ErrAlreadyRefunded is returned when refunding a purchase that has a refund already.
+	ErrAlreadyRefunded = errors.New("purchase has already been refunded")
+	// ErrRefundTooLarge is returned when a refund is more than was paid.
+	ErrRefundTooLarge = errors.New("refund is more than the purchase amount")
+	// ErrRemoved is returned when cancelling a post a moderator rejected, it was refunded then.
+	ErrRemoved = errors.New("job post was removed by a moderator")
+)
+
+// Cancel takes the post off the listing and records the refunds due for it as pending, see
+// RefundsDue. The caller issues them and marks each one with RefundIssued.
+func (item *JobPostItem) Cancel(now time.Time, by, reason string, full bool) error {
+	if item.Status == Cancelled {
+		return ErrAlreadyCancelled
+	}
+	if item.Status == Removed {
+		return ErrRemoved
+	}
+	// Refunds are worked out while the post still has its status, see RefundsDue.
+	refunds := item.RefundsDue(now, full)
+	if err := item.Transition(Cancelled, now); err != nil {
+		return err
+	}
+	for _, r := range refunds {
+		r.Reason = reason
+		r.RequestedBy = by
+		item.Refunds = append(item.Refunds, r)
+	}
+	item.BillingHistory = item.Purchases()
+	item.CancelledAt = now.Format(time.RFC3339)
+	item.CancelledBy = by
+	item.UpdatedAt = now.Format(time.RFC3339)
+	return nil
+}
+
+// PendingRefunds returns the indexes of refunds that haven't been issued yet.
+func (item JobPostItem) PendingRefunds() []int {
+	var pending []int
+	for i, r := range item.Refunds {
+		if r.Status == RefundPending {
+			pending = append(pending, i)
+		}
+	}
+	return pending
+}
+
+// RefundsDue returns the refund owed for each paid purchase if the post were cancelled at now.
+// Purchases paid within RefundWindow, or every purchase when full is set, are refunded in full.
+// Otherwise only unused time is refunded. Renewals add time to the end of a post, so the unused
+// time is taken from the newest purchases first, and an upgrade is refunded for the share of the
+// days it covered that are left.
+func (item JobPostItem) RefundsDue(now time.Time, full bool) []Refund {
+	refunded := map[string]bool{}
+	for _, r := range item.Refunds {
+		refunded[r.SessionID] = true
+	}
+
+	// The clock stops while a post is in review, so its remaining time is counted from then. A
+	// paused post's clock stops for as long as its pause time lasts, see Resume.
+	from := now
+	if item.Status == PendingReview {
+		if reviewRequestedAt, err := time.Parse(time.RFC3339, item.ReviewRequestedAt); err == nil {
+			from = reviewRequestedAt
+		}
+	}
+	if item.Status == Paused {
+		from = now.Add(-item.pauseCredit(now))
+	}
+	var remaining int64
+	if expiresAt, err := time.Parse(time.RFC3339, item.ExpiresAt); err == nil && (item.Status == Active || item.Status == PendingReview || item.Status == Paused) && expiresAt.After(from) {
+		remaining = int64(expiresAt.Sub(from) / time.Second)
+	}
+	totalRemaining := remaining
+
+	purchases := item.Purchases()
+	var refunds []Refund
+	for i := len(purchases) - 1; i >= 0; i-- {
+		p := purchases[i]
+		if p.Status != PurchasePaid || p.Amount <= 0 {
+			continue
+		}
+		covered := int64(p.PlanDuration) * int64(24*time.Hour/time.Second)
+		var unused int64
+		if p.Kind == Upgrade {
+			unused = min(totalRemaining, covered)
+		} else {
+			unused = min(remaining, covered)
+			remaining -= unused
+		}
+		if refunded[p.SessionID] {
+			continue
+		}
+
+		amount := p.Amount
+		paidAt, err := time.Parse(time.RFC3339, p.PaidAt)
+		inWindow := err == nil && now.Sub(paidAt) < RefundWindow
+		if !full && !inWindow && covered > 0 {
+			amount = p.Amount * unused / covered
+		}
+		if amount <= 0 {
+			continue
+		}
+		refunds = append(refunds, Refund{
+			SessionID: p.SessionID,
+			Amount:    amount,
+			Currency:  p.Currency,
+			Status:    RefundPending,
+			CreatedAt: now.Format(time.RFC3339),
+		})
+	}
+	return refunds
+}
+
+// Refund records a pending refund of amount for the paid purchase made with sessionID, or of the
+// whole purchase when amount is 0. Unlike Cancel the post stays as it is. It returns the index of
+// the refund in Refunds.
+func (item *JobPostItem) Refund(now time.Time, sessionID string, amount int64, by, reason string) (int, error) {
+	for _, r := range item.Refunds {
+		if r.SessionID == sessionID {
+			return 0, ErrAlreadyRefunded
+		}
+	}
+	item.BillingHistory = item.Purchases()
+	for _, p := range item.BillingHistory {
+		if p.SessionID != sessionID || p.Status != PurchasePaid {
+			continue
+		}
+		if amount == 0 {
+			amount = p.Amount
+		}
+		if amount < 0 || amount > p.Amount {
+			return 0, ErrRefundTooLarge
+		}
+		item.Refunds = append(item.Refunds, Refund{
+			SessionID:   sessionID,
+			Amount:      amount,
+			Currency:    p.Currency,
+			Status:      RefundPending,
+			Reason:      reason,
+			RequestedBy: by,
+			CreatedAt:   now.Format(time.RFC3339),
+		})
+		item.UpdatedAt = now.Format(time.RFC3339)
+		return len(item.Refunds) - 1, nil
+	}
+	return 0, fmt.Errorf("no paid purchase with session %s", sessionID)
+}
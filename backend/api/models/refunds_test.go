@@ -0,0 +1,166 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefundsDue(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := func(n int) string { return now.AddDate(0, 0, n).Format(time.RFC3339) }
+	paid := func(session string, kind PurchaseKind, plan PlanType, days int, amount int64, paidAt string) Purchase {
+		return Purchase{SessionID: session, Kind: kind, PlanType: plan, PlanDuration: days, Amount: amount, Currency: GBP, Status: PurchasePaid, PaidAt: paidAt}
+	}
+	post := func(status Status, expiresAt string, purchases ...Purchase) JobPostItem {
+		item := JobPostItem{Status: status, ExpiresAt: expiresAt, BillingHistory: purchases}
+		item.PlanType = Standard
+		return item
+	}
+
+	paused := post(Paused, day(20), paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-10)))
+	paused.PausedAt = day(-5)
+	pausedTooLong := paused
+	pausedTooLong.PausedSeconds = int64(12 * 24 * time.Hour / time.Second)
+	inReview := post(PendingReview, day(20), paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-10)))
+	inReview.ReviewRequestedAt = day(-4)
+	refunded := post(Active, day(40),
+		paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-20)),
+		paid("cs_2", Renewal, Standard, 30, 3500, day(-5)),
+	)
+	refunded.Refunds = []Refund{{SessionID: "cs_2", Amount: 3500, Status: RefundIssued}}
+
+	tests := []struct {
+		name string
+		item JobPostItem
+		full bool
+		want map[string]int64
+	}{
+		{
+			name: "unused time",
+			item: post(Active, day(20), paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-10))),
+			want: map[string]int64{"cs_1": 2333},
+		},
+		{
+			name: "within the refund window",
+			item: post(Active, day(30), paid("cs_1", InitialPurchase, Standard, 30, 3500, now.Add(-23*time.Hour).Format(time.RFC3339))),
+			want: map[string]int64{"cs_1": 3500},
+		},
+		{
+			name: "just outside the refund window",
+			item: post(Active, day(29), paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-1))),
+			want: map[string]int64{"cs_1": 3383},
+		},
+		{
+			name: "full refund",
+			item: post(Active, day(20), paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-10))),
+			full: true,
+			want: map[string]int64{"cs_1": 3500},
+		},
+		{
+			name: "renewal is used last",
+			item: post(Active, day(20),
+				paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-40)),
+				paid("cs_2", Renewal, Standard, 30, 3500, day(-5)),
+			),
+			want: map[string]int64{"cs_2": 2333},
+		},
+		{
+			name: "unused time spans a renewal",
+			item: post(Active, day(40),
+				paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-20)),
+				paid("cs_2", Renewal, Standard, 30, 3500, day(-5)),
+			),
+			want: map[string]int64{"cs_1": 1166, "cs_2": 3500},
+		},
+		{
+			name: "refunded renewal still uses its time",
+			item: refunded,
+			want: map[string]int64{"cs_1": 1166},
+		},
+		{
+			name: "upgrade is refunded for the share of its days left",
+			item: post(Active, day(10),
+				paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-20)),
+				paid("cs_2", Upgrade, Premium, 15, 2750, day(-5)),
+			),
+			want: map[string]int64{"cs_1": 1166, "cs_2": 1833},
+		},
+		{
+			name: "clock stops in review",
+			item: inReview,
+			want: map[string]int64{"cs_1": 2800},
+		},
+		{
+			name: "paused time is credited",
+			item: paused,
+			want: map[string]int64{"cs_1": 2916},
+		},
+		{
+			name: "paused time is credited up to the allowance",
+			item: pausedTooLong,
+			want: map[string]int64{"cs_1": 2566},
+		},
+		{
+			name: "expired post has nothing left",
+			item: post(Expired, day(-1), paid("cs_1", InitialPurchase, Standard, 30, 3500, day(-31))),
+			want: map[string]int64{},
+		},
+		{
+			name: "free and unpaid purchases",
+			item: post(Active, day(20),
+				paid("cs_1", InitialPurchase, Standard, 30, 0, day(-10)),
+				Purchase{SessionID: "cs_2", Kind: Renewal, PlanType: Standard, PlanDuration: 30, Amount: 3500, Currency: GBP, Status: PurchasePending},
+			),
+			want: map[string]int64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refunds := tt.item.RefundsDue(now, tt.full)
+			got := map[string]int64{}
+			for _, r := range refunds {
+				if r.Status != RefundPending || r.Currency != GBP || r.CreatedAt != now.Format(time.RFC3339) {
+					t.Errorf("refund %+v is not a pending GBP refund created now", r)
+				}
+				got[r.SessionID] = r.Amount
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("RefundsDue() = %v, want %v", got, tt.want)
+			}
+			for session, amount := range tt.want {
+				if got[session] != amount {
+					t.Errorf("RefundsDue() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := JobPostItem{
+		Status:    Active,
+		ExpiresAt: now.AddDate(0, 0, 20).Format(time.RFC3339),
+		BillingHistory: []Purchase{
+			{SessionID: "cs_1", Kind: InitialPurchase, PlanType: Standard, PlanDuration: 30, Amount: 3500, Currency: GBP, Status: PurchasePaid, PaidAt: now.AddDate(0, 0, -10).Format(time.RFC3339)},
+		},
+	}
+	if err := item.Cancel(now, "owner@example.com", "filled elsewhere", false); err != nil {
+		t.Fatalf("Cancel() = %v", err)
+	}
+	if item.Status != Cancelled || item.CancelledBy != "owner@example.com" {
+		t.Errorf("after Cancel status = %s, cancelledBy = %s", item.Status, item.CancelledBy)
+	}
+	if len(item.Refunds) != 1 || item.Refunds[0].Amount != 2333 || item.Refunds[0].Reason != "filled elsewhere" || item.Refunds[0].RequestedBy != "owner@example.com" {
+		t.Errorf("refunds = %+v, want one prorated refund", item.Refunds)
+	}
+	if err := item.Cancel(now, "owner@example.com", "", false); err != ErrAlreadyCancelled {
+		t.Errorf("second Cancel() = %v, want %v", err, ErrAlreadyCancelled)
+	}
+
+	removed := JobPostItem{Status: Removed}
+	if err := removed.Cancel(now, "owner@example.com", "", false); err != ErrRemoved {
+		t.Errorf("Cancel() on a removed post = %v, want %v", err, ErrRemoved)
+	}
+}
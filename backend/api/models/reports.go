@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ReportReason string
+
+const (
+	ReportScam           ReportReason = "Scam"
+	ReportMisleading     ReportReason = "Misleading"
+	ReportDiscriminatory ReportReason = "Discriminatory"
+	ReportSpam           ReportReason = "Spam"
+	// ReportUnavailable is a role that has already been filled or withdrawn.
+	ReportUnavailable ReportReason = "Unavailable"
+	ReportOther       ReportReason = "Other"
+)
+
+var ReportReasons = []ReportReason{ReportScam, ReportMisleading, ReportDiscriminatory, ReportSpam, ReportUnavailable, ReportOther}
+
+type ReportStatus string
+
+const (
+	ReportOpen     ReportStatus = "Open"
+	ReportResolved ReportStatus = "Resolved"
+)
+
+type ReportOutcome string
+
+const (
+	// ReportDismissed reports were unfounded.
+	ReportDismissed ReportOutcome = "Dismissed"
+	// ReportUpheld reports were acted on, for example by rejecting the post.
+	ReportUpheld ReportOutcome = "Upheld"
+)
+
+// MaxReportDetailsLength limits the free text a visitor can send with a report.
+const MaxReportDetailsLength = 2000
+
+// Report is a visitor flagging a job post, stored under the post's partition. Each reporter can
+// report a post once, so the number of reports is the number of distinct reporters.
+type Report struct {
+	PK    string `dynamodbav:"PK" json:"-"`
+	SK    string `dynamodbav:"SK" json:"-"`
+	JobID string `dynamodbav:"jobID" json:"jobID"`
+	// ReporterID is a hash of the reporter's IP address, the address itself isn't kept.
+	ReporterID string        `dynamodbav:"reporterID" json:"reporterID"`
+	Reason     ReportReason  `dynamodbav:"reason" json:"reason"`
+	Details    string        `dynamodbav:"details,omitempty" json:"details,omitempty"`
+	Status     ReportStatus  `dynamodbav:"status" json:"status"`
+	Outcome    ReportOutcome `dynamodbav:"outcome,omitempty" json:"outcome,omitempty"`
+	Note       string        `dynamodbav:"note,omitempty" json:"note,omitempty"`
+	ResolvedBy string        `dynamodbav:"resolvedBy,omitempty" json:"resolvedBy,omitempty"`
+	ResolvedAt string        `dynamodbav:"resolvedAt,omitempty" json:"resolvedAt,omitempty"`
+	CreatedAt  string        `dynamodbav:"createdAt" json:"createdAt"`
+}
+
+// ValidateReport returns the issues with a report's reason and details, if any.
+func ValidateReport(reason ReportReason, details string) []string {
+	var issues []string
+	valid := false
+	for _, r := range ReportReasons {
+		valid = valid || r == reason
+	}
+	if !valid {
+		issues = append(issues, fmt.Sprintf("reason must be one of %v", ReportReasons))
+	}
+	if reason == ReportOther && strings.TrimSpace(details) == "" {
+		issues = append(issues, fmt.Sprintf("details are required when the reason is %q", ReportOther))
+	}
+	if len(details) > MaxReportDetailsLength {
+		issues = append(issues, fmt.Sprintf("details must be at most %d characters", MaxReportDetailsLength))
+	}
+	return issues
+}
+
+// ReportSKPrefix starts the sort key of every report under a job post.
+const ReportSKPrefix = "report/"
+
+func FormatReportSK(reporterID string) string {
+	return ReportSKPrefix + reporterID
+}
@@ -0,0 +1,24 @@
+package models
+
+// ContentScore is how likely a post is to be spam or a scam, higher is worse.
+type ContentScore struct {
+	Score int `dynamodbav:"score" json:"score"`
+	// Findings explain the score, one for each rule that matched.
+	Findings []Finding `dynamodbav:"findings,omitempty" json:"findings,omitempty"`
+	// NeedsReview is set when the score reached the review threshold, the post is then held for
+	// review even when moderation is off.
+	NeedsReview bool   `dynamodbav:"needsReview" json:"needsReview"`
+	ScoredAt    string `dynamodbav:"scoredAt" json:"scoredAt"`
+}
+
+// Finding is a rule that matched a post and what it added to the post's score.
+type Finding struct {
+	Rule   string `dynamodbav:"rule" json:"rule"`
+	Detail string `dynamodbav:"detail" json:"detail"`
+	Weight int    `dynamodbav:"weight" json:"weight"`
+}
+
+// Flagged reports whether the post's content score routes it to review.
+func (item JobPostItem) Flagged() bool {
+	return item.ContentScore != nil && item.ContentScore.NeedsReview
+}
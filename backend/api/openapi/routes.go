@@ -0,0 +1,827 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/josepheid/upfront/api/handlers/canceljobpost"
+	"github.com/josepheid/upfront/api/handlers/createcheckoutsession"
+	"github.com/josepheid/upfront/api/handlers/createreport"
+	"github.com/josepheid/upfront/api/handlers/filljobpost"
+	"github.com/josepheid/upfront/api/handlers/getanalytics"
+	"github.com/josepheid/upfront/api/handlers/getcompany"
+	"github.com/josepheid/upfront/api/handlers/getjobpost"
+	"github.com/josepheid/upfront/api/handlers/getreports"
+	"github.com/josepheid/upfront/api/handlers/getreviewqueue"
+	"github.com/josepheid/upfront/api/handlers/invitecompanymember"
+	"github.com/josepheid/upfront/api/handlers/renewjobpost"
+	"github.com/josepheid/upfront/api/handlers/resolvereports"
+	"github.com/josepheid/upfront/api/handlers/reviewjobpost"
+	"github.com/josepheid/upfront/api/handlers/startchallenge"
+	"github.com/josepheid/upfront/api/handlers/transferjobpost"
+	"github.com/josepheid/upfront/api/handlers/updatecompanymember"
+	"github.com/josepheid/upfront/api/handlers/upgradejobpost"
+	"github.com/josepheid/upfront/api/handlers/uploadcompanylogo"
+	"github.com/josepheid/upfront/api/handlers/validatepurchase"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/idempotency"
+	"github.com/josepheid/upfront/internal/invoices"
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+// Route describes an API Gateway route. Every method added to the upfront resource in cdk.go must
+// have a matching entry in Routes.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tags        []string
+	// Authenticated routes need a recruiter's Cognito ID token as well as the API key.
+	Authenticated bool
+	Query         []Param
+	Headers       []Param
+	// Request is a zero value of the JSON request body type, nil if the route takes no body.
+	Request any
+	// Responses maps status codes to a zero value of the JSON response body type.
+	Responses map[int]any
+}
+
+// File is used in Responses for routes that return a file rather than JSON.
+type File struct {
+	ContentType string
+}
+
+type Param struct {
+	Name     string
+	Required bool
+	// Type is a zero value of the parameter type.
+	Type any
+}
+
+// Routes is the upfront API.
+var Routes = []Route{
+	{
+		Method:      http.MethodPost,
+		Path:        "/upfront/checkout-session",
+		OperationID: "createCheckoutSession",
+		Summary:     "Create a pending job post and a Stripe checkout session to pay for it from the form. The post gets a new company of its own once paid for, posting for an existing company or checking out a draft needs the recruiter route.",
+		Tags:        []string{"payments"},
+		Headers: []Param{
+			{Name: idempotency.Header, Type: ""},
+		},
+		Request: createcheckoutsession.CheckoutSessionRequest{},
+		Responses: map[int]any{
+			http.StatusCreated:             createcheckoutsession.CheckoutSessionResponse{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusUnprocessableEntity: respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/recruiter/checkout-session",
+		OperationID:   "createRecruiterCheckoutSession",
+		Summary:       "Check out as the signed in recruiter, from the form or one of their drafts' draftId. Only this route can post for a company with companyID, and a post paid for here joins the recruiter's company of the same name.",
+		Tags:          []string{"payments"},
+		Authenticated: true,
+		Headers: []Param{
+			{Name: idempotency.Header, Type: ""},
+		},
+		Request: createcheckoutsession.CheckoutSessionRequest{},
+		Responses: map[int]any{
+			http.StatusCreated:             createcheckoutsession.CheckoutSessionResponse{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusUnprocessableEntity: respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/upfront/validate-purchase/{id}",
+		OperationID: "validatePurchase",
+		Summary:     "Activate a job post once its checkout session has been paid, returning its listing and status.",
+		Tags:        []string{"payments"},
+		Responses: map[int]any{
+			http.StatusOK:                  validatepurchase.ValidatePurchaseResponse{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusPaymentRequired:     respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/upfront/job-posts",
+		OperationID: "getJobPosts",
+		Summary:     "List active job posts, featured Premium posts first, optionally filtered by minimum salary, location and title.",
+		Tags:        []string{"job posts"},
+		Query: []Param{
+			{Name: "salary", Type: 0},
+			{Name: "location", Type: ""},
+			{Name: "title", Type: ""},
+		},
+		Responses: map[int]any{
+			http.StatusOK:                  []models.JobPostListing{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/upfront/job-posts/{id}",
+		OperationID: "getJobPost",
+		Summary:     "Get an active job post, or one filled in the last 14 days with positionFilled set.",
+		Tags:        []string{"job posts"},
+		Responses: map[int]any{
+			http.StatusOK:                  getjobpost.JobPostDetail{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/upfront/drafts",
+		OperationID: "createDraft",
+		Summary:     "Save an unfinished job post form as a draft owned by its loginEmail. The owner signs in to change, preview or check it out, once a paid post or a company invitation lets them.",
+		Tags:        []string{"drafts"},
+		Request:     models.JobPostFormProps{},
+		Responses: map[int]any{
+			http.StatusCreated:             models.DraftForm{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnprocessableEntity: respond.Error{},
+			http.StatusTooManyRequests:     respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/recruiter/drafts",
+		OperationID:   "createRecruiterDraft",
+		Summary:       "Save an unfinished job post form as a draft owned by the signed in recruiter, loginEmail is ignored. Unlike drafts saved without signing in, it lets the recruiter sign in again to reach it.",
+		Tags:          []string{"drafts"},
+		Authenticated: true,
+		Request:       models.JobPostFormProps{},
+		Responses: map[int]any{
+			http.StatusCreated:             models.DraftForm{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusUnprocessableEntity: respond.Error{},
+			http.StatusTooManyRequests:     respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/drafts/{id}",
+		OperationID:   "getDraft",
+		Summary:       "Get a draft as it was saved.",
+		Tags:          []string{"drafts"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  models.DraftForm{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPut,
+		Path:          "/upfront/drafts/{id}",
+		OperationID:   "updateDraft",
+		Summary:       "Replace a draft's form. The draft stays tied to the email it was saved with.",
+		Tags:          []string{"drafts"},
+		Authenticated: true,
+		Request:       models.JobPostFormProps{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.DraftForm{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodDelete,
+		Path:          "/upfront/drafts/{id}",
+		OperationID:   "deleteDraft",
+		Summary:       "Delete a draft that hasn't been checked out.",
+		Tags:          []string{"drafts"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusNoContent:           nil,
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/drafts/{id}/preview",
+		OperationID:   "previewDraft",
+		Summary:       "Render a draft as it would be listed once paid for, with its price and anything that must be fixed before checkout.",
+		Tags:          []string{"drafts"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  models.DraftPreview{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPut,
+		Path:          "/upfront/job-posts/{id}",
+		OperationID:   "updateJobPost",
+		Summary:       "Edit a live, paused or pending review job post. The post is rescored and, when moderation is enabled or the score is high, reviewed again before it is listed. A paused post held for review is resumed once approved.",
+		Tags:          []string{"job posts", "moderation"},
+		Authenticated: true,
+		Request:       models.JobPostDetails{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/job-posts/{id}/renew",
+		OperationID:   "renewJobPost",
+		Summary:       "Create a Stripe checkout session to extend an active job post or reactivate an expired one.",
+		Tags:          []string{"payments"},
+		Authenticated: true,
+		Request:       renewjobpost.RenewJobPostRequest{},
+		Responses: map[int]any{
+			http.StatusCreated:             renewjobpost.RenewJobPostResponse{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/job-posts/{id}/upgrade",
+		OperationID:   "upgradeJobPost",
+		Summary:       "Create a Stripe checkout session for the prorated cost of moving an active job post to a higher plan.",
+		Tags:          []string{"payments"},
+		Authenticated: true,
+		Request:       upgradejobpost.UpgradeJobPostRequest{},
+		Responses: map[int]any{
+			http.StatusCreated:             upgradejobpost.UpgradeJobPostResponse{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/job-posts/{id}/cancel",
+		OperationID:   "cancelJobPost",
+		Summary:       "Cancel your job post, taking it off the listing and refunding the unused time, or everything within 24 hours of paying.",
+		Tags:          []string{"payments"},
+		Authenticated: true,
+		Request:       canceljobpost.CancelJobPostRequest{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/admin/job-posts/{id}/cancel",
+		OperationID:   "adminCancelJobPost",
+		Summary:       "Cancel any job post as an admin, optionally refunding every purchase in full.",
+		Tags:          []string{"payments", "admin"},
+		Authenticated: true,
+		Request:       canceljobpost.CancelJobPostRequest{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/admin/review-queue",
+		OperationID:   "getReviewQueue",
+		Summary:       "List the job posts waiting for a moderator, and those waiting for changes a moderator asked for.",
+		Tags:          []string{"moderation", "admin"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  getreviewqueue.ReviewQueueResponse{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/admin/duplicates",
+		OperationID:   "getDuplicates",
+		Summary:       "List live, expired and in review job posts that are nearly the same as another post from their company, newest first.",
+		Tags:          []string{"moderation", "admin"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  []models.JobPostItem{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/upfront/job-posts/{id}/reports",
+		OperationID: "createReport",
+		Summary:     "Report a live job post as a visitor. Each visitor can report a post once, and a post with enough open reports is taken down until an admin reviews it.",
+		Tags:        []string{"moderation"},
+		Request:     createreport.CreateReportRequest{},
+		Responses: map[int]any{
+			http.StatusCreated:             models.Report{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusTooManyRequests:     respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/admin/reports",
+		OperationID:   "getReports",
+		Summary:       "List job posts with open reports and their reports, most reported first.",
+		Tags:          []string{"moderation", "admin"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  []getreports.ReportedJobPost{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/admin/job-posts/{id}/reports/resolve",
+		OperationID:   "resolveReports",
+		Summary:       "Dismiss or uphold every open report of a job post. The post itself is changed by reviewing it.",
+		Tags:          []string{"moderation", "admin"},
+		Authenticated: true,
+		Request:       resolvereports.ResolveReportsRequest{},
+		Responses: map[int]any{
+			http.StatusOK:                  []models.Report{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/admin/job-posts/{id}/review",
+		OperationID:   "reviewJobPost",
+		Summary:       "Approve a job post pending review, reject it with a full refund, or ask its recruiter for changes. The recruiter is emailed the decision.",
+		Tags:          []string{"moderation", "admin"},
+		Authenticated: true,
+		Request:       reviewjobpost.ReviewJobPostRequest{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/job-posts/{id}/invoices/{sessionId}",
+		OperationID:   "getInvoice",
+		Summary:       "Download the PDF invoice for a paid purchase in a job post's billing history.",
+		Tags:          []string{"payments"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  File{ContentType: invoices.ContentType},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/upfront/companies/{slug}",
+		OperationID: "getCompany",
+		Summary:     "Get a company's profile and its active job posts.",
+		Tags:        []string{"companies"},
+		Responses: map[int]any{
+			http.StatusOK:                  getcompany.CompanyResponse{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPut,
+		Path:          "/upfront/companies/{slug}",
+		OperationID:   "updateCompany",
+		Summary:       "Update your company's profile, the details shown on its job posts are updated too.",
+		Tags:          []string{"companies"},
+		Authenticated: true,
+		Request:       models.CompanyProfile{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.Company{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/companies/{slug}/logo",
+		OperationID:   "uploadCompanyLogo",
+		Summary:       "Get a presigned URL to upload your company's logo, it is resized to square thumbnails and set as the logo once processed.",
+		Tags:          []string{"companies"},
+		Authenticated: true,
+		Request:       uploadcompanylogo.UploadCompanyLogoRequest{},
+		Responses: map[int]any{
+			http.StatusCreated:             uploadcompanylogo.UploadCompanyLogoResponse{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/companies/{slug}/members",
+		OperationID:   "getCompanyMembers",
+		Summary:       "List your company's members and outstanding invitations.",
+		Tags:          []string{"companies"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  []models.Member{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/companies/{slug}/invitations",
+		OperationID:   "inviteCompanyMember",
+		Summary:       "Email a recruiter an invitation to join your company with a role, inviting them again resends it.",
+		Tags:          []string{"companies"},
+		Authenticated: true,
+		Request:       invitecompanymember.InviteCompanyMemberRequest{},
+		Responses: map[int]any{
+			http.StatusCreated:             models.Member{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/companies/{slug}/invitations/accept",
+		OperationID:   "acceptInvitation",
+		Summary:       "Accept your invitation to join a company.",
+		Tags:          []string{"companies"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  models.Member{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPut,
+		Path:          "/upfront/companies/{slug}/members/{email}",
+		OperationID:   "updateCompanyMember",
+		Summary:       "Change a member's role, a company always keeps at least one owner.",
+		Tags:          []string{"companies"},
+		Authenticated: true,
+		Request:       updatecompanymember.UpdateCompanyMemberRequest{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.Member{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodDelete,
+		Path:          "/upfront/companies/{slug}/members/{email}",
+		OperationID:   "removeCompanyMember",
+		Summary:       "Remove a member or invitation, or leave a company yourself.",
+		Tags:          []string{"companies"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusNoContent:           nil,
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/job-posts/{id}/transfer",
+		OperationID:   "transferJobPost",
+		Summary:       "Make another editor or owner of the company the recruiter who owns a job post.",
+		Tags:          []string{"job posts", "companies"},
+		Authenticated: true,
+		Request:       transferjobpost.TransferJobPostRequest{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/job-posts/{id}/pause",
+		OperationID:   "pauseJobPost",
+		Summary:       "Take an active job post off the listing without losing its paid time, up to the total pause time its plan allows.",
+		Tags:          []string{"job posts"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/job-posts/{id}/resume",
+		OperationID:   "resumeJobPost",
+		Summary:       "Put a paused job post back on the listing, moving its expiry on by the time it was paused. A post whose paid time ran out anyway can't be resumed, it expires and can then be renewed.",
+		Tags:          []string{"job posts"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodPost,
+		Path:          "/upfront/job-posts/{id}/filled",
+		OperationID:   "fillJobPost",
+		Summary:       "Close an active, paused or expired job post because the role was filled, optionally saying where the hire came from. Open renewal and upgrade checkouts are expired so they can't be paid afterwards.",
+		Tags:          []string{"job posts"},
+		Authenticated: true,
+		Request:       filljobpost.FillJobPostRequest{},
+		Responses: map[int]any{
+			http.StatusOK:                  models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusConflict:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/analytics",
+		OperationID:   "getAnalytics",
+		Summary:       "Sum up the outcomes of the job posts of the companies you belong to, including how many were filled, where the hires came from and the time to fill.",
+		Tags:          []string{"job posts"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  getanalytics.AnalyticsResponse{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/admin/analytics",
+		OperationID:   "adminGetAnalytics",
+		Summary:       "Sum up the outcomes of every job post, including the time to fill, in total and by plan.",
+		Tags:          []string{"admin"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  getanalytics.AnalyticsResponse{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/job-posts/{id}/audit",
+		OperationID:   "getAuditLog",
+		Summary:       "List every change made to a job post you can view, oldest first.",
+		Tags:          []string{"job posts"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  []models.AuditEntry{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/admin/job-posts/{id}/audit",
+		OperationID:   "adminGetAuditLog",
+		Summary:       "List every change made to any job post, oldest first.",
+		Tags:          []string{"job posts", "admin"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  []models.AuditEntry{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusNotFound:            respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:        http.MethodGet,
+		Path:          "/upfront/recruiter-posts/{email}",
+		OperationID:   "getRecruiterJobPosts",
+		Summary:       "List the job posts owned by the signed in recruiter and every post of the companies they are a member of, admins can list any recruiter's.",
+		Tags:          []string{"job posts"},
+		Authenticated: true,
+		Responses: map[int]any{
+			http.StatusOK:                  []models.JobPostItem{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusUnauthorized:        respond.Error{},
+			http.StatusForbidden:           respond.Error{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:      http.MethodPost,
+		Path:        "/upfront/start-challenge",
+		OperationID: "startChallenge",
+		Summary:     "Email a magic login link to a recruiter with paid job posts, drafts saved signed in or a company invitation.",
+		Tags:        []string{"auth"},
+		Request:     startchallenge.StartChallengeRequest{},
+		Responses: map[int]any{
+			http.StatusCreated:             startchallenge.StartChallengeResponse{},
+			http.StatusBadRequest:          respond.Error{},
+			http.StatusNotFound:            startchallenge.StartChallengeResponse{},
+			http.StatusInternalServerError: respond.Error{},
+			http.StatusGatewayTimeout:      respond.Error{},
+		},
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/upfront/openapi.json",
+		OperationID: "getOpenAPI",
+		Summary:     "This document.",
+		Tags:        []string{"docs"},
+		Responses: map[int]any{
+			http.StatusOK: map[string]any{},
+		},
+	},
+}
+
+// enums lists the allowed values of the string types used in requests and responses.
+var enums = map[reflect.Type][]any{
+	reflect.TypeOf(models.Currency("")): {
+		models.GBP, models.USD, models.EUR, models.AUD, models.CAD, models.SGD, models.CHF, models.INR, models.JPY,
+	},
+	reflect.TypeOf(models.PlanType("")):       {models.Standard, models.Premium},
+	reflect.TypeOf(models.Status("")):         {models.Draft, models.PendingPayment, models.PendingReview, models.Active, models.Paused, models.Expired, models.Filled, models.Cancelled, models.Removed},
+	reflect.TypeOf(models.PurchaseKind("")):   {models.InitialPurchase, models.Renewal, models.Upgrade},
+	reflect.TypeOf(models.PurchaseStatus("")): {models.PurchasePending, models.PurchasePaid, models.PurchaseExpired},
+	reflect.TypeOf(models.RefundStatus("")):   {models.RefundPending, models.RefundIssued},
+	reflect.TypeOf(models.ReviewDecision("")): {models.Approved, models.Rejected, models.ChangesRequested},
+	reflect.TypeOf(models.Role("")):           {models.Owner, models.Editor, models.Viewer},
+	reflect.TypeOf(models.MemberStatus("")):   {models.Invited, models.ActiveMember},
+	reflect.TypeOf(models.ReportReason("")):   {models.ReportScam, models.ReportMisleading, models.ReportDiscriminatory, models.ReportSpam, models.ReportUnavailable, models.ReportOther},
+	reflect.TypeOf(models.ReportStatus("")):   {models.ReportOpen, models.ReportResolved},
+	reflect.TypeOf(models.ReportOutcome("")):  {models.ReportDismissed, models.ReportUpheld},
+	reflect.TypeOf(models.AuditAction("")): {
+		models.AuditCreated, models.AuditCheckoutStarted, models.AuditPurchasePaid, models.AuditPurchaseExpired, models.AuditEdited,
+		models.AuditHeldForReview, models.AuditReviewed, models.AuditCancelled, models.AuditRefunded, models.AuditExpired,
+		models.AuditExtended, models.AuditTransferred, models.AuditPaused, models.AuditResumed, models.AuditFilled,
+		models.AuditInvoiced,
+	},
+	reflect.TypeOf(models.HireChannel("")): {
+		models.HireUpfront, models.HireOtherJobBoard, models.HireReferral, models.HireAgency, models.HireInternal, models.HireOther,
+	},
+	reflect.TypeOf(respond.Code("")): codes(),
+}
+
+func codes() []any {
+	values := make([]any, len(respond.Codes))
+	for i, c := range respond.Codes {
+		values[i] = c
+	}
+	return values
+}
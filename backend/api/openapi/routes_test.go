@@ -0,0 +1,240 @@
+package openapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// cdkFile is the stack that registers the API Gateway routes, relative to this package.
+const cdkFile = "../../../cdk/cdk.go"
+
+// cdkMethods maps the net/http constants cdk.go uses to their methods.
+var cdkMethods = map[string]string{
+	"MethodGet":    http.MethodGet,
+	"MethodPost":   http.MethodPost,
+	"MethodPut":    http.MethodPut,
+	"MethodPatch":  http.MethodPatch,
+	"MethodDelete": http.MethodDelete,
+}
+
+// TestRoutesMatchCDK fails when Routes and the methods cdk.go adds to the API drift apart.
+func TestRoutesMatchCDK(t *testing.T) {
+	registered := cdkRoutes(t)
+	documented := map[string]bool{}
+	for _, r := range Routes {
+		documented[routeKey(r.Method, r.Path, r.Authenticated)] = true
+	}
+	for _, key := range sortedKeys(registered) {
+		if !documented[key] {
+			t.Errorf("cdk.go registers %s, it is missing from Routes", key)
+		}
+	}
+	for _, key := range sortedKeys(documented) {
+		if !registered[key] {
+			t.Errorf("Routes documents %s, cdk.go doesn't register it", key)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(Routes); err != nil {
+		t.Fatalf("Validate(Routes) = %v", err)
+	}
+}
+
+func routeKey(method, path string, authenticated bool) string {
+	if authenticated {
+		return method + " " + path + " (authenticated)"
+	}
+	return method + " " + path
+}
+
+// cdkRoutes walks cdk.go in source order, following resources assigned to variables and chained
+// AddResource calls, and returns a routeKey for every AddMethod.
+func cdkRoutes(t *testing.T) map[string]bool {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), cdkFile, nil, 0)
+	if err != nil {
+		t.Fatalf("error parsing %s: %v", cdkFile, err)
+	}
+	resources := map[string]string{}
+	routes := map[string]bool{}
+	var path func(expr ast.Expr) (string, bool)
+	path = func(expr ast.Expr) (string, bool) {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			p, ok := resources[e.Name]
+			return p, ok
+		case *ast.CallExpr:
+			sel, ok := e.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return "", false
+			}
+			switch sel.Sel.Name {
+			case "Root":
+				return "", true
+			case "AddResource":
+				parent, ok := path(sel.X)
+				if !ok || len(e.Args) == 0 {
+					return "", false
+				}
+				segment, ok := stringArg(e.Args[0])
+				return parent + "/" + segment, ok
+			}
+		}
+		return "", false
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, rhs := range n.Rhs {
+				ident, ok := n.Lhs[i].(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if p, ok := path(rhs); ok {
+					resources[ident.Name] = p
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "AddMethod" || len(n.Args) < 3 {
+				return true
+			}
+			p, ok := path(sel.X)
+			if !ok {
+				t.Errorf("can't follow the resource of the AddMethod at offset %d", n.Pos())
+				return true
+			}
+			method := methodArg(n.Args[0])
+			if method == "" {
+				t.Errorf("can't read the method of the AddMethod on %s", p)
+				return true
+			}
+			opts, _ := n.Args[2].(*ast.Ident)
+			routes[routeKey(method, p, opts != nil && opts.Name == "recruiterMethodOpts")] = true
+		}
+		return true
+	})
+	if len(routes) == 0 {
+		t.Fatalf("found no routes in %s", cdkFile)
+	}
+	return routes
+}
+
+// stringArg reads a jsii.String("...") argument.
+func stringArg(expr ast.Expr) (string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// methodArg reads a jsii.String(http.MethodX) argument.
+func methodArg(expr ast.Expr) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return ""
+	}
+	sel, ok := call.Args[0].(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	return cdkMethods[sel.Sel.Name]
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// modelsDir holds the types the API's enums are declared with, relative to this package.
+const modelsDir = "../models"
+
+// TestEnumsMatchModels fails when a string type in models gains or loses a constant without enums
+// being updated to match.
+func TestEnumsMatchModels(t *testing.T) {
+	declared := modelConstants(t)
+	documented := map[string][]string{}
+	for typ, values := range enums {
+		if typ.PkgPath() != "github.com/josepheid/upfront/api/models" {
+			continue
+		}
+		for _, v := range values {
+			documented[typ.Name()] = append(documented[typ.Name()], reflect.ValueOf(v).String())
+		}
+	}
+	for name, values := range declared {
+		sort.Strings(values)
+		got := documented[name]
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, values) {
+			t.Errorf("enums lists %v for models.%s, the package declares %v", got, name, values)
+		}
+	}
+	for name := range documented {
+		if _, ok := declared[name]; !ok {
+			t.Errorf("enums lists models.%s, the package declares no constants of it", name)
+		}
+	}
+}
+
+// modelConstants returns the values of the string constants declared with an explicit named type
+// in the models package, by type name.
+func modelConstants(t *testing.T) map[string][]string {
+	t.Helper()
+	pkgs, err := parser.ParseDir(token.NewFileSet(), modelsDir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("error parsing %s: %v", modelsDir, err)
+	}
+	constants := map[string][]string{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					value := spec.(*ast.ValueSpec)
+					typ, ok := value.Type.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					for _, v := range value.Values {
+						lit, ok := v.(*ast.BasicLit)
+						if !ok || lit.Kind != token.STRING {
+							continue
+						}
+						s, err := strconv.Unquote(lit.Value)
+						if err != nil {
+							t.Fatalf("error unquoting %s: %v", lit.Value, err)
+						}
+						constants[typ.Name] = append(constants[typ.Name], s)
+					}
+				}
+			}
+		}
+	}
+	return constants
+}
@@ -0,0 +1,295 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/josepheid/upfront/internal/respond"
+)
+
+// Document is an OpenAPI 3 document, only the parts of the specification that upfront uses are modelled.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	Security   []map[string][]any  `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps lower case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Security    []map[string][]any  `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	In   string `json:"in,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Example              any                `json:"example,omitempty"`
+}
+
+// Build creates the document for routes, request and response schemas are derived from the Go types
+// attached to each route.
+func Build(title, version string, routes []Route) (Document, error) {
+	if err := Validate(routes); err != nil {
+		return Document{}, err
+	}
+	g := generator{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+	doc := Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"apiKey": {Type: "apiKey", Name: "x-api-key", In: "header"},
+				// API Gateway's Cognito authorizer reads the recruiter's ID token from this header.
+				"cognito": {Type: "apiKey", Name: "Authorization", In: "header"},
+			},
+		},
+		Security: []map[string][]any{{"apiKey": {}}},
+	}
+	for _, route := range routes {
+		op := &Operation{
+			OperationID: route.OperationID,
+			Summary:     route.Summary,
+			Tags:        route.Tags,
+			Responses:   map[string]Response{},
+		}
+		if route.Authenticated {
+			op.Security = []map[string][]any{{"apiKey": {}, "cognito": {}}}
+		}
+		for _, name := range PathParams(route.Path) {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, q := range route.Query {
+			op.Parameters = append(op.Parameters, Parameter{Name: q.Name, In: "query", Required: q.Required, Schema: g.schema(reflect.TypeOf(q.Type))})
+		}
+		for _, h := range route.Headers {
+			op.Parameters = append(op.Parameters, Parameter{Name: h.Name, In: "header", Required: h.Required, Schema: g.schema(reflect.TypeOf(h.Type))})
+		}
+		if route.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: g.schema(reflect.TypeOf(route.Request))}},
+			}
+		}
+		for status, body := range route.Responses {
+			resp := Response{Description: http.StatusText(status)}
+			if file, ok := body.(File); ok {
+				resp.Content = map[string]MediaType{file.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+			} else if body != nil {
+				resp.Content = map[string]MediaType{"application/json": {Schema: g.schema(reflect.TypeOf(body))}}
+			}
+			if _, ok := body.(respond.Error); ok {
+				resp.Content["application/problem+json"] = MediaType{Schema: g.schema(reflect.TypeOf(respond.Problem{}))}
+			}
+			op.Responses[strconv.Itoa(status)] = resp
+		}
+		item, ok := doc.Paths[route.Path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+	return doc, nil
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// PathParams returns the names of the {placeholders} in path, in order.
+func PathParams(path string) []string {
+	var names []string
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// Validate checks that routes are unique, have operation IDs and document at least one response.
+func Validate(routes []Route) error {
+	seenRoutes := map[string]bool{}
+	seenIDs := map[string]bool{}
+	for _, r := range routes {
+		key := r.Method + " " + r.Path
+		switch {
+		case !strings.HasPrefix(r.Path, "/upfront/"):
+			return fmt.Errorf("route %s is not under /upfront/", key)
+		case seenRoutes[key]:
+			return fmt.Errorf("route %s is registered twice", key)
+		case r.OperationID == "":
+			return fmt.Errorf("route %s has no operation id", key)
+		case seenIDs[r.OperationID]:
+			return fmt.Errorf("operation id %q is used twice", r.OperationID)
+		case len(r.Responses) == 0:
+			return fmt.Errorf("route %s documents no responses", key)
+		}
+		seenRoutes[key] = true
+		seenIDs[r.OperationID] = true
+	}
+	return nil
+}
+
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func (g generator) schema(t reflect.Type) *Schema {
+	if values, ok := enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.ref(t)
+	}
+	return &Schema{}
+}
+
+// ref registers a struct as a named component schema and returns a reference to it.
+func (g generator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		g.schemas[name] = s
+		g.addFields(s, t)
+		sort.Strings(s.Required)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (g generator) componentName(t reflect.Type) string {
+	name := t.Name()
+	for _, existing := range g.names {
+		if existing == name {
+			// Two packages declare the same type name, qualify it with the package.
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			return pkg + "." + name
+		}
+	}
+	return name
+}
+
+func (g generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			g.addFields(s, f.Type)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fs := g.schema(f.Type)
+		if example, ok := f.Tag.Lookup("example"); ok && fs.Ref == "" {
+			fs.Example = exampleValue(fs.Type, example)
+		}
+		s.Properties[name] = fs
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func exampleValue(typ, example string) any {
+	switch typ {
+	case "integer":
+		if v, err := strconv.Atoi(example); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(example); err == nil {
+			return v
+		}
+	case "array":
+		return []string{example}
+	}
+	return example
+}
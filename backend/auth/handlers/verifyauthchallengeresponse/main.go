@@ -13,6 +13,7 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/josepheid/upfront/internal/timeouts"
 )
 
 // Challenge payload structure
@@ -27,7 +28,7 @@ type Handler struct {
 	logger   *slog.Logger
 }
 
-func (h Handler) Handle(event events.CognitoEventUserPoolsVerifyAuthChallenge) (events.CognitoEventUserPoolsVerifyAuthChallenge, error) {
+func (h Handler) Handle(ctx context.Context, event events.CognitoEventUserPoolsVerifyAuthChallenge) (events.CognitoEventUserPoolsVerifyAuthChallenge, error) {
 	email := event.Request.UserAttributes["email"]
 	expected := event.Request.PrivateChallengeParameters["challenge"]
 
@@ -44,7 +45,7 @@ func (h Handler) Handle(event events.CognitoEventUserPoolsVerifyAuthChallenge) (
 	}
 
 	// Decrypt the challenge answer
-	decryptedJSON, err := h.decrypt(decodedInput)
+	decryptedJSON, err := h.decrypt(ctx, decodedInput)
 	if err != nil {
 		h.logger.Error("failed to decrypt challenge answer: %v", "error", err)
 		event.Response.AnswerCorrect = false
@@ -88,7 +89,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	kmsc := kms.NewFromConfig(config)
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
+	kmsc := kms.NewFromConfig(config, func(o *kms.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.KMS))
+	})
 	kmsKeyID := os.Getenv("KMS_KEY_ID")
 
 	if kmsKeyID == "" {
@@ -105,10 +114,10 @@ func main() {
 	lambda.Start(handler.Handle)
 }
 
-func (h Handler) decrypt(input []byte) ([]byte, error) {
+func (h Handler) decrypt(ctx context.Context, input []byte) ([]byte, error) {
 
 	h.logger.Info("incoming input", "input", input)
-	out, err := h.kmsc.Decrypt(context.Background(), &kms.DecryptInput{
+	out, err := h.kmsc.Decrypt(ctx, &kms.DecryptInput{
 		CiphertextBlob: input,
 		KeyId:          &h.kmsKeyID,
 	})
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/repository"
+)
+
+func jobsList(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(false)
+	status := fs.String("status", string(models.Active), `only list posts with this status, "all" lists every post`)
+	companyID := fs.String("company", "", "only list posts of this company ID")
+	email := fs.String("email", "", "only list posts of this login email")
+	if err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	filter := expression.AttributeExists(expression.Name("PK"))
+	if *status != "all" {
+		filter = filter.And(expression.Name("status").Equal(expression.Value(models.Status(*status))))
+	}
+	if *companyID != "" {
+		filter = filter.And(expression.Name("companyID").Equal(expression.Value(*companyID)))
+	}
+	if *email != "" {
+		filter = filter.And(expression.Name("loginEmail").Equal(expression.Value(*email)))
+	}
+
+	jobPosts, err := a.jobPosts(ctx)
+	if err != nil {
+		return err
+	}
+	posts, err := jobPosts.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return a.print(posts, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tSTATUS\tPLAN\tEXPIRES\tEMAIL\tTITLE")
+		for _, p := range posts {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", p.JobID, p.Status, p.PlanType, p.ExpiresAt, p.LoginEmail, p.Title)
+		}
+	})
+}
+
+func jobsGet(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(false)
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	jobPosts, err := a.jobPosts(ctx)
+	if err != nil {
+		return err
+	}
+	item, err := jobPosts.Get(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return a.print(item, jobText(item))
+}
+
+func jobsExpire(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(true)
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	return a.updateJob(ctx, fs.Arg(0), models.AuditExpired, func(item *models.JobPostItem, now time.Time) error {
+		return item.Expire(now)
+	})
+}
+
+func jobsExtend(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(true)
+	days := fs.Int("days", 0, "days to add to the post")
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return fmt.Errorf("%w: --days must be positive", errUsage)
+	}
+	return a.updateJob(ctx, fs.Arg(0), models.AuditExtended, func(item *models.JobPostItem, now time.Time) error {
+		return item.Extend(*days, now)
+	})
+}
+
+func jobsDelete(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(true)
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	id := fs.Arg(0)
+	jobPosts, err := a.jobPosts(ctx)
+	if err != nil {
+		return err
+	}
+	item, err := jobPosts.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.dryRun {
+		return a.printDryRun(item, jobText(item))
+	}
+	deleted, err := jobPosts.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	result := struct {
+		JobID   string `json:"jobID"`
+		Deleted int    `json:"deleted"`
+	}{id, deleted}
+	return a.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "deleted job post %s and %d items under it, its audit log is kept\n", id, deleted-1)
+	})
+}
+
+// updateJob applies change to the job post with id and saves it with an audit entry for action,
+// unless it is a dry run.
+func (a *app) updateJob(ctx context.Context, id string, action models.AuditAction, change func(item *models.JobPostItem, now time.Time) error) error {
+	jobPosts, err := a.jobPosts(ctx)
+	if err != nil {
+		return err
+	}
+	item, err := jobPosts.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	before := item.Snapshot()
+	now := time.Now()
+	if err := change(&item, now); err != nil {
+		return fmt.Errorf("job post is %s: %w", item.Status, err)
+	}
+	if a.dryRun {
+		return a.printDryRun(item, jobText(item))
+	}
+	err = jobPosts.Save(ctx, &item, item.Audit(action, operator(), before, now))
+	if errors.Is(err, repository.ErrConflict) {
+		return errors.New("job post changed while updating it, please retry")
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(item, jobText(item))
+}
+
+func jobText(item models.JobPostItem) func(w io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintf(w, "ID\t%s\n", item.JobID)
+		fmt.Fprintf(w, "Title\t%s\n", item.Title)
+		fmt.Fprintf(w, "Company\t%s\n", item.CompanyName)
+		fmt.Fprintf(w, "Login email\t%s\n", item.LoginEmail)
+		fmt.Fprintf(w, "Status\t%s\n", item.Status)
+		fmt.Fprintf(w, "Plan\t%s, %d days\n", item.PlanType, item.PlanDuration)
+		fmt.Fprintf(w, "Created\t%s\n", item.CreatedAt)
+		fmt.Fprintf(w, "Expires\t%s\n", item.ExpiresAt)
+		if item.OpenReportCount > 0 {
+			fmt.Fprintf(w, "Open reports\t%d\n", item.OpenReportCount)
+		}
+		for _, p := range item.Purchases() {
+			fmt.Fprintf(w, "Purchase\t%s %s %d %s %s %s\n", p.SessionID, p.Kind, p.Amount, p.Currency, p.Status, p.PaidAt)
+		}
+		for _, r := range item.Refunds {
+			fmt.Fprintf(w, "Refund\t%s %d %s %s %s\n", r.SessionID, r.Amount, r.Currency, r.Status, r.CreatedAt)
+		}
+	}
+}
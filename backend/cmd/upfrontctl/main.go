@@ -0,0 +1,271 @@
+// Command upfrontctl runs support tasks against the job board, using the same packages as the
+// lambdas. It reads the same environment variables as them, UPFRONT_TABLE_NAME always and
+// USER_POOL_ID, KMS_KEY_ID, ALLOWED_ORIGINS or STRIPE_SECRET_KEY for the commands that need them.
+// STRIPE_SECRET_KEY is read from Secrets Manager when it isn't set.
+//
+// Every command takes --json to print JSON, and commands that change anything take --dry-run to
+// print the change without making it.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/josepheid/upfront/internal/accounts"
+	"github.com/josepheid/upfront/internal/magiclink"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/timeouts"
+)
+
+const usage = `usage: upfrontctl <group> <command> [flags] [args]
+
+  jobs list [--status Active|all] [--company id] [--email email]
+  jobs get <id>
+  jobs expire <id>
+  jobs extend --days n <id>
+  jobs delete <id>
+  users resend-link --origin url <email>
+  users disable <email>
+  payments lookup <sessionId>
+  payments refund [--amount pence] [--reason text] <sessionId>
+  promo create --code code (--percent-off n | --amount-off pence) [--max-redemptions n] [--expires yyyy-mm-dd]
+
+Every command takes --json, commands that change anything take --dry-run.`
+
+// errUsage is returned when a command is called with the wrong arguments.
+var errUsage = errors.New("invalid arguments")
+
+type command func(ctx context.Context, a *app, args []string) error
+
+var commands = map[string]map[string]command{
+	"jobs": {
+		"list":   jobsList,
+		"get":    jobsGet,
+		"expire": jobsExpire,
+		"extend": jobsExtend,
+		"delete": jobsDelete,
+	},
+	"users": {
+		"resend-link": usersResendLink,
+		"disable":     usersDisable,
+	},
+	"payments": {
+		"lookup": paymentsLookup,
+		"refund": paymentsRefund,
+	},
+	"promo": {
+		"create": promoCreate,
+	},
+}
+
+func main() {
+	if len(os.Args) < 3 || commands[os.Args[1]][os.Args[2]] == nil {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	ctx := context.Background()
+	a := &app{
+		name: os.Args[1] + " " + os.Args[2],
+		out:  os.Stdout,
+	}
+	err := commands[os.Args[1]][os.Args[2]](ctx, a, os.Args[3:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, "upfrontctl:", err)
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "upfrontctl:", err)
+		os.Exit(1)
+	}
+}
+
+// app holds the flags every command takes and creates clients as commands need them.
+type app struct {
+	name   string
+	out    io.Writer
+	json   bool
+	dryRun bool
+
+	config  *aws.Config
+	budgets timeouts.Budgets
+}
+
+// flags returns a flag set for the command with --json, and --dry-run when mutates is set.
+func (a *app) flags(mutates bool) *flag.FlagSet {
+	fs := flag.NewFlagSet(a.name, flag.ContinueOnError)
+	fs.BoolVar(&a.json, "json", false, "print JSON")
+	if mutates {
+		fs.BoolVar(&a.dryRun, "dry-run", false, "print the change without making it")
+	}
+	return fs
+}
+
+// parse parses args and checks the number of positional arguments left.
+func (a *app) parse(fs *flag.FlagSet, args []string, positional int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != positional {
+		return errUsage
+	}
+	return nil
+}
+
+func (a *app) aws(ctx context.Context) (aws.Config, error) {
+	if a.config != nil {
+		return *a.config, nil
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("eu-west-2"))
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("error loading aws config: %w", err)
+	}
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("invalid timeout configuration: %w", err)
+	}
+	a.config = &cfg
+	a.budgets = budgets
+	return cfg, nil
+}
+
+func (a *app) dynamoDB(ctx context.Context) (*dynamodb.Client, string, error) {
+	tableName, err := env("UPFRONT_TABLE_NAME")
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := a.aws(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	ddbc := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(a.budgets.DynamoDB))
+	})
+	return ddbc, tableName, nil
+}
+
+func (a *app) jobPosts(ctx context.Context) (repository.JobPosts, error) {
+	ddbc, tableName, err := a.dynamoDB(ctx)
+	if err != nil {
+		return repository.JobPosts{}, err
+	}
+	return repository.NewJobPosts(ddbc, tableName), nil
+}
+
+func (a *app) cognito(ctx context.Context) (*cognitoidentityprovider.Client, string, error) {
+	userPoolId, err := env("USER_POOL_ID")
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := a.aws(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	cipc := cognitoidentityprovider.NewFromConfig(cfg, func(o *cognitoidentityprovider.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(a.budgets.Cognito))
+	})
+	return cipc, userPoolId, nil
+}
+
+func (a *app) accounts(ctx context.Context) (accounts.Accounts, error) {
+	cipc, userPoolId, err := a.cognito(ctx)
+	if err != nil {
+		return accounts.Accounts{}, err
+	}
+	return accounts.NewAccounts(cipc, userPoolId), nil
+}
+
+func (a *app) links(ctx context.Context) (magiclink.Sender, error) {
+	kmsKeyID, err := env("KMS_KEY_ID")
+	if err != nil {
+		return magiclink.Sender{}, err
+	}
+	cipc, userPoolId, err := a.cognito(ctx)
+	if err != nil {
+		return magiclink.Sender{}, err
+	}
+	cfg, err := a.aws(ctx)
+	if err != nil {
+		return magiclink.Sender{}, err
+	}
+	sesc := ses.NewFromConfig(cfg, func(o *ses.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(a.budgets.SES))
+	})
+	kmsc := kms.NewFromConfig(cfg, func(o *kms.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(a.budgets.KMS))
+	})
+	return magiclink.NewSender(sesc, kmsc, cipc, kmsKeyID, userPoolId), nil
+}
+
+func (a *app) payments(ctx context.Context) (payments.Provider, error) {
+	cfg, err := a.aws(ctx)
+	if err != nil {
+		return payments.Provider{}, err
+	}
+	secret := os.Getenv("STRIPE_SECRET_KEY")
+	if secret == "" {
+		result, err := secretsmanager.NewFromConfig(cfg).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+			SecretId:     aws.String("STRIPE_SECRET_KEY"),
+			VersionStage: aws.String("AWSCURRENT"),
+		})
+		if err != nil {
+			return payments.Provider{}, fmt.Errorf("error getting stripe secret: %w", err)
+		}
+		var secretKeyValuePair map[string]string
+		if err := json.Unmarshal([]byte(aws.ToString(result.SecretString)), &secretKeyValuePair); err != nil {
+			return payments.Provider{}, fmt.Errorf("error unmarshalling stripe secret: %w", err)
+		}
+		secret = secretKeyValuePair["STRIPE_SECRET_KEY"]
+	}
+	return payments.NewProvider(secret, a.budgets), nil
+}
+
+// print writes v as JSON with --json, otherwise text writes it as aligned columns.
+func (a *app) print(v any, text func(w io.Writer)) error {
+	if a.json {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	text(tw)
+	return tw.Flush()
+}
+
+// printDryRun prints what a command would have changed.
+func (a *app) printDryRun(v any, text func(w io.Writer)) error {
+	fmt.Fprintln(os.Stderr, "dry run, nothing was changed")
+	return a.print(v, text)
+}
+
+// operator identifies who made a change, it is recorded alongside refunds and in the audit log.
+func operator() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return "upfrontctl/" + u.Username
+	}
+	return "upfrontctl"
+}
+
+func env(name string) (string, error) {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+}
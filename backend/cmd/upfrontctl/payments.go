@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/stripe/stripe-go/v80"
+)
+
+// SessionLookup is a checkout session and what the job post it paid for recorded about it.
+type SessionLookup struct {
+	SessionID     string                              `json:"sessionID"`
+	Status        stripe.CheckoutSessionStatus        `json:"status"`
+	PaymentStatus stripe.CheckoutSessionPaymentStatus `json:"paymentStatus"`
+	AmountTotal   int64                               `json:"amountTotal"`
+	Currency      stripe.Currency                     `json:"currency"`
+	CustomerEmail string                              `json:"customerEmail"`
+	JobID         string                              `json:"jobID"`
+	Purchase      *models.Purchase                    `json:"purchase,omitempty"`
+	Refund        *models.Refund                      `json:"refund,omitempty"`
+}
+
+func paymentsLookup(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(false)
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	lookup, _, err := a.lookupSession(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return a.print(lookup, lookupText(lookup))
+}
+
+func paymentsRefund(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(true)
+	amount := fs.Int64("amount", 0, "pence to refund, the whole purchase when not set. A pending refund is retried with its recorded amount")
+	reason := fs.String("reason", "", "why the purchase is refunded, kept with the refund")
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	sessionID := fs.Arg(0)
+	lookup, item, err := a.lookupSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return fmt.Errorf("job post %s not found", lookup.JobID)
+	}
+
+	// A refund left pending by a Stripe failure is sent again with the amount recorded the first
+	// time, Stripe's idempotency key for the session stops it being paid out twice.
+	i, retry := pendingRefund(*item, sessionID)
+	if retry && *amount != 0 && *amount != item.Refunds[i].Amount {
+		return fmt.Errorf("a refund of %d is already pending for %s, run again without --amount to retry it", item.Refunds[i].Amount, sessionID)
+	}
+	before := item.Snapshot()
+	now := time.Now()
+	if !retry {
+		i, err = item.Refund(now, sessionID, *amount, operator(), *reason)
+		if err != nil {
+			return err
+		}
+	}
+	lookup.Refund = &item.Refunds[i]
+	if a.dryRun {
+		return a.printDryRun(lookup, lookupText(lookup))
+	}
+
+	jobPosts, err := a.jobPosts(ctx)
+	if err != nil {
+		return err
+	}
+	// The refund is saved as pending first, like cancelling a post, so it is on record even if
+	// Stripe fails.
+	if !retry {
+		if err := saveJob(ctx, jobPosts, item, item.Audit(models.AuditRefunded, operator(), before, now)); err != nil {
+			return err
+		}
+	}
+	provider, err := a.payments(ctx)
+	if err != nil {
+		return err
+	}
+	pending := &item.Refunds[i]
+	result, err := provider.Refund(ctx, sessionID, pending.Amount)
+	if err != nil {
+		return err
+	}
+	before = item.Snapshot()
+	now = time.Now()
+	pending.RefundID = result.ID
+	pending.Status = models.RefundIssued
+	pending.IssuedAt = now.Format(time.RFC3339)
+	item.UpdatedAt = now.Format(time.RFC3339)
+	if err := saveJob(ctx, jobPosts, item, item.Audit(models.AuditRefunded, operator(), before, now)); err != nil {
+		return fmt.Errorf("refund %s was issued but not recorded: %w", result.ID, err)
+	}
+	lookup.Refund = pending
+	return a.print(lookup, lookupText(lookup))
+}
+
+// pendingRefund returns the index of the refund of sessionID that was recorded but not issued, if
+// there is one.
+func pendingRefund(item models.JobPostItem, sessionID string) (int, bool) {
+	for _, i := range item.PendingRefunds() {
+		if item.Refunds[i].SessionID == sessionID {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// lookupSession gets a checkout session and the job post it was for, the post is nil if it has
+// been deleted.
+func (a *app) lookupSession(ctx context.Context, sessionID string) (SessionLookup, *models.JobPostItem, error) {
+	provider, err := a.payments(ctx)
+	if err != nil {
+		return SessionLookup{}, nil, err
+	}
+	session, err := provider.GetCheckout(ctx, sessionID)
+	if err != nil {
+		return SessionLookup{}, nil, err
+	}
+	lookup := SessionLookup{
+		SessionID:     session.ID,
+		Status:        session.Status,
+		PaymentStatus: session.PaymentStatus,
+		AmountTotal:   session.AmountTotal,
+		Currency:      session.Currency,
+		CustomerEmail: session.CustomerEmail,
+		JobID:         session.ClientReferenceID,
+	}
+	if lookup.JobID == "" {
+		return lookup, nil, nil
+	}
+
+	jobPosts, err := a.jobPosts(ctx)
+	if err != nil {
+		return SessionLookup{}, nil, err
+	}
+	item, err := jobPosts.Get(ctx, lookup.JobID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return lookup, nil, nil
+	}
+	if err != nil {
+		return SessionLookup{}, nil, err
+	}
+	for _, p := range item.Purchases() {
+		if p.SessionID == sessionID {
+			lookup.Purchase = &p
+		}
+	}
+	for _, r := range item.Refunds {
+		if r.SessionID == sessionID {
+			lookup.Refund = &r
+		}
+	}
+	return lookup, &item, nil
+}
+
+func saveJob(ctx context.Context, jobPosts repository.JobPosts, item *models.JobPostItem, audit models.AuditEntry) error {
+	err := jobPosts.Save(ctx, item, audit)
+	if errors.Is(err, repository.ErrConflict) {
+		return errors.New("job post changed while updating it, please retry")
+	}
+	return err
+}
+
+func lookupText(lookup SessionLookup) func(w io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintf(w, "Session\t%s\n", lookup.SessionID)
+		fmt.Fprintf(w, "Status\t%s, %s\n", lookup.Status, lookup.PaymentStatus)
+		fmt.Fprintf(w, "Amount\t%d %s\n", lookup.AmountTotal, lookup.Currency)
+		fmt.Fprintf(w, "Customer\t%s\n", lookup.CustomerEmail)
+		fmt.Fprintf(w, "Job post\t%s\n", lookup.JobID)
+		if p := lookup.Purchase; p != nil {
+			fmt.Fprintf(w, "Purchase\t%s %s %d days, %d %s %s\n", p.Kind, p.PlanType, p.PlanDuration, p.Amount, p.Currency, p.Status)
+		}
+		if r := lookup.Refund; r != nil {
+			fmt.Fprintf(w, "Refund\t%d %s %s %s\n", r.Amount, r.Currency, r.Status, r.RefundID)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/josepheid/upfront/internal/payments"
+)
+
+type promoResult struct {
+	ID             string  `json:"id,omitempty"`
+	Code           string  `json:"code"`
+	PercentOff     float64 `json:"percentOff,omitempty"`
+	AmountOff      int64   `json:"amountOff,omitempty"`
+	MaxRedemptions int64   `json:"maxRedemptions,omitempty"`
+	ExpiresAt      string  `json:"expiresAt,omitempty"`
+}
+
+func promoCreate(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(true)
+	code := fs.String("code", "", "code recruiters enter at checkout")
+	percentOff := fs.Float64("percent-off", 0, "percentage taken off one payment")
+	amountOff := fs.Int64("amount-off", 0, "pence taken off one payment")
+	maxRedemptions := fs.Int64("max-redemptions", 0, "times the code can be used, unlimited when not set")
+	expires := fs.String("expires", "", "last day the code can be used, yyyy-mm-dd")
+	if err := a.parse(fs, args, 0); err != nil {
+		return err
+	}
+	params := payments.PromotionParams{
+		Code:           strings.ToUpper(strings.TrimSpace(*code)),
+		PercentOff:     *percentOff,
+		AmountOff:      *amountOff,
+		MaxRedemptions: *maxRedemptions,
+	}
+	if params.Code == "" {
+		return fmt.Errorf("%w: --code is required", errUsage)
+	}
+	if (params.PercentOff > 0) == (params.AmountOff > 0) {
+		return fmt.Errorf("%w: set one of --percent-off or --amount-off", errUsage)
+	}
+	if params.PercentOff > 100 || params.PercentOff < 0 || params.AmountOff < 0 || params.MaxRedemptions < 0 {
+		return fmt.Errorf("%w: --percent-off must be at most 100 and amounts can't be negative", errUsage)
+	}
+	result := promoResult{
+		Code:           params.Code,
+		PercentOff:     params.PercentOff,
+		AmountOff:      params.AmountOff,
+		MaxRedemptions: params.MaxRedemptions,
+	}
+	if *expires != "" {
+		day, err := time.Parse(time.DateOnly, *expires)
+		if err != nil {
+			return fmt.Errorf("%w: --expires must be yyyy-mm-dd", errUsage)
+		}
+		// The code works until the end of the day.
+		params.ExpiresAt = day.AddDate(0, 0, 1)
+		result.ExpiresAt = params.ExpiresAt.Format(time.RFC3339)
+	}
+	text := func(w io.Writer) {
+		fmt.Fprintf(w, "Code\t%s\n", result.Code)
+		if result.PercentOff > 0 {
+			fmt.Fprintf(w, "Discount\t%g%%\n", result.PercentOff)
+		} else {
+			fmt.Fprintf(w, "Discount\t%d pence\n", result.AmountOff)
+		}
+		if result.MaxRedemptions > 0 {
+			fmt.Fprintf(w, "Max redemptions\t%d\n", result.MaxRedemptions)
+		}
+		if result.ExpiresAt != "" {
+			fmt.Fprintf(w, "Expires\t%s\n", result.ExpiresAt)
+		}
+	}
+	if a.dryRun {
+		return a.printDryRun(result, text)
+	}
+
+	provider, err := a.payments(ctx)
+	if err != nil {
+		return err
+	}
+	promotionCode, err := provider.CreatePromotion(ctx, params)
+	if err != nil {
+		return err
+	}
+	result.ID = promotionCode.ID
+	return a.print(result, text)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/josepheid/upfront/internal/origins"
+)
+
+type userResult struct {
+	Email  string `json:"email"`
+	Action string `json:"action"`
+}
+
+func usersResendLink(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(true)
+	rawOrigin := fs.String("origin", "", "frontend origin the link opens, it must be in ALLOWED_ORIGINS")
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	email := strings.ToLower(fs.Arg(0))
+	allowedOrigins, err := origins.NewAllowlist(os.Getenv("ALLOWED_ORIGINS"))
+	if err != nil {
+		return fmt.Errorf("environment variable ALLOWED_ORIGINS is not valid: %w", err)
+	}
+	origin, err := allowedOrigins.Resolve(*rawOrigin)
+	if err != nil {
+		return err
+	}
+	result := userResult{Email: email, Action: "sent login link"}
+	text := func(w io.Writer) {
+		fmt.Fprintf(w, "sent a login link for %s to %s\n", origin, email)
+	}
+	if a.dryRun {
+		return a.printDryRun(result, text)
+	}
+
+	links, err := a.links(ctx)
+	if err != nil {
+		return err
+	}
+	link, err := links.Start(ctx, email, origin, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := links.Send(ctx, email, link); err != nil {
+		return err
+	}
+	return a.print(result, text)
+}
+
+func usersDisable(ctx context.Context, a *app, args []string) error {
+	fs := a.flags(true)
+	if err := a.parse(fs, args, 1); err != nil {
+		return err
+	}
+	email := strings.ToLower(fs.Arg(0))
+	result := userResult{Email: email, Action: "disabled"}
+	text := func(w io.Writer) {
+		fmt.Fprintf(w, "disabled %s and signed them out\n", email)
+	}
+	if a.dryRun {
+		return a.printDryRun(result, text)
+	}
+
+	accounts, err := a.accounts(ctx)
+	if err != nil {
+		return err
+	}
+	if err := accounts.Disable(ctx, email); err != nil {
+		return err
+	}
+	return a.print(result, text)
+}
@@ -0,0 +1,55 @@
+package access
+
+import (
+	"context"
+	"errors"
+
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/auth"
+	"github.com/josepheid/upfront/internal/repository"
+)
+
+// ErrForbidden is returned when a recruiter doesn't have the role an action needs.
+var ErrForbidden = errors.New("recruiter is not allowed to do this")
+
+// Checker decides what recruiters can do from their company memberships.
+type Checker struct {
+	members repository.Members
+}
+
+func NewChecker(members repository.Members) Checker {
+	return Checker{
+		members: members,
+	}
+}
+
+// Company returns nil if the identity is an admin or an active member of a company with at least
+// role, otherwise ErrForbidden.
+func (c Checker) Company(ctx context.Context, identity auth.Identity, companyID string, role models.Role) error {
+	if identity.IsAdmin() {
+		return nil
+	}
+	member, err := c.members.Get(ctx, companyID, identity.Email)
+	if errors.Is(err, repository.ErrMemberNotFound) {
+		return ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if !member.Active() || !member.Role.AtLeast(role) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// JobPost is Company for the post's company. Posts made before companies existed have no company,
+// only the recruiter who made them can act on those.
+func (c Checker) JobPost(ctx context.Context, identity auth.Identity, item models.JobPostItem, role models.Role) error {
+	if item.CompanyID == "" {
+		if identity.IsAdmin() || identity.Owns(item.LoginEmail) {
+			return nil
+		}
+		return ErrForbidden
+	}
+	return c.Company(ctx, identity, item.CompanyID, role)
+}
@@ -0,0 +1,105 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	cognitotypes "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"golang.org/x/exp/rand"
+)
+
+// Accounts manages recruiters' Cognito users, recruiters sign in with magic links so a user only
+// needs to exist for startChallenge to set its challenge.
+type Accounts struct {
+	cipc       *cognitoidentityprovider.Client
+	userPoolId string
+}
+
+func NewAccounts(cipc *cognitoidentityprovider.Client, userPoolId string) Accounts {
+	return Accounts{
+		cipc:       cipc,
+		userPoolId: userPoolId,
+	}
+}
+
+// Ensure creates a confirmed user for email if there isn't one already.
+func (a Accounts) Ensure(ctx context.Context, email string) error {
+	email = strings.ToLower(email)
+	_, err := a.cipc.AdminGetUser(ctx, &cognitoidentityprovider.AdminGetUserInput{
+		UserPoolId: aws.String(a.userPoolId),
+		Username:   aws.String(email),
+	})
+	if err == nil {
+		return nil
+	}
+	var notFound *cognitotypes.UserNotFoundException
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	_, err = a.cipc.AdminCreateUser(ctx, &cognitoidentityprovider.AdminCreateUserInput{
+		UserPoolId:             aws.String(a.userPoolId),
+		Username:               aws.String(email),
+		MessageAction:          cognitotypes.MessageActionTypeSuppress, // Suppress the temporary password email
+		DesiredDeliveryMediums: []cognitotypes.DeliveryMediumType{},    // Don't send any messages
+		UserAttributes: []cognitotypes.AttributeType{
+			{
+				Name:  aws.String("email"),
+				Value: aws.String(email),
+			},
+			{
+				Name:  aws.String("email_verified"),
+				Value: aws.String("true"),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating user in userpool: %w", err)
+	}
+
+	_, err = a.cipc.AdminSetUserPassword(ctx, &cognitoidentityprovider.AdminSetUserPasswordInput{
+		UserPoolId: aws.String(a.userPoolId),
+		Username:   aws.String(email),
+		Password:   aws.String(generateSecureRandomPassword()), // Generate a secure random password
+		Permanent:  true,                                       // This prevents FORCE_CHANGE_PASSWORD status
+	})
+	if err != nil {
+		return fmt.Errorf("error confirming user in userpool: %w", err)
+	}
+	return nil
+}
+
+// Disable stops email from signing in and signs them out everywhere, their job posts are kept.
+func (a Accounts) Disable(ctx context.Context, email string) error {
+	email = strings.ToLower(email)
+	_, err := a.cipc.AdminDisableUser(ctx, &cognitoidentityprovider.AdminDisableUserInput{
+		UserPoolId: aws.String(a.userPoolId),
+		Username:   aws.String(email),
+	})
+	if err != nil {
+		return fmt.Errorf("error disabling user: %w", err)
+	}
+	_, err = a.cipc.AdminUserGlobalSignOut(ctx, &cognitoidentityprovider.AdminUserGlobalSignOutInput{
+		UserPoolId: aws.String(a.userPoolId),
+		Username:   aws.String(email),
+	})
+	if err != nil {
+		return fmt.Errorf("error signing user out: %w", err)
+	}
+	return nil
+}
+
+func generateSecureRandomPassword() string {
+	const length = 32
+	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*()_+-=[]{}|"
+
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = charset[rand.Intn(len(charset))]
+	}
+	return string(b)
+}
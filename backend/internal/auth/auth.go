@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+)
+
+// AdminGroup is the Cognito group whose members can act on any job post.
+const AdminGroup = "admins"
+
+// ErrUnauthenticated is returned when a request has no verified Cognito claims.
+var ErrUnauthenticated = errors.New("request is not authenticated")
+
+// Identity is the recruiter making a request, as verified by the API Gateway Cognito authorizer.
+type Identity struct {
+	Email  string
+	Groups []string
+}
+
+// FromRequest reads the caller's identity from the authorizer claims on r.
+func FromRequest(r *http.Request) (Identity, error) {
+	apiGatewayContext, ok := core.GetAPIGatewayContextFromContext(r.Context())
+	if !ok {
+		return Identity{}, ErrUnauthenticated
+	}
+	claims, ok := apiGatewayContext.Authorizer["claims"].(map[string]any)
+	if !ok {
+		return Identity{}, ErrUnauthenticated
+	}
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return Identity{}, ErrUnauthenticated
+	}
+	identity := Identity{Email: strings.ToLower(email)}
+	if groups, ok := claims["cognito:groups"].(string); ok {
+		identity.Groups = parseGroups(groups)
+	}
+	return identity, nil
+}
+
+// Owns reports whether the identity is the recruiter that owns a job post.
+func (i Identity) Owns(loginEmail string) bool {
+	return strings.EqualFold(i.Email, loginEmail)
+}
+
+// IsAdmin reports whether the identity is in AdminGroup.
+func (i Identity) IsAdmin() bool {
+	for _, g := range i.Groups {
+		if g == AdminGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGroups handles both forms REST API authorizers pass groups in, "a,b" and "[a b]".
+func parseGroups(groups string) []string {
+	groups = strings.Trim(groups, "[]")
+	return strings.FieldsFunc(groups, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
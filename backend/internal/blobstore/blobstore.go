@@ -0,0 +1,179 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrNotFound is returned when a key doesn't exist.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps files such as invoices outside the table.
+type Store interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// S3 stores blobs in a bucket.
+type S3 struct {
+	client *s3.Client
+	bucket string
+}
+
+func NewS3(client *s3.Client, bucket string) S3 {
+	return S3{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+func (s S3) Put(ctx context.Context, key, contentType string, data []byte) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("error putting object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s S3) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting object %s: %w", key, err)
+	}
+	defer out.Body.Close()
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading object %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (s S3) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting object %s: %w", key, err)
+	}
+	return nil
+}
+
+// PresignPut returns a URL that uploads exactly size bytes of contentType to key until expires
+// has passed. The client must send the same Content-Type and Content-Length headers.
+func (s S3) PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(s.client).PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", fmt.Errorf("error presigning upload to %s: %w", key, err)
+	}
+	return req.URL, nil
+}
+
+// Dir stores blobs as files under a directory, for running locally without S3.
+type Dir struct {
+	root string
+}
+
+func NewDir(root string) Dir {
+	return Dir{
+		root: root,
+	}
+}
+
+func (d Dir) Put(ctx context.Context, key, contentType string, data []byte) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating directory for %s: %w", key, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing %s: %w", key, err)
+	}
+	return nil
+}
+
+func (d Dir) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (d Dir) Delete(ctx context.Context, key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting %s: %w", key, err)
+	}
+	return nil
+}
+
+// path keeps keys inside the root, keys are built by the API but are checked anyway.
+func (d Dir) path(key string) (string, error) {
+	path := filepath.Join(d.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(d.root)+string(filepath.Separator)) {
+		return "", fmt.Errorf("key %q is outside the blob store", key)
+	}
+	return path, nil
+}
+
+// EndpointFromEnv points an S3 client at S3_ENDPOINT when it is set, so a local S3 compatible
+// server such as MinIO can stand in for S3. Pass it to s3.NewFromConfig.
+func EndpointFromEnv(o *s3.Options) {
+	if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+	}
+}
+
+// FromEnv uses the BLOB_STORE_BUCKET bucket when it is set, otherwise files under BLOB_STORE_DIR.
+func FromEnv(client *s3.Client) (Store, error) {
+	if bucket := os.Getenv("BLOB_STORE_BUCKET"); bucket != "" {
+		return NewS3(client, bucket), nil
+	}
+	if dir := os.Getenv("BLOB_STORE_DIR"); dir != "" {
+		return NewDir(dir), nil
+	}
+	return nil, errors.New("environment variable BLOB_STORE_BUCKET or BLOB_STORE_DIR must be set")
+}
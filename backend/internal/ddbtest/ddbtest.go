@@ -0,0 +1,363 @@
+// Package ddbtest serves an in-memory stand-in for the DynamoDB API, so code that takes a
+// *dynamodb.Client can be tested without AWS. It supports what upfront uses: GetItem, PutItem,
+// UpdateItem, DeleteItem, Query and TransactWriteItems on a table keyed by PK and SK, with the
+// condition, key condition, filter and update expressions the expression package builds.
+package ddbtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Index is a global secondary index, items without its PK attribute are left out of it.
+type Index struct {
+	Name string
+	PK   string
+	SK   string
+}
+
+// Table holds the items of every table the client is used with, table names are ignored.
+type Table struct {
+	mu      sync.Mutex
+	items   map[string]map[string]value
+	indexes map[string]Index
+}
+
+// New starts a stand-in with indexes and returns it with a client that talks to it. It is closed
+// when the test ends.
+func New(t testing.TB, indexes ...Index) (*Table, *dynamodb.Client) {
+	t.Helper()
+	table := &Table{items: map[string]map[string]value{}, indexes: map[string]Index{}}
+	for _, idx := range indexes {
+		table.indexes[idx.Name] = idx
+	}
+	server := httptest.NewServer(table)
+	t.Cleanup(server.Close)
+	client := dynamodb.New(dynamodb.Options{
+		Region:       "eu-west-2",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		RetryMaxAttempts: 1,
+	})
+	return table, client
+}
+
+// Len returns the number of items stored.
+func (tb *Table) Len() int {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return len(tb.items)
+}
+
+// Keys returns the PK and SK of every item stored, sorted.
+func (tb *Table) Keys() [][2]string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	keys := [][2]string{}
+	for _, item := range tb.items {
+		keys = append(keys, [2]string{str(item["PK"]), str(item["SK"])})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
+
+// value is an attribute value in the wire format, such as {"S": "a"} or {"N": "1"}.
+type value = map[string]any
+
+type request struct {
+	Key                       map[string]value
+	Item                      map[string]value
+	ConditionExpression       string
+	UpdateExpression          string
+	KeyConditionExpression    string
+	FilterExpression          string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues map[string]value
+	IndexName                 string
+	ScanIndexForward          *bool
+	Limit                     int
+	ExclusiveStartKey         map[string]value
+	ReturnValues              string
+	TransactItems             []struct {
+		Put            *request
+		Update         *request
+		Delete         *request
+		ConditionCheck *request
+	}
+}
+
+type apiError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+	Reasons []struct {
+		Code string
+	} `json:"CancellationReasons,omitempty"`
+}
+
+func (e *apiError) Error() string {
+	return e.Type + ": " + e.Message
+}
+
+var errConditionFailed = &apiError{Type: "com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException", Message: "The conditional request failed"}
+
+func (tb *Table) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	var req request
+	err := json.NewDecoder(r.Body).Decode(&req)
+	var resp any
+	if err == nil {
+		tb.mu.Lock()
+		resp, err = tb.handle(op, req)
+		tb.mu.Unlock()
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	if err != nil {
+		apiErr, ok := err.(*apiError)
+		if !ok {
+			apiErr = &apiError{Type: "com.amazonaws.dynamodb.v20120810#ValidationException", Message: err.Error()}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(apiErr)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (tb *Table) handle(op string, req request) (any, error) {
+	switch op {
+	case "GetItem":
+		item, ok := tb.items[itemKey(req.Key)]
+		if !ok {
+			return map[string]any{}, nil
+		}
+		return map[string]any{"Item": item}, nil
+	case "PutItem":
+		if err := tb.check(req, req.Item); err != nil {
+			return nil, err
+		}
+		tb.items[itemKey(req.Item)] = clone(req.Item)
+		return map[string]any{}, nil
+	case "DeleteItem":
+		if err := tb.check(req, req.Key); err != nil {
+			return nil, err
+		}
+		delete(tb.items, itemKey(req.Key))
+		return map[string]any{}, nil
+	case "UpdateItem":
+		if err := tb.check(req, req.Key); err != nil {
+			return nil, err
+		}
+		updated, changed, err := tb.update(req)
+		if err != nil {
+			return nil, err
+		}
+		switch req.ReturnValues {
+		case "ALL_NEW":
+			return map[string]any{"Attributes": updated}, nil
+		case "UPDATED_NEW":
+			attrs := map[string]value{}
+			for _, name := range changed {
+				if v, ok := updated[name]; ok {
+					attrs[name] = v
+				}
+			}
+			return map[string]any{"Attributes": attrs}, nil
+		}
+		return map[string]any{}, nil
+	case "Query":
+		return tb.query(req)
+	case "TransactWriteItems":
+		failed := false
+		reasons := make([]struct{ Code string }, len(req.TransactItems))
+		for i, ti := range req.TransactItems {
+			reasons[i].Code = "None"
+			var err error
+			switch {
+			case ti.Put != nil:
+				err = tb.check(*ti.Put, ti.Put.Item)
+			case ti.Update != nil:
+				err = tb.check(*ti.Update, ti.Update.Key)
+			case ti.Delete != nil:
+				err = tb.check(*ti.Delete, ti.Delete.Key)
+			case ti.ConditionCheck != nil:
+				err = tb.check(*ti.ConditionCheck, ti.ConditionCheck.Key)
+			}
+			if err == errConditionFailed {
+				reasons[i].Code = "ConditionalCheckFailed"
+				failed = true
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		if failed {
+			return nil, &apiError{Type: "com.amazonaws.dynamodb.v20120810#TransactionCanceledException", Message: "Transaction cancelled", Reasons: reasons}
+		}
+		for _, ti := range req.TransactItems {
+			switch {
+			case ti.Put != nil:
+				tb.items[itemKey(ti.Put.Item)] = clone(ti.Put.Item)
+			case ti.Update != nil:
+				if _, _, err := tb.update(*ti.Update); err != nil {
+					return nil, err
+				}
+			case ti.Delete != nil:
+				delete(tb.items, itemKey(ti.Delete.Key))
+			}
+		}
+		return map[string]any{}, nil
+	}
+	return nil, fmt.Errorf("operation %q is not supported", op)
+}
+
+// check evaluates req's condition against the stored item with key's PK and SK.
+func (tb *Table) check(req request, key map[string]value) error {
+	if req.ConditionExpression == "" {
+		return nil
+	}
+	item := tb.items[itemKey(key)]
+	ok, err := newParser(req.ConditionExpression, req, item).condition()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errConditionFailed
+	}
+	return nil
+}
+
+// update applies req's update expression, creating the item if needed, and returns it with the
+// names of the top level attributes it changed.
+func (tb *Table) update(req request) (map[string]value, []string, error) {
+	k := itemKey(req.Key)
+	original, ok := tb.items[k]
+	if !ok {
+		original = clone(req.Key)
+	}
+	updated := clone(original)
+	changed, err := newParser(req.UpdateExpression, req, original).update(updated)
+	if err != nil {
+		return nil, nil, err
+	}
+	tb.items[k] = updated
+	return updated, changed, nil
+}
+
+func (tb *Table) query(req request) (any, error) {
+	pk, sk := "PK", "SK"
+	if req.IndexName != "" {
+		idx, ok := tb.indexes[req.IndexName]
+		if !ok {
+			return nil, fmt.Errorf("index %q is not defined", req.IndexName)
+		}
+		pk, sk = idx.PK, idx.SK
+	}
+	candidates := []map[string]value{}
+	for _, item := range tb.items {
+		if _, ok := item[pk]; !ok {
+			continue
+		}
+		matches, err := newParser(req.KeyConditionExpression, req, item).condition()
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			candidates = append(candidates, item)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if c := compare(candidates[i][sk], candidates[j][sk]); c != 0 {
+			return c < 0
+		}
+		return itemKey(candidates[i]) < itemKey(candidates[j])
+	})
+	if req.ScanIndexForward != nil && !*req.ScanIndexForward {
+		for i, j := 0, len(candidates)-1; i < j; i, j = i+1, j-1 {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		}
+	}
+	if req.ExclusiveStartKey != nil {
+		start := itemKey(req.ExclusiveStartKey)
+		for i, item := range candidates {
+			if itemKey(item) == start {
+				candidates = candidates[i+1:]
+				break
+			}
+		}
+	}
+	resp := map[string]any{}
+	if req.Limit > 0 && len(candidates) > req.Limit {
+		candidates = candidates[:req.Limit]
+		last := candidates[len(candidates)-1]
+		lastKey := map[string]value{"PK": last["PK"], "SK": last["SK"]}
+		for _, name := range []string{pk, sk} {
+			if v, ok := last[name]; ok && name != "" {
+				lastKey[name] = v
+			}
+		}
+		resp["LastEvaluatedKey"] = lastKey
+	}
+	items := []map[string]value{}
+	for _, item := range candidates {
+		if req.FilterExpression != "" {
+			matches, err := newParser(req.FilterExpression, req, item).condition()
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	resp["Items"] = items
+	resp["Count"] = len(items)
+	resp["ScannedCount"] = len(candidates)
+	return resp, nil
+}
+
+func itemKey(item map[string]value) string {
+	return str(item["PK"]) + "\x00" + str(item["SK"])
+}
+
+func str(v value) string {
+	s, _ := v["S"].(string)
+	return s
+}
+
+func clone(item map[string]value) map[string]value {
+	data, _ := json.Marshal(item)
+	var c map[string]value
+	json.Unmarshal(data, &c)
+	return c
+}
+
+// equal reports whether two attribute values are the same, numbers are compared by value.
+func equal(a, b value) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if _, ok := a["N"]; ok {
+		if _, ok := b["N"]; ok {
+			return compare(a, b) == 0
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
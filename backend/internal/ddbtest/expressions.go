@@ -0,0 +1,554 @@
+package ddbtest
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// parser evaluates one expression against an item as it reads it.
+type parser struct {
+	tokens []string
+	pos    int
+	names  map[string]string
+	values map[string]value
+	item   map[string]value
+}
+
+func newParser(expr string, req request, item map[string]value) *parser {
+	return &parser{
+		tokens: tokenize(expr),
+		names:  req.ExpressionAttributeNames,
+		values: req.ExpressionAttributeValues,
+		item:   item,
+	}
+}
+
+func tokenize(expr string) []string {
+	var tokens []string
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("(),=+-", r):
+			tokens = append(tokens, string(r))
+			i++
+		case r == '<' || r == '>':
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else {
+				tokens = append(tokens, string(r))
+				i++
+			}
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("(),=+-<>", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		}
+	}
+	return tokens
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		return fmt.Errorf("expected %q, got %q in %v", tok, got, p.tokens)
+	}
+	return nil
+}
+
+// condition evaluates the whole expression as a condition, an empty one is true.
+func (p *parser) condition() (bool, error) {
+	if len(p.tokens) == 0 {
+		return true, nil
+	}
+	ok, err := p.or()
+	if err == nil && p.pos != len(p.tokens) {
+		err = fmt.Errorf("unexpected %q in %v", p.peek(), p.tokens)
+	}
+	return ok, err
+}
+
+func (p *parser) or() (bool, error) {
+	v, err := p.and()
+	for err == nil && p.peek() == "OR" {
+		p.next()
+		var w bool
+		w, err = p.and()
+		v = v || w
+	}
+	return v, err
+}
+
+func (p *parser) and() (bool, error) {
+	v, err := p.not()
+	for err == nil && p.peek() == "AND" {
+		p.next()
+		var w bool
+		w, err = p.not()
+		v = v && w
+	}
+	return v, err
+}
+
+func (p *parser) not() (bool, error) {
+	if p.peek() == "NOT" {
+		p.next()
+		v, err := p.not()
+		return !v, err
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (bool, error) {
+	switch p.peek() {
+	case "(":
+		p.next()
+		v, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		return v, p.expect(")")
+	case "attribute_exists", "attribute_not_exists":
+		fn := p.next()
+		if err := p.expect("("); err != nil {
+			return false, err
+		}
+		v := p.path(p.next())
+		if err := p.expect(")"); err != nil {
+			return false, err
+		}
+		return (v != nil) == (fn == "attribute_exists"), nil
+	case "begins_with", "contains":
+		fn := p.next()
+		if err := p.expect("("); err != nil {
+			return false, err
+		}
+		a, err := p.operand()
+		if err != nil {
+			return false, err
+		}
+		if err := p.expect(","); err != nil {
+			return false, err
+		}
+		b, err := p.operand()
+		if err != nil {
+			return false, err
+		}
+		if err := p.expect(")"); err != nil {
+			return false, err
+		}
+		if fn == "begins_with" {
+			return a != nil && b != nil && strings.HasPrefix(str(a), str(b)), nil
+		}
+		return contains(a, b), nil
+	}
+	a, err := p.operand()
+	if err != nil {
+		return false, err
+	}
+	switch op := p.next(); op {
+	case "=", "<>", "<", "<=", ">", ">=":
+		b, err := p.operand()
+		if err != nil {
+			return false, err
+		}
+		return compareOp(a, b, op), nil
+	case "IN":
+		if err := p.expect("("); err != nil {
+			return false, err
+		}
+		found := false
+		for {
+			b, err := p.operand()
+			if err != nil {
+				return false, err
+			}
+			found = found || equal(a, b)
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+		return found, p.expect(")")
+	case "BETWEEN":
+		lo, err := p.operand()
+		if err != nil {
+			return false, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return false, err
+		}
+		hi, err := p.operand()
+		if err != nil {
+			return false, err
+		}
+		return compareOp(a, lo, ">=") && compareOp(a, hi, "<="), nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q in %v", op, p.tokens)
+	}
+}
+
+// operand evaluates a path, value or function, nil means the attribute doesn't exist.
+func (p *parser) operand() (value, error) {
+	var v value
+	switch tok := p.next(); {
+	case tok == "size":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		a, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if a != nil {
+			v = value{"N": fmt.Sprint(size(a))}
+		}
+	case tok == "if_not_exists":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		v = p.path(p.next())
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		def, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if v == nil {
+			v = def
+		}
+	case strings.HasPrefix(tok, ":"):
+		var ok bool
+		if v, ok = p.values[tok]; !ok {
+			return nil, fmt.Errorf("value %q is not defined", tok)
+		}
+	default:
+		v = p.path(tok)
+	}
+	if op := p.peek(); op == "+" || op == "-" {
+		p.next()
+		w, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return arithmetic(v, w, op)
+	}
+	return v, nil
+}
+
+// path looks up a document path such as #0 or #0.#1 in the item.
+func (p *parser) path(tok string) value {
+	current := value{"M": toAny(p.item)}
+	for _, part := range strings.Split(tok, ".") {
+		name := part
+		if strings.HasPrefix(part, "#") {
+			name = p.names[part]
+		}
+		m, ok := current["M"].(map[string]any)
+		if !ok {
+			return nil
+		}
+		next, ok := m[name].(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// pathNames resolves a document path to attribute names.
+func (p *parser) pathNames(tok string) []string {
+	parts := strings.Split(tok, ".")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "#") {
+			parts[i] = p.names[part]
+		}
+	}
+	return parts
+}
+
+// update applies the expression's SET, ADD, REMOVE and DELETE actions to item. Operands are read
+// from the item as it was before the update.
+func (p *parser) update(item map[string]value) ([]string, error) {
+	var changed []string
+	action := ""
+	for p.pos < len(p.tokens) {
+		switch tok := p.peek(); tok {
+		case "SET", "ADD", "REMOVE", "DELETE":
+			action = p.next()
+			continue
+		case ",":
+			p.next()
+			continue
+		}
+		target := p.next()
+		names := p.pathNames(target)
+		changed = append(changed, names[0])
+		switch action {
+		case "SET":
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			v, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			setPath(item, names, v)
+		case "ADD":
+			v, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			current := p.path(target)
+			if current == nil {
+				setPath(item, names, v)
+				continue
+			}
+			if _, ok := v["N"]; ok {
+				sum, err := arithmetic(current, v, "+")
+				if err != nil {
+					return nil, err
+				}
+				setPath(item, names, sum)
+				continue
+			}
+			setPath(item, names, setUnion(current, v))
+		case "REMOVE":
+			setPath(item, names, nil)
+		case "DELETE":
+			v, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			if current := p.path(target); current != nil {
+				setPath(item, names, setDifference(current, v))
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in %v", target, p.tokens)
+		}
+	}
+	return changed, nil
+}
+
+func setPath(item map[string]value, names []string, v value) {
+	if len(names) == 1 {
+		if v == nil {
+			delete(item, names[0])
+		} else {
+			item[names[0]] = v
+		}
+		return
+	}
+	parent, ok := item[names[0]]
+	if !ok {
+		return
+	}
+	m, ok := parent["M"].(map[string]any)
+	if !ok {
+		return
+	}
+	child := map[string]value{}
+	for k, c := range m {
+		child[k] = c.(map[string]any)
+	}
+	setPath(child, names[1:], v)
+	item[names[0]] = value{"M": toAny(child)}
+}
+
+func toAny(item map[string]value) map[string]any {
+	m := make(map[string]any, len(item))
+	for k, v := range item {
+		m[k] = v
+	}
+	return m
+}
+
+func number(v value) (*big.Float, bool) {
+	s, ok := v["N"].(string)
+	if !ok {
+		return nil, false
+	}
+	f, _, err := big.ParseFloat(s, 10, 100, big.ToNearestEven)
+	return f, err == nil
+}
+
+func arithmetic(a, b value, op string) (value, error) {
+	x, ok := number(a)
+	y, ok2 := number(b)
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("%s needs two numbers, got %v and %v", op, a, b)
+	}
+	if op == "+" {
+		x.Add(x, y)
+	} else {
+		x.Sub(x, y)
+	}
+	return value{"N": x.Text('f', -1)}, nil
+}
+
+// compare orders two values of the same scalar type, numbers by value and the rest as strings.
+func compare(a, b value) int {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x.Cmp(y)
+		}
+	}
+	return strings.Compare(scalar(a), scalar(b))
+}
+
+func scalar(v value) string {
+	for _, t := range []string{"S", "N", "B"} {
+		if s, ok := v[t].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func sameType(a, b value) bool {
+	for t := range a {
+		_, ok := b[t]
+		return ok
+	}
+	return false
+}
+
+func compareOp(a, b value, op string) bool {
+	if op == "<>" {
+		return !equal(a, b)
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	if op == "=" {
+		return equal(a, b)
+	}
+	if !sameType(a, b) {
+		return false
+	}
+	c := compare(a, b)
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func size(v value) int {
+	switch {
+	case v["S"] != nil:
+		return len(v["S"].(string))
+	case v["L"] != nil:
+		return len(v["L"].([]any))
+	case v["M"] != nil:
+		return len(v["M"].(map[string]any))
+	case v["SS"] != nil:
+		return len(v["SS"].([]any))
+	case v["NS"] != nil:
+		return len(v["NS"].([]any))
+	}
+	return 0
+}
+
+func contains(a, b value) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if s, ok := a["S"].(string); ok {
+		return strings.Contains(s, str(b))
+	}
+	for _, t := range []string{"SS", "NS"} {
+		if members, ok := a[t].([]any); ok {
+			for _, m := range members {
+				if m == scalar(b) {
+					return true
+				}
+			}
+		}
+	}
+	if list, ok := a["L"].([]any); ok {
+		for _, m := range list {
+			if equal(m.(map[string]any), b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func setUnion(a, b value) value {
+	for _, t := range []string{"SS", "NS"} {
+		x, ok := a[t].([]any)
+		y, ok2 := b[t].([]any)
+		if !ok || !ok2 {
+			continue
+		}
+		seen := map[any]bool{}
+		union := []any{}
+		for _, m := range append(append([]any{}, x...), y...) {
+			if !seen[m] {
+				seen[m] = true
+				union = append(union, m)
+			}
+		}
+		return value{t: union}
+	}
+	return b
+}
+
+func setDifference(a, b value) value {
+	for _, t := range []string{"SS", "NS"} {
+		x, ok := a[t].([]any)
+		y, ok2 := b[t].([]any)
+		if !ok || !ok2 {
+			continue
+		}
+		remove := map[any]bool{}
+		for _, m := range y {
+			remove[m] = true
+		}
+		rest := []any{}
+		for _, m := range x {
+			if !remove[m] {
+				rest = append(rest, m)
+			}
+		}
+		if len(rest) == 0 {
+			return nil
+		}
+		return value{t: rest}
+	}
+	return a
+}
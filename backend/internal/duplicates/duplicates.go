@@ -0,0 +1,147 @@
+package duplicates
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/repository"
+)
+
+const (
+	// signatureSize is the number of hashes in a signature, the similarity of two signatures is
+	// within about 0.06 of the real similarity of their texts.
+	signatureSize = 128
+	// shingleSize is the number of consecutive words compared.
+	shingleSize = 3
+	// Threshold is the similarity at which two posts are reported as possible duplicates.
+	Threshold = 0.7
+)
+
+// Signature returns the MinHash signature of a post's title and description. Texts are compared
+// as sets of shingles, runs of consecutive words, so reordered paragraphs and small edits still
+// match.
+func Signature(title, description string) []uint32 {
+	words := strings.FieldsFunc(strings.ToLower(title+" "+description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+	var shingles []uint64
+	for i := 0; i+shingleSize <= len(words) || i == 0; i++ {
+		h := fnv.New64a()
+		for _, w := range words[i:min(i+shingleSize, len(words))] {
+			h.Write([]byte(w))
+			h.Write([]byte{0})
+		}
+		shingles = append(shingles, h.Sum64())
+	}
+
+	signature := make([]uint32, signatureSize)
+	for i := range signature {
+		signature[i] = ^uint32(0)
+	}
+	for _, s := range shingles {
+		for i := range signature {
+			if h := uint32(mix(s^seeds[i]) >> 32); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// Similarity estimates the similarity of the texts two signatures were made from.
+func Similarity(a, b []uint32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var same int
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// seeds give each position in a signature its own hash function.
+var seeds = func() [signatureSize]uint64 {
+	var s [signatureSize]uint64
+	x := uint64(0x2545f4914f6cdd1d)
+	for i := range s {
+		x = mix(x + uint64(i))
+		s[i] = x
+	}
+	return s
+}()
+
+// mix is the splitmix64 finaliser.
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// Detector finds a company's posts that are nearly the same as a new or edited one.
+type Detector struct {
+	companies repository.Companies
+}
+
+func NewDetector(companies repository.Companies) Detector {
+	return Detector{companies: companies}
+}
+
+// Find returns the other posts of post's company that are possible duplicates of it, most similar
+// first. post.MinHash must be set. Unpaid, cancelled and removed posts are ignored. Expired, paused
+// and filled ones aren't, so re-posting a role instead of renewing, resuming or reopening it is
+// caught.
+func (d Detector) Find(ctx context.Context, post models.JobPostItem) ([]models.PossibleDuplicate, error) {
+	if post.CompanyID == "" || len(post.MinHash) == 0 {
+		return nil, nil
+	}
+	filter := expression.Name("status").In(
+		expression.Value(models.Active),
+		expression.Value(models.Expired),
+		expression.Value(models.PendingReview),
+		expression.Value(models.Paused),
+		expression.Value(models.Filled),
+	)
+	others, err := d.companies.JobPosts(ctx, post.CompanyID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error getting company job posts: %w", err)
+	}
+	var found []models.PossibleDuplicate
+	for _, other := range others {
+		if other.JobID == post.JobID {
+			continue
+		}
+		// Posts from before signatures were stored get one now.
+		signature := other.MinHash
+		if len(signature) == 0 {
+			signature = Signature(other.Title, other.Description)
+		}
+		similarity := Similarity(post.MinHash, signature)
+		if similarity < Threshold {
+			continue
+		}
+		found = append(found, models.PossibleDuplicate{
+			JobID:      other.JobID,
+			Title:      other.Title,
+			Status:     other.Status,
+			CreatedAt:  other.CreatedAt,
+			Similarity: similarity,
+		})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Similarity > found[j].Similarity
+	})
+	return found, nil
+}
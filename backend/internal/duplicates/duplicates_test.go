@@ -0,0 +1,135 @@
+package duplicates
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/ddbtest"
+	"github.com/josepheid/upfront/internal/repository"
+)
+
+const (
+	title       = "Senior Backend Engineer"
+	description = `We are looking for a senior backend engineer to join our payments team in London.
+You will design and build the services that move money for thousands of small businesses, working
+in Go on AWS with DynamoDB and Lambda. You will review code, mentor other engineers and help us
+decide what to build next. We offer a salary of 90,000 to 110,000 pounds, 30 days of holiday and a
+generous pension. Applications close at the end of the month.`
+)
+
+func TestSignature(t *testing.T) {
+	a := Signature(title, description)
+	if len(a) != signatureSize {
+		t.Fatalf("len(Signature()) = %d, want %d", len(a), signatureSize)
+	}
+	if b := Signature(title, description); Similarity(a, b) != 1 {
+		t.Error("Signature() is not deterministic")
+	}
+	if Signature("", "") != nil || Signature("!!", "--") != nil {
+		t.Error("Signature() of a text with no words is not nil")
+	}
+	if got := Signature("Go", ""); len(got) != signatureSize {
+		t.Errorf("Signature() of a text shorter than a shingle has %d hashes", len(got))
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	original := Signature(title, description)
+	tests := []struct {
+		name     string
+		title    string
+		desc     string
+		min, max float64
+	}{
+		{name: "case and punctuation", title: "SENIOR backend-engineer!", desc: strings.ReplaceAll(strings.ToUpper(description), ",", " - "), min: 1, max: 1},
+		{name: "small edit", title: title, desc: description + " We sponsor visas.", min: Threshold, max: 1},
+		{name: "salary changed", title: title, desc: strings.Replace(description, "90,000 to 110,000", "95,000 to 120,000", 1), min: Threshold, max: 1},
+		{name: "different role", title: "Office Manager", desc: "Run our office in Leeds, organise events, look after suppliers and keep the kitchen stocked. Part time, three days a week.", min: 0, max: 0.1},
+		{name: "same title only", title: title, desc: "A short post.", min: 0, max: Threshold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(original, Signature(tt.title, tt.desc))
+			if got < tt.min || got > tt.max {
+				t.Errorf("Similarity() = %.2f, want between %.2f and %.2f", got, tt.min, tt.max)
+			}
+		})
+	}
+
+	if got := Similarity(original, nil); got != 0 {
+		t.Errorf("Similarity() with no signature = %v, want 0", got)
+	}
+	if got := Similarity(original, original[:10]); got != 0 {
+		t.Errorf("Similarity() of different lengths = %v, want 0", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	ctx := context.Background()
+	_, ddbc := ddbtest.New(t, ddbtest.Index{Name: "companyIndex", PK: "companyID", SK: "createdAt"})
+	detector := NewDetector(repository.NewCompanies(ddbc, "upfront"))
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	put := func(id, companyID string, status models.Status, desc string, withSignature bool) {
+		t.Helper()
+		item := models.JobPostItem{
+			PK:        models.FormatPK(id),
+			SK:        now.Format(time.RFC3339),
+			JobID:     id,
+			Status:    status,
+			CreatedAt: now.Add(-time.Hour).Format(time.RFC3339),
+		}
+		item.CompanyID = companyID
+		item.Title = title
+		item.Description = desc
+		if withSignature {
+			item.MinHash = Signature(title, desc)
+		}
+		data, err := attributevalue.MarshalMap(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ddbc.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("upfront"), Item: data}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("active", "acme", models.Active, description, true)
+	put("edited", "acme", models.Expired, description+" We sponsor visas.", true)
+	put("old", "acme", models.PendingReview, description, false)
+	put("paused", "acme", models.Paused, description, true)
+	put("filled", "acme", models.Filled, description, true)
+	put("unrelated", "acme", models.Active, "Run our office in Leeds and keep the kitchen stocked.", true)
+	put("cancelled", "acme", models.Cancelled, description, true)
+	put("unpaid", "acme", models.PendingPayment, description, true)
+	put("draft", "acme", models.Draft, description, true)
+	put("elsewhere", "globex", models.Active, description, true)
+
+	post := models.JobPostItem{JobID: "active", MinHash: Signature(title, description)}
+	post.CompanyID = "acme"
+	found, err := detector.Find(ctx, post)
+	if err != nil {
+		t.Fatalf("Find() = %v", err)
+	}
+	got := map[string]float64{}
+	for _, d := range found {
+		got[d.JobID] = d.Similarity
+	}
+	// The post itself, other companies' posts and unpaid, cancelled and draft posts are left out.
+	if len(got) != 4 || got["old"] != 1 || got["paused"] != 1 || got["filled"] != 1 || got["edited"] < Threshold {
+		t.Errorf("Find() = %+v, want old, paused, filled and edited", found)
+	}
+	if len(found) == 4 && found[3].JobID != "edited" {
+		t.Errorf("Find() = %+v, want the most similar first", found)
+	}
+
+	post.CompanyID = ""
+	if found, err := detector.Find(ctx, post); err != nil || found != nil {
+		t.Errorf("Find() for a post without a company = %v, %v, want nothing", found, err)
+	}
+}
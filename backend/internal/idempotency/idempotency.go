@@ -0,0 +1,202 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Header is the request header clients use to make a request safe to retry.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses that were replayed from a stored record.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// MaxKeyLength matches the limit Stripe puts on its own idempotency keys.
+const MaxKeyLength = 255
+
+var (
+	// ErrKeyReused is returned when a key is sent again with a different request body.
+	ErrKeyReused = errors.New("idempotency key was used with a different request")
+	// ErrInProgress is returned when the first request with a key hasn't finished yet.
+	ErrInProgress = errors.New("a request with this idempotency key is in progress")
+)
+
+const (
+	statusInProgress = "InProgress"
+	statusComplete   = "Complete"
+)
+
+// Record is a stored response, kept in the upfront table until its TTL passes.
+type Record struct {
+	PK          string `dynamodbav:"PK"`
+	SK          string `dynamodbav:"SK"`
+	RequestHash string `dynamodbav:"requestHash"`
+	Status      string `dynamodbav:"status"`
+	StatusCode  int    `dynamodbav:"statusCode"`
+	Body        []byte `dynamodbav:"body"`
+	CreatedAt   string `dynamodbav:"createdAt"`
+	TTL         int64  `dynamodbav:"ttl"`
+}
+
+// Replay writes the stored response.
+func (r Record) Replay(w http.ResponseWriter) {
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(r.StatusCode)
+	w.Write(r.Body)
+}
+
+type Store struct {
+	ddbc      *dynamodb.Client
+	tableName string
+	ttl       time.Duration
+}
+
+// NewStore creates a store whose records expire after ttl.
+func NewStore(ddbc *dynamodb.Client, tableName string, ttl time.Duration) Store {
+	return Store{
+		ddbc:      ddbc,
+		tableName: tableName,
+		ttl:       ttl,
+	}
+}
+
+// Hash fingerprints a request body so a reused key can be detected.
+func Hash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// CallerKey scopes a client's key to the caller sending it, so two callers sending the same key
+// never share a stored response or a Stripe request. The result is what Begin, Complete, Release
+// and Stripe are given, it is never longer than MaxKeyLength.
+func CallerKey(caller, key string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(caller) + "\n" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Begin claims key for a request with the given hash. If the key was already claimed the existing
+// record is returned when it is complete, otherwise ErrInProgress or ErrKeyReused is returned. A nil
+// record and error means the caller owns the key and must call Complete or Release.
+func (s Store) Begin(ctx context.Context, scope, key, requestHash string) (*Record, error) {
+	now := time.Now()
+	record := Record{
+		PK:          formatPK(scope, key),
+		SK:          "idempotency",
+		RequestHash: requestHash,
+		Status:      statusInProgress,
+		CreatedAt:   now.Format(time.RFC3339),
+		TTL:         now.Add(s.ttl).Unix(),
+	}
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling idempotency record: %w", err)
+	}
+	// DynamoDB removes expired items lazily, so an expired record can still be claimed.
+	cond := expression.AttributeNotExists(expression.Name("PK")).
+		Or(expression.Name("ttl").LessThan(expression.Value(now.Unix())))
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = s.ddbc.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(s.tableName),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err == nil {
+		return nil, nil
+	}
+	var conditionFailed *types.ConditionalCheckFailedException
+	if !errors.As(err, &conditionFailed) {
+		return nil, fmt.Errorf("error putting idempotency record: %w", err)
+	}
+
+	existing, err := s.get(ctx, record.PK)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case existing.RequestHash != requestHash:
+		return nil, ErrKeyReused
+	case existing.Status != statusComplete:
+		return nil, ErrInProgress
+	}
+	return &existing, nil
+}
+
+// Complete stores the response for key so retries get the same answer.
+func (s Store) Complete(ctx context.Context, scope, key string, statusCode int, body []byte) error {
+	upd := expression.
+		Set(expression.Name("status"), expression.Value(statusComplete)).
+		Set(expression.Name("statusCode"), expression.Value(statusCode)).
+		Set(expression.Name("body"), expression.Value(body))
+	expr, err := expression.NewBuilder().WithUpdate(upd).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = s.ddbc.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.tableName),
+		Key:                       itemKey(formatPK(scope, key)),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+	})
+	if err != nil {
+		return fmt.Errorf("error completing idempotency record: %w", err)
+	}
+	return nil
+}
+
+// Release gives up a claimed key after a failure, so the client can retry with it.
+func (s Store) Release(ctx context.Context, scope, key string) error {
+	_, err := s.ddbc.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       itemKey(formatPK(scope, key)),
+	})
+	if err != nil {
+		return fmt.Errorf("error releasing idempotency record: %w", err)
+	}
+	return nil
+}
+
+func (s Store) get(ctx context.Context, pk string) (Record, error) {
+	out, err := s.ddbc.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            itemKey(pk),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return Record{}, fmt.Errorf("error getting idempotency record: %w", err)
+	}
+	var record Record
+	if err := attributevalue.UnmarshalMap(out.Item, &record); err != nil {
+		return Record{}, fmt.Errorf("error unmarshalling idempotency record: %w", err)
+	}
+	return record, nil
+}
+
+func formatPK(scope, key string) string {
+	return fmt.Sprintf("idempotency/%s/%s", scope, key)
+}
+
+func itemKey(pk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: pk},
+		"SK": &types.AttributeValueMemberS{Value: "idempotency"},
+	}
+}
@@ -0,0 +1,136 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josepheid/upfront/internal/ddbtest"
+)
+
+func newStore(t *testing.T, ttl time.Duration) Store {
+	t.Helper()
+	_, ddbc := ddbtest.New(t)
+	return NewStore(ddbc, "upfront", ttl)
+}
+
+func TestBeginClaimsNewKey(t *testing.T) {
+	store := newStore(t, time.Hour)
+	record, err := store.Begin(context.Background(), "checkout", "key", Hash([]byte(`{}`)))
+	if record != nil || err != nil {
+		t.Fatalf("Begin() = %v, %v, want the key claimed", record, err)
+	}
+}
+
+func TestBeginInProgress(t *testing.T) {
+	store := newStore(t, time.Hour)
+	ctx := context.Background()
+	hash := Hash([]byte(`{"a":1}`))
+	if _, err := store.Begin(ctx, "checkout", "key", hash); err != nil {
+		t.Fatalf("first Begin() = %v", err)
+	}
+	if _, err := store.Begin(ctx, "checkout", "key", hash); !errors.Is(err, ErrInProgress) {
+		t.Errorf("second Begin() error = %v, want ErrInProgress", err)
+	}
+}
+
+func TestBeginKeyReused(t *testing.T) {
+	store := newStore(t, time.Hour)
+	ctx := context.Background()
+	if _, err := store.Begin(ctx, "checkout", "key", Hash([]byte(`{"a":1}`))); err != nil {
+		t.Fatalf("first Begin() = %v", err)
+	}
+	if _, err := store.Begin(ctx, "checkout", "key", Hash([]byte(`{"a":2}`))); !errors.Is(err, ErrKeyReused) {
+		t.Errorf("Begin() with a different body error = %v, want ErrKeyReused", err)
+	}
+}
+
+func TestScopesAreSeparate(t *testing.T) {
+	store := newStore(t, time.Hour)
+	ctx := context.Background()
+	if _, err := store.Begin(ctx, "checkout", "key", Hash([]byte(`{"a":1}`))); err != nil {
+		t.Fatalf("Begin() = %v", err)
+	}
+	if record, err := store.Begin(ctx, "renew", "key", Hash([]byte(`{"a":2}`))); record != nil || err != nil {
+		t.Errorf("Begin() in another scope = %v, %v, want the key claimed", record, err)
+	}
+}
+
+func TestCompleteThenReplay(t *testing.T) {
+	store := newStore(t, time.Hour)
+	ctx := context.Background()
+	hash := Hash([]byte(`{"a":1}`))
+	if _, err := store.Begin(ctx, "checkout", "key", hash); err != nil {
+		t.Fatalf("Begin() = %v", err)
+	}
+	if err := store.Complete(ctx, "checkout", "key", http.StatusCreated, []byte(`{"url":"https://stripe"}`)); err != nil {
+		t.Fatalf("Complete() = %v", err)
+	}
+	record, err := store.Begin(ctx, "checkout", "key", hash)
+	if err != nil || record == nil {
+		t.Fatalf("Begin() after Complete = %v, %v, want the stored record", record, err)
+	}
+	w := httptest.NewRecorder()
+	record.Replay(w)
+	if w.Code != http.StatusCreated || w.Body.String() != `{"url":"https://stripe"}` || w.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("Replay() wrote %d %q with headers %v", w.Code, w.Body.String(), w.Header())
+	}
+	if _, err := store.Begin(ctx, "checkout", "key", Hash([]byte(`{"a":2}`))); !errors.Is(err, ErrKeyReused) {
+		t.Errorf("Begin() with a different body after Complete error = %v, want ErrKeyReused", err)
+	}
+}
+
+func TestReleaseLetsKeyBeClaimedAgain(t *testing.T) {
+	store := newStore(t, time.Hour)
+	ctx := context.Background()
+	if _, err := store.Begin(ctx, "checkout", "key", Hash([]byte(`{"a":1}`))); err != nil {
+		t.Fatalf("Begin() = %v", err)
+	}
+	if err := store.Release(ctx, "checkout", "key"); err != nil {
+		t.Fatalf("Release() = %v", err)
+	}
+	if record, err := store.Begin(ctx, "checkout", "key", Hash([]byte(`{"a":2}`))); record != nil || err != nil {
+		t.Errorf("Begin() after Release = %v, %v, want the key claimed", record, err)
+	}
+}
+
+func TestExpiredRecordCanBeClaimed(t *testing.T) {
+	// A negative TTL stands in for a record DynamoDB hasn't deleted yet.
+	store := newStore(t, -time.Minute)
+	ctx := context.Background()
+	if _, err := store.Begin(ctx, "checkout", "key", Hash([]byte(`{"a":1}`))); err != nil {
+		t.Fatalf("Begin() = %v", err)
+	}
+	if record, err := store.Begin(ctx, "checkout", "key", Hash([]byte(`{"a":2}`))); record != nil || err != nil {
+		t.Errorf("Begin() on an expired record = %v, %v, want the key claimed", record, err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash([]byte("a")) != Hash([]byte("a")) {
+		t.Error("Hash() differs for the same body")
+	}
+	if Hash([]byte("a")) == Hash([]byte("b")) {
+		t.Error("Hash() is the same for different bodies")
+	}
+}
+
+func TestCallerKey(t *testing.T) {
+	key := CallerKey("owner@acme.com", "key")
+	if key == CallerKey("someone@else.com", "key") {
+		t.Error("CallerKey() is the same for two callers sending the same key")
+	}
+	if key == CallerKey("owner@acme.com", "other") {
+		t.Error("CallerKey() is the same for two keys from one caller")
+	}
+	if key != CallerKey("Owner@Acme.com", "key") {
+		t.Error("CallerKey() depends on the case of the caller's email")
+	}
+	if long := CallerKey("owner@acme.com", strings.Repeat("k", MaxKeyLength)); len(long) > MaxKeyLength {
+		t.Errorf("CallerKey() is %d characters, want at most %d", len(long), MaxKeyLength)
+	}
+}
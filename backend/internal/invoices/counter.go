@@ -0,0 +1,164 @@
+package invoices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxAttempts is how many times Number tries to take the next number while other purchases are
+// being numbered at the same time.
+const maxAttempts = 10
+
+// Counter hands out invoice numbers from a counter item in the upfront table. Each number is
+// recorded against the purchase it was given to in the same transaction that takes it from the
+// counter, so numbers run without gaps and a purchase keeps its number however often it is issued.
+type Counter struct {
+	ddbc      *dynamodb.Client
+	tableName string
+}
+
+func NewCounter(ddbc *dynamodb.Client, tableName string) Counter {
+	return Counter{
+		ddbc:      ddbc,
+		tableName: tableName,
+	}
+}
+
+var counterKey = map[string]types.AttributeValue{
+	"PK": &types.AttributeValueMemberS{Value: "counter/invoice"},
+	"SK": &types.AttributeValueMemberS{Value: "counter"},
+}
+
+func numberKey(sessionID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: "invoice/" + sessionID},
+		"SK": &types.AttributeValueMemberS{Value: "number"},
+	}
+}
+
+// Number returns the invoice number of the purchase made with sessionID, e.g. "UPF-000042". The
+// first call takes the next number from the counter, later ones return the same number.
+func (c Counter) Number(ctx context.Context, sessionID string) (string, error) {
+	for range maxAttempts {
+		number, ok, err := c.assigned(ctx, sessionID)
+		if err != nil || ok {
+			return number, err
+		}
+
+		current, err := c.current(ctx)
+		if err != nil {
+			return "", err
+		}
+		// The counter only moves on if nobody else took the number first, and the purchase only
+		// gets it if it has no number yet.
+		counterCond := expression.Name("value").Equal(expression.Value(current))
+		if current == 0 {
+			counterCond = expression.AttributeNotExists(expression.Name("value"))
+		}
+		counterExpr, err := expression.NewBuilder().
+			WithCondition(counterCond).
+			WithUpdate(expression.Set(expression.Name("value"), expression.Value(current+1))).
+			Build()
+		if err != nil {
+			return "", fmt.Errorf("error building expression: %w", err)
+		}
+		numberExpr, err := expression.NewBuilder().
+			WithCondition(expression.AttributeNotExists(expression.Name("PK"))).
+			Build()
+		if err != nil {
+			return "", fmt.Errorf("error building expression: %w", err)
+		}
+		number = format(current + 1)
+		item, err := attributevalue.MarshalMap(struct {
+			Number string `dynamodbav:"number"`
+		}{Number: number})
+		if err != nil {
+			return "", fmt.Errorf("error marshalling invoice number: %w", err)
+		}
+		for k, v := range numberKey(sessionID) {
+			item[k] = v
+		}
+
+		_, err = c.ddbc.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: []types.TransactWriteItem{
+				{Update: &types.Update{
+					TableName:                 aws.String(c.tableName),
+					Key:                       counterKey,
+					ConditionExpression:       counterExpr.Condition(),
+					ExpressionAttributeNames:  counterExpr.Names(),
+					ExpressionAttributeValues: counterExpr.Values(),
+					UpdateExpression:          counterExpr.Update(),
+				}},
+				{Put: &types.Put{
+					TableName:                 aws.String(c.tableName),
+					Item:                      item,
+					ConditionExpression:       numberExpr.Condition(),
+					ExpressionAttributeNames:  numberExpr.Names(),
+					ExpressionAttributeValues: numberExpr.Values(),
+				}},
+			},
+		})
+		var cancelled *types.TransactionCanceledException
+		if errors.As(err, &cancelled) {
+			// Another purchase took the number, or another request numbered this one, look again.
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error taking invoice number: %w", err)
+		}
+		return number, nil
+	}
+	return "", fmt.Errorf("error taking invoice number: counter still busy after %d attempts", maxAttempts)
+}
+
+// assigned returns the number already given to the purchase made with sessionID, if there is one.
+func (c Counter) assigned(ctx context.Context, sessionID string) (string, bool, error) {
+	out, err := c.ddbc.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(c.tableName),
+		Key:            numberKey(sessionID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("error getting invoice number: %w", err)
+	}
+	if out.Item == nil {
+		return "", false, nil
+	}
+	var assigned struct {
+		Number string `dynamodbav:"number"`
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &assigned); err != nil {
+		return "", false, fmt.Errorf("error unmarshalling invoice number: %w", err)
+	}
+	return assigned.Number, true, nil
+}
+
+// current returns the last number taken from the counter, 0 if none has been.
+func (c Counter) current(ctx context.Context) (int64, error) {
+	out, err := c.ddbc.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(c.tableName),
+		Key:            counterKey,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error getting invoice counter: %w", err)
+	}
+	var counter struct {
+		Value int64 `dynamodbav:"value"`
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &counter); err != nil {
+		return 0, fmt.Errorf("error unmarshalling invoice counter: %w", err)
+	}
+	return counter.Value, nil
+}
+
+func format(n int64) string {
+	return fmt.Sprintf("UPF-%06d", n)
+}
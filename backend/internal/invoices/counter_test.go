@@ -0,0 +1,75 @@
+package invoices
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/josepheid/upfront/internal/ddbtest"
+)
+
+func TestCounterNumber(t *testing.T) {
+	ctx := context.Background()
+	_, ddbc := ddbtest.New(t)
+	counter := NewCounter(ddbc, "upfront")
+
+	for i, session := range []string{"cs_1", "cs_2", "cs_3"} {
+		number, err := counter.Number(ctx, session)
+		if err != nil {
+			t.Fatalf("Number(%s) = %v", session, err)
+		}
+		if want := fmt.Sprintf("UPF-%06d", i+1); number != want {
+			t.Errorf("Number(%s) = %s, want %s", session, number, want)
+		}
+	}
+
+	// Issuing a purchase again, e.g. after the save that recorded its number failed, gives it the
+	// same number and doesn't use up another.
+	again, err := counter.Number(ctx, "cs_2")
+	if err != nil || again != "UPF-000002" {
+		t.Errorf("Number(cs_2) again = %s, %v, want UPF-000002", again, err)
+	}
+	next, err := counter.Number(ctx, "cs_4")
+	if err != nil || next != "UPF-000004" {
+		t.Errorf("Number(cs_4) = %s, %v, want UPF-000004", next, err)
+	}
+}
+
+func TestCounterNumberConcurrent(t *testing.T) {
+	ctx := context.Background()
+	_, ddbc := ddbtest.New(t)
+	counter := NewCounter(ddbc, "upfront")
+
+	// Each purchase is numbered twice at once, as when the recruiter's redirect and a retry race.
+	const purchases = 5
+	numbers := make([][2]string, purchases)
+	var wg sync.WaitGroup
+	for i := range purchases {
+		for j := range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				number, err := counter.Number(ctx, fmt.Sprintf("cs_%d", i))
+				if err != nil {
+					t.Errorf("Number(cs_%d) = %v", i, err)
+				}
+				numbers[i][j] = number
+			}()
+		}
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for i, pair := range numbers {
+		if pair[0] != pair[1] {
+			t.Errorf("cs_%d was given %s and %s", i, pair[0], pair[1])
+		}
+		seen[pair[0]] = true
+	}
+	for n := 1; n <= purchases; n++ {
+		if want := fmt.Sprintf("UPF-%06d", n); !seen[want] {
+			t.Errorf("%s was not given out, numbers = %v", want, numbers)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package invoices
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/blobstore"
+)
+
+// ContentType is the media type invoices are stored and served as.
+const ContentType = "application/pdf"
+
+// Seller is the business named on invoices.
+type Seller struct {
+	Name    string
+	Address []string
+	// VATNumber is left off the invoice when empty.
+	VATNumber string
+	// VATRate is the VAT included in prices, in percent.
+	VATRate int
+	// Email is the address invoices are sent from, it must be verified in SES.
+	Email string
+}
+
+// DefaultSeller is used for any INVOICE_ environment variables that aren't set.
+var DefaultSeller = Seller{
+	Name:    "Upfront",
+	VATRate: 20,
+	Email:   "josephceid@gmail.com",
+}
+
+// SellerFromEnv reads the seller from INVOICE_SELLER_NAME, INVOICE_SELLER_ADDRESS (lines separated
+// by "|"), INVOICE_VAT_NUMBER, INVOICE_VAT_RATE and INVOICE_SENDER_EMAIL.
+func SellerFromEnv() (Seller, error) {
+	s := DefaultSeller
+	if v := os.Getenv("INVOICE_SELLER_NAME"); v != "" {
+		s.Name = v
+	}
+	if v := os.Getenv("INVOICE_SELLER_ADDRESS"); v != "" {
+		s.Address = strings.Split(v, "|")
+	}
+	s.VATNumber = os.Getenv("INVOICE_VAT_NUMBER")
+	if v := os.Getenv("INVOICE_VAT_RATE"); v != "" {
+		rate, err := strconv.Atoi(v)
+		if err != nil || rate < 0 || rate > 100 {
+			return Seller{}, fmt.Errorf("environment variable INVOICE_VAT_RATE must be a percentage, got %q", v)
+		}
+		s.VATRate = rate
+	}
+	if v := os.Getenv("INVOICE_SENDER_EMAIL"); v != "" {
+		s.Email = v
+	}
+	return s, nil
+}
+
+// Invoice is the record of a single paid purchase.
+type Invoice struct {
+	Number       string
+	IssuedAt     time.Time
+	CompanyName  string
+	BilledTo     string
+	JobID        string
+	JobTitle     string
+	Kind         models.PurchaseKind
+	PlanType     models.PlanType
+	PlanDuration int
+	// Amount is the total paid in pence, including VAT.
+	Amount   int64
+	Currency models.Currency
+}
+
+// NewInvoice describes a paid purchase of item.
+func NewInvoice(number string, item models.JobPostItem, purchase models.Purchase, issuedAt time.Time) Invoice {
+	return Invoice{
+		Number:       number,
+		IssuedAt:     issuedAt,
+		CompanyName:  item.CompanyName,
+		BilledTo:     item.LoginEmail,
+		JobID:        item.JobID,
+		JobTitle:     item.Title,
+		Kind:         purchase.Kind,
+		PlanType:     purchase.PlanType,
+		PlanDuration: purchase.PlanDuration,
+		Amount:       purchase.Amount,
+		Currency:     purchase.Currency,
+	}
+}
+
+// Key is where an invoice's PDF is kept in the blob store.
+func Key(number string) string {
+	return fmt.Sprintf("invoices/%s.pdf", number)
+}
+
+// VAT splits a VAT inclusive amount in pence into its net and VAT parts.
+func VAT(amount int64, rate int) (net, vat int64) {
+	net = (amount*100 + int64(100+rate)/2) / int64(100+rate)
+	return net, amount - net
+}
+
+// Render lays the invoice out as a PDF.
+func Render(inv Invoice, seller Seller) []byte {
+	var lines []line
+	y := 780.0
+	add := func(x, size float64, bold bool, text string) {
+		lines = append(lines, line{x: x, y: y, size: size, bold: bold, text: text})
+	}
+	next := func(gap float64) { y -= gap }
+
+	add(50, 22, true, "INVOICE")
+	next(40)
+	add(50, 11, true, seller.Name)
+	for _, l := range seller.Address {
+		next(14)
+		add(50, 10, false, l)
+	}
+	if seller.VATNumber != "" {
+		next(14)
+		add(50, 10, false, "VAT number: "+seller.VATNumber)
+	}
+
+	next(30)
+	add(50, 10, true, "Invoice number")
+	add(200, 10, false, inv.Number)
+	next(14)
+	add(50, 10, true, "Date")
+	add(200, 10, false, inv.IssuedAt.Format("2 January 2006"))
+
+	next(30)
+	add(50, 10, true, "Billed to")
+	next(14)
+	add(50, 10, false, inv.CompanyName)
+	next(14)
+	add(50, 10, false, inv.BilledTo)
+
+	next(36)
+	add(50, 10, true, "Description")
+	add(450, 10, true, "Amount")
+	next(18)
+	add(50, 10, false, description(inv))
+	add(450, 10, false, money(inv.Amount, inv.Currency))
+	next(14)
+	add(50, 9, false, fmt.Sprintf("Job post: %s (%s)", inv.JobTitle, inv.JobID))
+
+	net, vat := VAT(inv.Amount, seller.VATRate)
+	next(36)
+	add(300, 10, false, "Subtotal")
+	add(450, 10, false, money(net, inv.Currency))
+	next(14)
+	add(300, 10, false, fmt.Sprintf("VAT at %d%%", seller.VATRate))
+	add(450, 10, false, money(vat, inv.Currency))
+	next(14)
+	add(300, 10, true, "Total paid")
+	add(450, 10, true, money(inv.Amount, inv.Currency))
+
+	next(50)
+	add(50, 9, false, "Paid in full by card. Thank you for posting with "+seller.Name+".")
+	return renderPDF(lines)
+}
+
+func description(inv Invoice) string {
+	switch inv.Kind {
+	case models.Renewal:
+		return fmt.Sprintf("%s plan renewal, %d days", inv.PlanType, inv.PlanDuration)
+	case models.Upgrade:
+		return fmt.Sprintf("Upgrade to %s plan, %d days remaining", inv.PlanType, inv.PlanDuration)
+	default:
+		return fmt.Sprintf("%s plan, %d days", inv.PlanType, inv.PlanDuration)
+	}
+}
+
+func money(pence int64, currency models.Currency) string {
+	amount := fmt.Sprintf("%d.%02d", pence/100, pence%100)
+	switch currency {
+	case models.GBP:
+		return "£" + amount
+	case models.EUR:
+		return "€" + amount
+	default:
+		return amount + " " + string(currency)
+	}
+}
+
+// Issuer numbers, stores and emails invoices.
+type Issuer struct {
+	counter Counter
+	blobs   blobstore.Store
+	ses     *ses.Client
+	seller  Seller
+}
+
+func NewIssuer(counter Counter, blobs blobstore.Store, sesClient *ses.Client, seller Seller) Issuer {
+	return Issuer{
+		counter: counter,
+		blobs:   blobs,
+		ses:     sesClient,
+		seller:  seller,
+	}
+}
+
+// Issue numbers the paid purchase at index i of item, stores its PDF and sets the purchase's
+// InvoiceNumber. The caller saves item and then emails the invoice. A purchase is given the same
+// number each time it is issued, so issuing again after a failed save doesn't use up another.
+func (iss Issuer) Issue(ctx context.Context, item *models.JobPostItem, i int, now time.Time) (Invoice, []byte, error) {
+	item.BillingHistory = item.Purchases()
+	if i < 0 || i >= len(item.BillingHistory) {
+		return Invoice{}, nil, fmt.Errorf("purchase %d not found", i)
+	}
+	purchase := &item.BillingHistory[i]
+	if purchase.Status != models.PurchasePaid {
+		return Invoice{}, nil, fmt.Errorf("purchase %s has not been paid", purchase.SessionID)
+	}
+	if purchase.InvoiceNumber != "" {
+		return Invoice{}, nil, fmt.Errorf("purchase %s already has invoice %s", purchase.SessionID, purchase.InvoiceNumber)
+	}
+
+	number, err := iss.counter.Number(ctx, purchase.SessionID)
+	if err != nil {
+		return Invoice{}, nil, err
+	}
+	inv := NewInvoice(number, *item, *purchase, now)
+	pdf := Render(inv, iss.seller)
+	if err := iss.blobs.Put(ctx, Key(number), ContentType, pdf); err != nil {
+		return Invoice{}, nil, err
+	}
+	purchase.InvoiceNumber = number
+	return inv, pdf, nil
+}
+
+// Get returns a stored invoice PDF.
+func (iss Issuer) Get(ctx context.Context, number string) ([]byte, error) {
+	return iss.blobs.Get(ctx, Key(number))
+}
+
+// Email sends the invoice to the recruiter as a PDF attachment.
+func (iss Issuer) Email(ctx context.Context, inv Invoice, pdf []byte) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fmt.Fprintf(&body, "From: %s\r\nTo: %s\r\nSubject: Your Upfront invoice %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%q\r\n\r\n",
+		iss.seller.Email, inv.BilledTo, inv.Number, mw.Boundary())
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	if err != nil {
+		return fmt.Errorf("error creating email body: %w", err)
+	}
+	fmt.Fprintf(text, "Thanks for your payment of %s for %q.\r\n\r\nYour invoice %s is attached.\r\n", money(inv.Amount, inv.Currency), inv.JobTitle, inv.Number)
+
+	filename := inv.Number + ".pdf"
+	attachment, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {fmt.Sprintf("%s; name=%q", ContentType, filename)},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", filename)},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating email attachment: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(pdf)
+	for len(encoded) > 76 {
+		fmt.Fprintf(attachment, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(attachment, "%s\r\n", encoded)
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("error closing email: %w", err)
+	}
+
+	_, err = iss.ses.SendRawEmail(ctx, &ses.SendRawEmailInput{
+		Source:       aws.String(iss.seller.Email),
+		Destinations: []string{inv.BilledTo},
+		RawMessage:   &types.RawMessage{Data: body.Bytes()},
+	})
+	if err != nil {
+		return fmt.Errorf("error sending invoice email via ses: %w", err)
+	}
+	return nil
+}
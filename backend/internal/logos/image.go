@@ -0,0 +1,105 @@
+package logos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+
+	// Registered so image.Decode accepts every type in ContentTypes.
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxBytes is the largest logo that can be uploaded.
+	MaxBytes = 2 << 20
+	// MaxDimension bounds the width and height of an upload, so a small file can't decode into a
+	// huge image.
+	MaxDimension = 4096
+	// ThumbnailContentType is the type every thumbnail is stored as.
+	ThumbnailContentType = "image/png"
+)
+
+// ContentTypes are the image types that can be uploaded.
+var ContentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// Sizes are the widths in pixels of the square thumbnails made from each logo, smallest first.
+var Sizes = []int{64, 128, 256}
+
+// ErrInvalidImage is returned when an upload isn't an acceptable image.
+var ErrInvalidImage = errors.New("invalid logo image")
+
+// ValidateUpload returns the issues with the type and size a client says it will upload, if any.
+// The upload itself is checked again by Thumbnails.
+func ValidateUpload(contentType string, size int64) []string {
+	var issues []string
+	if !allowed(contentType) {
+		issues = append(issues, fmt.Sprintf("contentType must be one of %v", ContentTypes))
+	}
+	if size <= 0 || size > MaxBytes {
+		issues = append(issues, fmt.Sprintf("contentLength must be between 1 and %d bytes", MaxBytes))
+	}
+	return issues
+}
+
+// Thumbnails decodes an uploaded logo and returns a PNG of each of Sizes, keyed by size. The image
+// is cropped to a centred square and re-encoded, which drops any metadata such as EXIF or XMP.
+func Thumbnails(data []byte) (map[int][]byte, error) {
+	if len(data) == 0 || len(data) > MaxBytes {
+		return nil, fmt.Errorf("%w: size must be between 1 and %d bytes, got %d", ErrInvalidImage, MaxBytes, len(data))
+	}
+	// The type is sniffed from the bytes, the Content-Type the client sent isn't trusted.
+	if contentType := http.DetectContentType(data); !allowed(contentType) {
+		return nil, fmt.Errorf("%w: content type %s is not allowed", ErrInvalidImage, contentType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension {
+		return nil, fmt.Errorf("%w: %dx%d is larger than %dx%d", ErrInvalidImage, config.Width, config.Height, MaxDimension, MaxDimension)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	square := centreSquare(src.Bounds())
+	if square.Empty() {
+		return nil, fmt.Errorf("%w: image is empty", ErrInvalidImage)
+	}
+
+	thumbnails := map[int][]byte{}
+	for _, size := range Sizes {
+		dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Src, nil)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, fmt.Errorf("error encoding %dpx thumbnail: %w", size, err)
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+	return thumbnails, nil
+}
+
+// centreSquare is the largest square in the middle of r.
+func centreSquare(r image.Rectangle) image.Rectangle {
+	side := min(r.Dx(), r.Dy())
+	x := r.Min.X + (r.Dx()-side)/2
+	y := r.Min.Y + (r.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+func allowed(contentType string) bool {
+	for _, t := range ContentTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package logos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/blobstore"
+	"github.com/josepheid/upfront/internal/repository"
+)
+
+// UploadPrefix is where clients upload logos, uploads are deleted once processed.
+const UploadPrefix = "uploads/logos/"
+
+// UploadKey is where a company's logo upload is stored until it is processed.
+func UploadKey(companyID, uploadID string) string {
+	return UploadPrefix + companyID + "/" + uploadID
+}
+
+// ParseUploadKey returns the company and upload IDs from an UploadKey.
+func ParseUploadKey(key string) (companyID, uploadID string, ok bool) {
+	companyID, uploadID, ok = strings.Cut(strings.TrimPrefix(key, UploadPrefix), "/")
+	if !strings.HasPrefix(key, UploadPrefix) || companyID == "" || uploadID == "" || strings.Contains(uploadID, "/") {
+		return "", "", false
+	}
+	return companyID, uploadID, ok
+}
+
+// ThumbnailKey is where a processed thumbnail is stored, only keys under "logos/" are public.
+func ThumbnailKey(companyID, uploadID string, size int) string {
+	return fmt.Sprintf("logos/%s/%s/%d.png", companyID, uploadID, size)
+}
+
+// BaseURLFromEnv reads LOGO_BASE_URL, the public URL that thumbnail keys are joined to, e.g. the
+// bucket's URL or a local MinIO's http://localhost:9000/logos.
+func BaseURLFromEnv() (string, error) {
+	baseURL := strings.TrimSuffix(os.Getenv("LOGO_BASE_URL"), "/")
+	if baseURL == "" {
+		return "", errors.New("environment variable LOGO_BASE_URL is not set")
+	}
+	return baseURL, nil
+}
+
+// saveAttempts is how many times a company is reread and saved when it changes concurrently.
+const saveAttempts = 3
+
+// Processor turns uploaded logos into the thumbnails that companies and their posts link to.
+type Processor struct {
+	blobs     blobstore.Store
+	companies repository.Companies
+	baseURL   string
+}
+
+func NewProcessor(blobs blobstore.Store, companies repository.Companies, baseURL string) Processor {
+	return Processor{
+		blobs:     blobs,
+		companies: companies,
+		baseURL:   baseURL,
+	}
+}
+
+// Process makes thumbnails of the upload at key and sets them as its company's logo. Uploads
+// that aren't acceptable images are deleted and ErrInvalidImage is returned, so they aren't retried.
+func (p Processor) Process(ctx context.Context, key string, now time.Time) error {
+	companyID, uploadID, ok := ParseUploadKey(key)
+	if !ok {
+		return fmt.Errorf("%s is not a logo upload key", key)
+	}
+	data, err := p.blobs.Get(ctx, key)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		// Already processed by an earlier delivery of the same event.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	thumbnails, err := Thumbnails(data)
+	if errors.Is(err, ErrInvalidImage) {
+		if deleteErr := p.blobs.Delete(ctx, key); deleteErr != nil {
+			return deleteErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	var logo []models.LogoThumbnail
+	for _, size := range Sizes {
+		thumbnailKey := ThumbnailKey(companyID, uploadID, size)
+		if err := p.blobs.Put(ctx, thumbnailKey, ThumbnailContentType, thumbnails[size]); err != nil {
+			return err
+		}
+		logo = append(logo, models.LogoThumbnail{Size: size, URL: p.baseURL + "/" + thumbnailKey})
+	}
+
+	company, err := p.setLogo(ctx, companyID, logo, now)
+	if err != nil {
+		return err
+	}
+	posts, err := p.companies.JobPosts(ctx, companyID, expression.ConditionBuilder{})
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if err := p.companies.CopyToJobPost(ctx, company, post, now); err != nil {
+			return fmt.Errorf("error updating logo of job post %s: %w", post.JobID, err)
+		}
+	}
+	return p.blobs.Delete(ctx, key)
+}
+
+func (p Processor) setLogo(ctx context.Context, companyID string, logo []models.LogoThumbnail, now time.Time) (models.Company, error) {
+	for attempt := 1; ; attempt++ {
+		company, err := p.companies.Get(ctx, companyID)
+		if err != nil {
+			return models.Company{}, err
+		}
+		previousUpdatedAt := company.UpdatedAt
+		company.LogoThumbnails = logo
+		company.LogoURL = &logo[len(logo)-1].URL
+		company.UpdatedAt = now.Format(time.RFC3339)
+		err = p.companies.Save(ctx, company, previousUpdatedAt)
+		if errors.Is(err, repository.ErrConflict) && attempt < saveAttempts {
+			continue
+		}
+		if err != nil {
+			return models.Company{}, err
+		}
+		return company, nil
+	}
+}
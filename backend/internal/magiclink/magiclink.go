@@ -0,0 +1,104 @@
+package magiclink
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	cognitotypes "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+	"github.com/josepheid/upfront/internal/origins"
+)
+
+// path is the frontend page that completes the login, it is joined to an allowed origin.
+const path = "/magic-link"
+
+// validFor is how long a link can be used for.
+const validFor = 10 * time.Minute
+
+type TokenPayload struct {
+	Email      string `json:"email"`
+	Expiration string `json:"expiration"`
+}
+
+// Sender emails recruiters links that sign them in. The link's token is set as the Cognito user's
+// auth challenge, so only the latest link works.
+type Sender struct {
+	ses        *ses.Client
+	kmsc       *kms.Client
+	cipc       *cognitoidentityprovider.Client
+	kmsKeyID   string
+	userPoolId string
+}
+
+func NewSender(ses *ses.Client, kmsc *kms.Client, cipc *cognitoidentityprovider.Client, kmsKeyID, userPoolId string) Sender {
+	return Sender{
+		ses:        ses,
+		kmsc:       kmsc,
+		cipc:       cipc,
+		kmsKeyID:   kmsKeyID,
+		userPoolId: userPoolId,
+	}
+}
+
+// Start sets a new challenge for email and returns the link on origin that answers it.
+func (s Sender) Start(ctx context.Context, email, origin string, now time.Time) (string, error) {
+	payload := TokenPayload{
+		Email:      email,
+		Expiration: now.Add(validFor).Format(time.RFC3339),
+	}
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling token payload: %w", err)
+	}
+	resp, err := s.kmsc.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(s.kmsKeyID),
+		Plaintext: rawPayload,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error encrypting token: %w", err)
+	}
+	tokenB64 := base64.StdEncoding.EncodeToString(resp.CiphertextBlob)
+
+	_, err = s.cipc.AdminUpdateUserAttributes(ctx, &cognitoidentityprovider.AdminUpdateUserAttributesInput{
+		UserPoolId: aws.String(s.userPoolId),
+		Username:   aws.String(email),
+		UserAttributes: []cognitotypes.AttributeType{
+			{
+				Name:  aws.String("custom:authChallenge"),
+				Value: aws.String(tokenB64)},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error updating user atts: %w", err)
+	}
+	return origins.URL(origin, path, url.Values{"email": {email}, "token": {tokenB64}}), nil
+}
+
+// Send emails link to email.
+func (s Sender) Send(ctx context.Context, email, link string) error {
+	emailBody := aws.String(fmt.Sprintf(`<h1>You are nearly there! Please use the link below to log in:</h1><br/><br/>
+	<a href='%s'>Log In</a>`, link))
+	_, err := s.ses.SendEmail(ctx, &ses.SendEmailInput{
+		Destination: &types.Destination{
+			ToAddresses: []string{strings.ToLower(email)},
+		},
+		Message: &types.Message{
+			Subject: &types.Content{Data: aws.String("Your Upfront Login Link")},
+			Body:    &types.Body{Html: &types.Content{Data: emailBody}},
+		},
+		Source: aws.String("josephceid@gmail.com"),
+	})
+	if err != nil {
+		return fmt.Errorf("error sending email via ses: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+	"github.com/josepheid/upfront/api/models"
+)
+
+// EnabledFromEnv reports whether new posts are held for review, from the MODERATION_ENABLED
+// environment variable. It is off when unset.
+func EnabledFromEnv() (bool, error) {
+	v := os.Getenv("MODERATION_ENABLED")
+	if v == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("environment variable MODERATION_ENABLED must be true or false, got %q", v)
+	}
+	return enabled, nil
+}
+
+// senderEmail sends moderation decisions, the same address as the magic link emails.
+const senderEmail = "josephceid@gmail.com"
+
+// Notifier emails recruiters the decisions moderators make on their posts.
+type Notifier struct {
+	ses *ses.Client
+}
+
+func NewNotifier(sesClient *ses.Client) Notifier {
+	return Notifier{ses: sesClient}
+}
+
+// Notify emails the post's recruiter about review.
+func (n Notifier) Notify(ctx context.Context, item models.JobPostItem, review models.Review) error {
+	title := html.EscapeString(item.Title)
+	var subject, body string
+	switch review.Decision {
+	case models.Approved:
+		subject = fmt.Sprintf("Your job post %q is live", item.Title)
+		body = fmt.Sprintf("<h1>Your job post %s has been approved and is now live on Upfront.</h1>", title)
+	case models.ChangesRequested:
+		subject = fmt.Sprintf("Changes needed to your job post %q", item.Title)
+		body = fmt.Sprintf(`<h1>Your job post %s needs changes before it can go live.</h1><br/><br/>%s<br/><br/>
+	Edit the post from your dashboard and it will be reviewed again.`, title, html.EscapeString(review.Reason))
+	case models.Rejected:
+		subject = fmt.Sprintf("Your job post %q was rejected", item.Title)
+		body = fmt.Sprintf(`<h1>Your job post %s was rejected and will not be published.</h1><br/><br/>%s<br/><br/>
+	You have been refunded in full.`, title, html.EscapeString(review.Reason))
+	default:
+		return fmt.Errorf("unknown review decision %q", review.Decision)
+	}
+	_, err := n.ses.SendEmail(ctx, &ses.SendEmailInput{
+		Destination: &types.Destination{
+			ToAddresses: []string{item.LoginEmail},
+		},
+		Message: &types.Message{
+			Subject: &types.Content{Data: aws.String(subject)},
+			Body:    &types.Body{Html: &types.Content{Data: aws.String(body)}},
+		},
+		Source: aws.String(senderEmail),
+	})
+	if err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+	return nil
+}
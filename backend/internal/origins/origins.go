@@ -0,0 +1,64 @@
+package origins
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrNotAllowed is returned when an origin isn't in the allow-list.
+var ErrNotAllowed = errors.New("origin not allowed")
+
+// Allowlist is the set of frontend origins that redirects and magic links may point at.
+type Allowlist struct {
+	origins map[string]string
+}
+
+// NewAllowlist parses a comma separated list of origins, e.g. "https://example.com,http://localhost:3000".
+func NewAllowlist(raw string) (Allowlist, error) {
+	a := Allowlist{origins: map[string]string{}}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		u, err := url.Parse(entry)
+		if err != nil || u.Scheme == "" || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return Allowlist{}, fmt.Errorf("invalid origin %q, expected scheme://host[:port]", entry)
+		}
+		origin := normalise(u)
+		a.origins[origin] = origin
+	}
+	if len(a.origins) == 0 {
+		return Allowlist{}, errors.New("no allowed origins configured")
+	}
+	return a, nil
+}
+
+// Resolve returns the configured origin that rawURL belongs to. rawURL may be a bare origin
+// or a full URL, only its scheme and host are considered.
+func (a Allowlist) Resolve(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("%w: %q", ErrNotAllowed, rawURL)
+	}
+	origin, ok := a.origins[normalise(u)]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrNotAllowed, rawURL)
+	}
+	return origin, nil
+}
+
+// URL builds an absolute URL for path on origin, origin must already have been resolved.
+func URL(origin, path string, query url.Values) string {
+	u := origin + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func normalise(u *url.URL) string {
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
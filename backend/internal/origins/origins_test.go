@@ -0,0 +1,81 @@
+package origins
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestNewAllowlist(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "single origin", raw: "https://example.com"},
+		{name: "several origins with spaces", raw: " https://example.com , http://localhost:3000 "},
+		{name: "trailing slash", raw: "https://example.com/"},
+		{name: "empty entries are skipped", raw: "https://example.com,,"},
+		{name: "empty", raw: "", wantErr: true},
+		{name: "only commas", raw: ",,", wantErr: true},
+		{name: "missing scheme", raw: "example.com", wantErr: true},
+		{name: "path", raw: "https://example.com/app", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAllowlist(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAllowlist(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	allowlist, err := NewAllowlist("https://Example.com,http://localhost:3000")
+	if err != nil {
+		t.Fatalf("NewAllowlist: %v", err)
+	}
+	tests := []struct {
+		name   string
+		rawURL string
+		want   string
+	}{
+		{name: "bare origin", rawURL: "https://example.com", want: "https://example.com"},
+		{name: "full url", rawURL: "https://example.com/success?id=1", want: "https://example.com"},
+		{name: "case is ignored", rawURL: "HTTPS://EXAMPLE.COM/x", want: "https://example.com"},
+		{name: "surrounding space", rawURL: "  http://localhost:3000/post-job ", want: "http://localhost:3000"},
+		{name: "different scheme", rawURL: "http://example.com"},
+		{name: "different port", rawURL: "http://localhost:3001"},
+		{name: "subdomain", rawURL: "https://evil.example.com"},
+		{name: "lookalike suffix", rawURL: "https://example.com.evil.com"},
+		{name: "userinfo", rawURL: "https://example.com@evil.com"},
+		{name: "scheme relative", rawURL: "//example.com"},
+		{name: "relative path", rawURL: "/success"},
+		{name: "empty", rawURL: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allowlist.Resolve(tt.rawURL)
+			if tt.want == "" {
+				if !errors.Is(err, ErrNotAllowed) {
+					t.Errorf("Resolve(%q) = %q, %v, want ErrNotAllowed", tt.rawURL, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Resolve(%q) = %q, %v, want %q", tt.rawURL, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	if got, want := URL("https://example.com", "/post-job", nil), "https://example.com/post-job"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+	got := URL("https://example.com", "/success", url.Values{"id": {"a b"}})
+	if want := "https://example.com/success?id=a+b"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
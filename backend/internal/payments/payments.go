@@ -0,0 +1,243 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/requestid"
+	"github.com/josepheid/upfront/internal/timeouts"
+	"github.com/stripe/stripe-go/v80"
+	"github.com/stripe/stripe-go/v80/checkout/session"
+	"github.com/stripe/stripe-go/v80/coupon"
+	"github.com/stripe/stripe-go/v80/price"
+	"github.com/stripe/stripe-go/v80/promotioncode"
+	"github.com/stripe/stripe-go/v80/refund"
+)
+
+// Provider takes payments through Stripe checkout.
+type Provider struct {
+	timeouts timeouts.Budgets
+}
+
+// NewProvider configures the Stripe client with stripeKey.
+func NewProvider(stripeKey string, budgets timeouts.Budgets) Provider {
+	stripe.Key = stripeKey
+	return Provider{
+		timeouts: budgets,
+	}
+}
+
+// CheckoutParams describes a one off payment for a job post.
+type CheckoutParams struct {
+	JobID         string
+	ProductName   string
+	Amount        int64
+	CustomerEmail string
+	SuccessURL    string
+	CancelURL     string
+	// IdempotencyKey makes the call safe to retry, the same key must always be sent with the
+	// same parameters.
+	IdempotencyKey string
+	Metadata       map[string]string
+}
+
+// CreateCheckout creates a price and a checkout session to pay it.
+func (p Provider) CreateCheckout(ctx context.Context, params CheckoutParams) (*stripe.CheckoutSession, error) {
+	priceParams := &stripe.PriceParams{
+		Currency:    stripe.String(strings.ToLower(string(models.BillingCurrency))),
+		UnitAmount:  stripe.Int64(params.Amount),
+		ProductData: &stripe.PriceProductDataParams{Name: stripe.String(params.ProductName)},
+	}
+	priceCtx, cancelPrice := context.WithTimeout(ctx, p.timeouts.Stripe)
+	defer cancelPrice()
+	priceParams.Context = priceCtx
+	requestid.Stripe(ctx, &priceParams.Params)
+	if params.IdempotencyKey != "" {
+		priceParams.SetIdempotencyKey(params.IdempotencyKey + "/price")
+	}
+	priceResult, err := price.New(priceParams)
+	if err != nil {
+		return nil, fmt.Errorf("error creating price: %w", err)
+	}
+
+	checkoutSessionParams := &stripe.CheckoutSessionParams{
+		ClientReferenceID: stripe.String(params.JobID),
+		SuccessURL:        stripe.String(params.SuccessURL),
+		CancelURL:         stripe.String(params.CancelURL),
+		CustomerEmail:     stripe.String(params.CustomerEmail),
+		LineItems: []*stripe.CheckoutSessionLineItemParams{
+			{
+				Price:    stripe.String(priceResult.ID),
+				Quantity: stripe.Int64(1),
+			},
+		},
+		Mode: stripe.String(string(stripe.CheckoutSessionModePayment)),
+		// The amount taken is read back from the session, so discounts are recorded as paid.
+		AllowPromotionCodes: stripe.Bool(true),
+	}
+	sessionCtx, cancelSession := context.WithTimeout(ctx, p.timeouts.Stripe)
+	defer cancelSession()
+	checkoutSessionParams.Context = sessionCtx
+	requestid.Stripe(ctx, &checkoutSessionParams.Params)
+	for k, v := range params.Metadata {
+		checkoutSessionParams.AddMetadata(k, v)
+	}
+	if params.IdempotencyKey != "" {
+		// Retries must send identical parameters, so the per attempt request ID can't be included.
+		checkoutSessionParams.SetIdempotencyKey(params.IdempotencyKey + "/session")
+		checkoutSessionParams.AddMetadata("idempotency_key", params.IdempotencyKey)
+	} else {
+		checkoutSessionParams.AddMetadata("request_id", requestid.FromContext(ctx))
+	}
+	checkoutSessionResult, err := session.New(checkoutSessionParams)
+	if err != nil {
+		return nil, fmt.Errorf("error creating checkout session: %w", err)
+	}
+	return checkoutSessionResult, nil
+}
+
+// GetCheckout retrieves a checkout session.
+func (p Provider) GetCheckout(ctx context.Context, id string) (*stripe.CheckoutSession, error) {
+	stripeCtx, cancel := context.WithTimeout(ctx, p.timeouts.Stripe)
+	defer cancel()
+	params := &stripe.CheckoutSessionParams{}
+	params.Context = stripeCtx
+	requestid.Stripe(ctx, &params.Params)
+	result, err := session.Get(id, params)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving session: %w", err)
+	}
+	return result, nil
+}
+
+// ExpireCheckout stops an open checkout session from being paid.
+func (p Provider) ExpireCheckout(ctx context.Context, id string) error {
+	stripeCtx, cancel := context.WithTimeout(ctx, p.timeouts.Stripe)
+	defer cancel()
+	params := &stripe.CheckoutSessionExpireParams{}
+	params.Context = stripeCtx
+	requestid.Stripe(ctx, &params.Params)
+	if _, err := session.Expire(id, params); err != nil {
+		return fmt.Errorf("error expiring session: %w", err)
+	}
+	return nil
+}
+
+// Refund refunds amount of the payment taken by a checkout session. Each session is refunded at
+// most once, so a refund the payment already has is returned instead of issuing another. The
+// session ID also keys the refund, so two retries racing within Stripe's 24 hours can't both issue
+// one.
+func (p Provider) Refund(ctx context.Context, sessionID string, amount int64) (*stripe.Refund, error) {
+	checkoutSession, err := p.GetCheckout(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if checkoutSession.PaymentIntent == nil {
+		return nil, fmt.Errorf("checkout session %s has no payment intent", sessionID)
+	}
+	existing, err := p.issuedRefund(ctx, checkoutSession.PaymentIntent.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	stripeCtx, cancel := context.WithTimeout(ctx, p.timeouts.Stripe)
+	defer cancel()
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(checkoutSession.PaymentIntent.ID),
+		Amount:        stripe.Int64(amount),
+	}
+	params.Context = stripeCtx
+	requestid.Stripe(ctx, &params.Params)
+	params.SetIdempotencyKey("refund/" + sessionID)
+	result, err := refund.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("error creating refund: %w", err)
+	}
+	return result, nil
+}
+
+// issuedRefund returns a refund of the payment that hasn't failed or been cancelled, if it has one.
+// A refund can be issued and the post not saved afterwards, and Stripe forgets idempotency keys
+// after 24 hours, so this is what stops a later retry refunding the payment twice.
+func (p Provider) issuedRefund(ctx context.Context, paymentIntentID string) (*stripe.Refund, error) {
+	stripeCtx, cancel := context.WithTimeout(ctx, p.timeouts.Stripe)
+	defer cancel()
+	params := &stripe.RefundListParams{PaymentIntent: stripe.String(paymentIntentID)}
+	params.Context = stripeCtx
+	it := refund.List(params)
+	for it.Next() {
+		r := it.Refund()
+		if r.Status != stripe.RefundStatusFailed && r.Status != stripe.RefundStatusCanceled {
+			return r, nil
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("error listing refunds: %w", err)
+	}
+	return nil, nil
+}
+
+// PromotionParams describes a code recruiters can enter at checkout for a discount on one payment.
+// Exactly one of PercentOff and AmountOff is set.
+type PromotionParams struct {
+	Code       string
+	PercentOff float64
+	// AmountOff is in pence.
+	AmountOff int64
+	// MaxRedemptions limits how many times the code can be used, 0 is unlimited.
+	MaxRedemptions int64
+	// ExpiresAt is when the code stops working, the zero time never expires.
+	ExpiresAt time.Time
+}
+
+// CreatePromotion creates a coupon and a promotion code for it.
+func (p Provider) CreatePromotion(ctx context.Context, params PromotionParams) (*stripe.PromotionCode, error) {
+	couponParams := &stripe.CouponParams{
+		Name:     stripe.String(params.Code),
+		Duration: stripe.String(string(stripe.CouponDurationOnce)),
+	}
+	if params.PercentOff > 0 {
+		couponParams.PercentOff = stripe.Float64(params.PercentOff)
+	} else {
+		couponParams.AmountOff = stripe.Int64(params.AmountOff)
+		couponParams.Currency = stripe.String(strings.ToLower(string(models.BillingCurrency)))
+	}
+	couponCtx, cancelCoupon := context.WithTimeout(ctx, p.timeouts.Stripe)
+	defer cancelCoupon()
+	couponParams.Context = couponCtx
+	requestid.Stripe(ctx, &couponParams.Params)
+	couponResult, err := coupon.New(couponParams)
+	if err != nil {
+		return nil, fmt.Errorf("error creating coupon: %w", err)
+	}
+
+	promotionCodeParams := &stripe.PromotionCodeParams{
+		Coupon: stripe.String(couponResult.ID),
+		Code:   stripe.String(params.Code),
+	}
+	if params.MaxRedemptions > 0 {
+		promotionCodeParams.MaxRedemptions = stripe.Int64(params.MaxRedemptions)
+	}
+	if !params.ExpiresAt.IsZero() {
+		promotionCodeParams.ExpiresAt = stripe.Int64(params.ExpiresAt.Unix())
+	}
+	promotionCodeCtx, cancelPromotionCode := context.WithTimeout(ctx, p.timeouts.Stripe)
+	defer cancelPromotionCode()
+	promotionCodeParams.Context = promotionCodeCtx
+	requestid.Stripe(ctx, &promotionCodeParams.Params)
+	result, err := promotioncode.New(promotionCodeParams)
+	if err != nil {
+		return nil, fmt.Errorf("error creating promotion code: %w", err)
+	}
+	return result, nil
+}
+
+// Paid reports whether a checkout session has been paid for.
+func Paid(s *stripe.CheckoutSession) bool {
+	return s.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid
+}
@@ -0,0 +1,141 @@
+package ranking
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/josepheid/upfront/api/models"
+)
+
+// Config controls how Premium posts are placed above Standard ones.
+type Config struct {
+	// FeaturedDays is how long a Premium post is featured after it was paid for.
+	FeaturedDays int
+	// Slots is how many featured posts fit at the top of the listing at once, the featured order
+	// moves on by this many posts each rotation. Zero turns featuring off.
+	Slots int
+	// Rotation is how often the featured order moves on.
+	Rotation time.Duration
+}
+
+// Defaults feature Premium posts for their first week, five at a time, rotating hourly.
+var Defaults = Config{
+	FeaturedDays: 7,
+	Slots:        5,
+	Rotation:     time.Hour,
+}
+
+// FromEnv reads the config from the FEATURED_DAYS, FEATURED_SLOTS and FEATURED_ROTATION environment
+// variables. Unset variables keep their default.
+func FromEnv() (Config, error) {
+	c := Defaults
+	for key, n := range map[string]*int{
+		"FEATURED_DAYS":  &c.FeaturedDays,
+		"FEATURED_SLOTS": &c.Slots,
+	} {
+		v := os.Getenv(key)
+		if v == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			return Config{}, fmt.Errorf("environment variable %s must be a non negative whole number, got %q", key, v)
+		}
+		*n = parsed
+	}
+	if v := os.Getenv("FEATURED_ROTATION"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			return Config{}, fmt.Errorf("environment variable FEATURED_ROTATION must be a positive duration, got %q", v)
+		}
+		c.Rotation = parsed
+	}
+	return c, nil
+}
+
+// Rank orders posts for the public listing. Every featured post comes first and is marked
+// Featured, the rest follow newest first. Within the featured group the order rotates by Slots
+// posts every Rotation, so when there are more featured posts than fit at the top each of them
+// spends the same time there.
+func Rank(posts []models.JobPostItem, now time.Time, c Config) []models.JobPostItem {
+	type candidate struct {
+		post  models.JobPostItem
+		since time.Time
+	}
+	var featured []candidate
+	var rest []models.JobPostItem
+	for _, post := range posts {
+		post.Featured = false
+		if since, ok := FeaturedSince(post, now, c); ok && c.Slots > 0 {
+			featured = append(featured, candidate{post: post, since: since})
+			continue
+		}
+		rest = append(rest, post)
+	}
+
+	// A stable order for the rotation to walk through, oldest feature first.
+	sort.SliceStable(featured, func(i, j int) bool {
+		if !featured[i].since.Equal(featured[j].since) {
+			return featured[i].since.Before(featured[j].since)
+		}
+		return featured[i].post.JobID < featured[j].post.JobID
+	})
+	if len(featured) > c.Slots {
+		offset := rotationOffset(len(featured), now, c)
+		featured = append(featured[offset:], featured[:offset]...)
+	}
+
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].CreatedAt > rest[j].CreatedAt
+	})
+
+	ranked := make([]models.JobPostItem, 0, len(posts))
+	for _, f := range featured {
+		f.post.Featured = true
+		ranked = append(ranked, f.post)
+	}
+	return append(ranked, rest...)
+}
+
+// FeaturedSince returns when a post's current featured window started, if it is still in it. The
+// window starts at the latest paid Premium purchase, so renewing or upgrading features a post again.
+func FeaturedSince(post models.JobPostItem, now time.Time, c Config) (time.Time, bool) {
+	if post.Status != models.Active || post.PlanType != models.Premium || c.FeaturedDays <= 0 {
+		return time.Time{}, false
+	}
+	var since time.Time
+	for _, p := range post.Purchases() {
+		if p.Status != models.PurchasePaid || p.PlanType != models.Premium {
+			continue
+		}
+		// Posts from before billing history was recorded have no PaidAt.
+		paidAt := p.PaidAt
+		if paidAt == "" {
+			paidAt = post.CreatedAt
+		}
+		t, err := time.Parse(time.RFC3339, paidAt)
+		if err != nil {
+			continue
+		}
+		// Moderated posts are featured from when they went live rather than when they were paid for.
+		if t = post.LiveSince(t); t.After(since) {
+			since = t
+		}
+	}
+	if since.IsZero() || !now.Before(since.AddDate(0, 0, c.FeaturedDays)) {
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+// rotationOffset moves the start of the featured order on by Slots posts every Rotation.
+func rotationOffset(n int, now time.Time, c Config) int {
+	if c.Rotation <= 0 {
+		return 0
+	}
+	step := now.UnixNano() / int64(c.Rotation)
+	return int((step * int64(c.Slots)) % int64(n))
+}
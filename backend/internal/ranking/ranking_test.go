@@ -0,0 +1,221 @@
+package ranking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/josepheid/upfront/api/models"
+)
+
+var now = time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+func post(id string, plan models.PlanType, paidAt time.Time) models.JobPostItem {
+	item := models.JobPostItem{
+		JobID:     id,
+		Status:    models.Active,
+		CreatedAt: paidAt.Format(time.RFC3339),
+		BillingHistory: []models.Purchase{
+			{SessionID: "cs_" + id, Kind: models.InitialPurchase, PlanType: plan, PlanDuration: 30, Status: models.PurchasePaid, PaidAt: paidAt.Format(time.RFC3339)},
+		},
+	}
+	item.PlanType = plan
+	return item
+}
+
+func ids(posts []models.JobPostItem) []string {
+	out := make([]string, len(posts))
+	for i, p := range posts {
+		out[i] = p.JobID
+	}
+	return out
+}
+
+func featuredIDs(posts []models.JobPostItem) map[string]bool {
+	out := map[string]bool{}
+	for _, p := range posts {
+		if p.Featured {
+			out[p.JobID] = true
+		}
+	}
+	return out
+}
+
+func TestFeaturedSince(t *testing.T) {
+	c := Config{FeaturedDays: 7, Slots: 5, Rotation: time.Hour}
+	expired := post("expired", models.Premium, now.AddDate(0, 0, -8))
+	renewed := post("renewed", models.Premium, now.AddDate(0, 0, -20))
+	renewed.BillingHistory = append(renewed.BillingHistory, models.Purchase{
+		SessionID: "cs_renewal", Kind: models.Renewal, PlanType: models.Premium, PlanDuration: 30,
+		Status: models.PurchasePaid, PaidAt: now.AddDate(0, 0, -1).Format(time.RFC3339),
+	})
+	paused := post("paused", models.Premium, now.AddDate(0, 0, -1))
+	paused.Status = models.Paused
+	reviewed := post("reviewed", models.Premium, now.AddDate(0, 0, -9))
+	reviewed.Reviews = []models.Review{{Decision: models.Approved, ReviewedAt: now.AddDate(0, 0, -2).Format(time.RFC3339)}}
+
+	tests := []struct {
+		name      string
+		post      models.JobPostItem
+		c         Config
+		wantSince time.Time
+		want      bool
+	}{
+		{name: "within the window", post: post("a", models.Premium, now.AddDate(0, 0, -3)), c: c, wantSince: now.AddDate(0, 0, -3), want: true},
+		{name: "window ended", post: expired, c: c},
+		{name: "ends exactly now", post: post("b", models.Premium, now.AddDate(0, 0, -7)), c: c},
+		{name: "standard plan", post: post("c", models.Standard, now), c: c},
+		{name: "renewal features again", post: renewed, c: c, wantSince: now.AddDate(0, 0, -1), want: true},
+		{name: "not active", post: paused, c: c},
+		{name: "featured from approval", post: reviewed, c: c, wantSince: now.AddDate(0, 0, -2), want: true},
+		{name: "featuring off", post: post("d", models.Premium, now), c: Config{Slots: 5, Rotation: time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, ok := FeaturedSince(tt.post, now, tt.c)
+			if ok != tt.want || !since.Equal(tt.wantSince) {
+				t.Errorf("FeaturedSince() = %v, %v, want %v, %v", since, ok, tt.wantSince, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankFeaturedAboveStandard(t *testing.T) {
+	c := Config{FeaturedDays: 7, Slots: 2, Rotation: time.Hour}
+	posts := []models.JobPostItem{
+		post("std-old", models.Standard, now.AddDate(0, 0, -5)),
+		post("prem-1", models.Premium, now.AddDate(0, 0, -4)),
+		post("std-new", models.Standard, now.Add(-time.Hour)),
+		post("prem-2", models.Premium, now.AddDate(0, 0, -3)),
+		post("prem-old", models.Premium, now.AddDate(0, 0, -10)),
+		post("prem-3", models.Premium, now.AddDate(0, 0, -2)),
+		post("prem-4", models.Premium, now.AddDate(0, 0, -1)),
+	}
+	ranked := Rank(posts, now, c)
+	if len(ranked) != len(posts) {
+		t.Fatalf("Rank() returned %d posts, want %d", len(ranked), len(posts))
+	}
+	// More featured posts than slots: all four still come before every other post.
+	featured := featuredIDs(ranked)
+	for i, p := range ranked {
+		if want := i < 4; p.Featured != want {
+			t.Errorf("post %d %s Featured = %v, want %v in %v", i, p.JobID, p.Featured, want, ids(ranked))
+		}
+	}
+	for _, id := range []string{"prem-1", "prem-2", "prem-3", "prem-4"} {
+		if !featured[id] {
+			t.Errorf("%s is not featured in %v", id, ids(ranked))
+		}
+	}
+	// The rest, including a Premium post past its window, are newest first.
+	rest := ids(ranked[4:])
+	want := []string{"std-new", "std-old", "prem-old"}
+	for i := range want {
+		if rest[i] != want[i] {
+			t.Errorf("unfeatured order = %v, want %v", rest, want)
+			break
+		}
+	}
+}
+
+func TestRankRotation(t *testing.T) {
+	c := Config{FeaturedDays: 7, Slots: 2, Rotation: time.Hour}
+	var posts []models.JobPostItem
+	order := []string{"a", "b", "c", "d", "e"}
+	for i, id := range order {
+		posts = append(posts, post(id, models.Premium, now.AddDate(0, 0, -5).Add(time.Duration(i)*time.Hour)))
+	}
+	posts = append(posts, post("std", models.Standard, now))
+
+	leads := map[string]int{}
+	var previous string
+	for hour := 0; hour < len(order); hour++ {
+		at := now.Add(time.Duration(hour) * time.Hour)
+		ranked := ids(Rank(posts, at, c))
+		if ranked[len(ranked)-1] != "std" {
+			t.Fatalf("at hour %d the standard post is not last: %v", hour, ranked)
+		}
+		// The featured group keeps its oldest-first cycle, only the starting point moves.
+		start := 0
+		for order[start] != ranked[0] {
+			start++
+		}
+		for i := range order {
+			if ranked[i] != order[(start+i)%len(order)] {
+				t.Fatalf("at hour %d featured order = %v, want a rotation of %v", hour, ranked[:len(order)], order)
+			}
+		}
+		if hour > 0 {
+			prev := 0
+			for order[prev] != previous {
+				prev++
+			}
+			if want := order[(prev+c.Slots)%len(order)]; ranked[0] != want {
+				t.Errorf("at hour %d the top post is %s, want %s two on from %s", hour, ranked[0], want, previous)
+			}
+		}
+		previous = ranked[0]
+		leads[ranked[0]]++
+	}
+	// Over a full cycle every featured post leads once.
+	for _, id := range order {
+		if leads[id] != 1 {
+			t.Errorf("%s led %d times in a cycle, want 1: %v", id, leads[id], leads)
+		}
+	}
+
+	// Within a rotation period the order doesn't change.
+	first, later := ids(Rank(posts, now, c)), ids(Rank(posts, now.Add(20*time.Minute), c))
+	for i := range first {
+		if first[i] != later[i] {
+			t.Errorf("order changed within a rotation: %v then %v", first, later)
+			break
+		}
+	}
+}
+
+func TestRankNoRotationWithinSlots(t *testing.T) {
+	c := Config{FeaturedDays: 7, Slots: 5, Rotation: time.Hour}
+	posts := []models.JobPostItem{
+		post("newer", models.Premium, now.AddDate(0, 0, -1)),
+		post("older", models.Premium, now.AddDate(0, 0, -2)),
+	}
+	for hour := 0; hour < 3; hour++ {
+		ranked := ids(Rank(posts, now.Add(time.Duration(hour)*time.Hour), c))
+		if ranked[0] != "older" || ranked[1] != "newer" {
+			t.Errorf("at hour %d order = %v, want oldest feature first", hour, ranked)
+		}
+	}
+}
+
+func TestRankTies(t *testing.T) {
+	c := Config{FeaturedDays: 7, Slots: 5, Rotation: time.Hour}
+	paidAt := now.AddDate(0, 0, -1)
+	posts := []models.JobPostItem{
+		post("std-b", models.Standard, paidAt),
+		post("prem-z", models.Premium, paidAt),
+		post("std-a", models.Standard, paidAt),
+		post("prem-m", models.Premium, paidAt),
+		post("prem-a", models.Premium, paidAt),
+	}
+	ranked := ids(Rank(posts, now, c))
+	// Featured posts with the same start are ordered by id, Standard ones created at the same time
+	// keep the order they were given in.
+	want := []string{"prem-a", "prem-m", "prem-z", "std-b", "std-a"}
+	for i := range want {
+		if ranked[i] != want[i] {
+			t.Fatalf("Rank() = %v, want %v", ranked, want)
+		}
+	}
+}
+
+func TestRankFeaturingOff(t *testing.T) {
+	posts := []models.JobPostItem{
+		post("prem", models.Premium, now.AddDate(0, 0, -2)),
+		post("std", models.Standard, now.AddDate(0, 0, -1)),
+	}
+	posts[0].Featured = true
+	ranked := Rank(posts, now, Config{FeaturedDays: 7, Slots: 0, Rotation: time.Hour})
+	if ranked[0].JobID != "std" || ranked[0].Featured || ranked[1].Featured {
+		t.Errorf("with no slots Rank() = %v, featured %v, want newest first with nothing featured", ids(ranked), featuredIDs(ranked))
+	}
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+)
+
+// Limiter allows a number of requests per key in each fixed window, counting them in items in the
+// upfront table that expire after the window.
+type Limiter struct {
+	ddbc      *dynamodb.Client
+	tableName string
+	limit     int
+	window    time.Duration
+}
+
+func NewLimiter(ddbc *dynamodb.Client, tableName string, limit int, window time.Duration) Limiter {
+	return Limiter{
+		ddbc:      ddbc,
+		tableName: tableName,
+		limit:     limit,
+		window:    window,
+	}
+}
+
+// Allow counts a request for key and reports whether it is within the limit. Scope namespaces
+// the keys of each endpoint.
+func (l Limiter) Allow(ctx context.Context, scope, key string, now time.Time) (bool, error) {
+	windowStart := now.Truncate(l.window)
+	cond := expression.AttributeNotExists(expression.Name("PK")).
+		Or(expression.Name("requests").LessThan(expression.Value(l.limit)))
+	upd := expression.Add(expression.Name("requests"), expression.Value(1)).
+		Set(expression.Name("ttl"), expression.Value(windowStart.Add(l.window).Unix()))
+	expr, err := expression.NewBuilder().WithCondition(cond).WithUpdate(upd).Build()
+	if err != nil {
+		return false, fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = l.ddbc.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(l.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: fmt.Sprintf("rateLimit/%s/%s", scope, key)},
+			"SK": &types.AttributeValueMemberS{Value: strconv.FormatInt(windowStart.Unix(), 10)},
+		},
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error counting request: %w", err)
+	}
+	return true, nil
+}
+
+// ClientIP returns the address a request came from, as seen by API Gateway.
+func ClientIP(r *http.Request) string {
+	if apiGatewayContext, ok := core.GetAPIGatewayContextFromContext(r.Context()); ok && apiGatewayContext.Identity.SourceIP != "" {
+		return apiGatewayContext.Identity.SourceIP
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// IPHashKeySecret is the Secrets Manager secret that holds the IPHasher key, under a key of the
+// same name.
+const IPHashKeySecret = "IP_HASH_KEY"
+
+// minIPHashKeyLength keeps the key long enough that it can't be guessed either.
+const minIPHashKeyLength = 32
+
+// IPHasher identifies addresses without storing them. Hashes are an HMAC keyed with a secret, so
+// they can't be reversed by hashing every possible address.
+type IPHasher struct {
+	key []byte
+}
+
+func NewIPHasher(key string) (IPHasher, error) {
+	if len(key) < minIPHashKeyLength {
+		return IPHasher{}, fmt.Errorf("ip hash key must be at least %d characters", minIPHashKeyLength)
+	}
+	return IPHasher{key: []byte(key)}, nil
+}
+
+// IPHasherFromSecret reads the key from the IPHashKeySecret secret.
+func IPHasherFromSecret(ctx context.Context, smc *secretsmanager.Client) (IPHasher, error) {
+	result, err := smc.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(IPHashKeySecret),
+		VersionStage: aws.String("AWSCURRENT"),
+	})
+	if err != nil {
+		return IPHasher{}, fmt.Errorf("error getting ip hash key: %w", err)
+	}
+	var secretKeyValuePair map[string]string
+	if err := json.Unmarshal([]byte(aws.ToString(result.SecretString)), &secretKeyValuePair); err != nil {
+		return IPHasher{}, fmt.Errorf("error decoding ip hash key: %w", err)
+	}
+	return NewIPHasher(secretKeyValuePair[IPHashKeySecret])
+}
+
+// Hash returns the identifier of ip.
+func (h IPHasher) Hash(ip string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
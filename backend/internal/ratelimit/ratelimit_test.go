@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josepheid/upfront/internal/ddbtest"
+)
+
+func TestIPHasher(t *testing.T) {
+	if _, err := NewIPHasher("too short"); err == nil {
+		t.Error("NewIPHasher() accepted a short key")
+	}
+	a, err := NewIPHasher(strings.Repeat("a", 32))
+	if err != nil {
+		t.Fatalf("NewIPHasher() = %v", err)
+	}
+	b, _ := NewIPHasher(strings.Repeat("b", 32))
+
+	ip := "203.0.113.7"
+	if a.Hash(ip) != a.Hash(ip) {
+		t.Error("Hash() is not deterministic")
+	}
+	if a.Hash(ip) == a.Hash("203.0.113.8") {
+		t.Error("different addresses have the same hash")
+	}
+	if a.Hash(ip) == b.Hash(ip) {
+		t.Error("hashes don't depend on the key")
+	}
+	// Without the key, hashing every address doesn't find it.
+	sum := sha256.Sum256([]byte(ip))
+	if a.Hash(ip) == hex.EncodeToString(sum[:16]) {
+		t.Error("Hash() is a plain sha256 of the address")
+	}
+	if strings.Contains(a.Hash(ip), ip) || len(a.Hash(ip)) != 32 {
+		t.Errorf("Hash() = %q", a.Hash(ip))
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	_, ddbc := ddbtest.New(t)
+	limiter := NewLimiter(ddbc, "upfront", 2, time.Hour)
+	now := time.Date(2026, 3, 1, 12, 10, 0, 0, time.UTC)
+
+	tests := []struct {
+		scope, key string
+		at         time.Time
+		want       bool
+	}{
+		{scope: "reports", key: "a", at: now, want: true},
+		{scope: "reports", key: "a", at: now.Add(time.Minute), want: true},
+		{scope: "reports", key: "a", at: now.Add(2 * time.Minute), want: false},
+		{scope: "reports", key: "b", at: now, want: true},
+		{scope: "drafts", key: "a", at: now, want: true},
+		{scope: "reports", key: "a", at: now.Add(50 * time.Minute), want: true},
+	}
+	for i, tt := range tests {
+		got, err := limiter.Allow(ctx, tt.scope, tt.key, tt.at)
+		if err != nil {
+			t.Fatalf("request %d: Allow() = %v", i, err)
+		}
+		if got != tt.want {
+			t.Errorf("request %d: Allow(%s, %s, %s) = %v, want %v", i, tt.scope, tt.key, tt.at.Format(time.Kitchen), got, tt.want)
+		}
+	}
+}
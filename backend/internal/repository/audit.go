@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/requestid"
+)
+
+// Audit reads and writes the audit entries stored under job posts in the upfront table.
+type Audit struct {
+	ddbc      *dynamodb.Client
+	tableName string
+}
+
+func NewAudit(ddbc *dynamodb.Client, tableName string) Audit {
+	return Audit{
+		ddbc:      ddbc,
+		tableName: tableName,
+	}
+}
+
+// auditPut returns the write that adds entry, so it can be made in the same transaction as the
+// change it records. i orders entries written together, the request ID is taken from ctx.
+func auditPut(ctx context.Context, tableName string, entry models.AuditEntry, i int) (types.TransactWriteItem, error) {
+	createdAt, err := time.Parse(time.RFC3339Nano, entry.CreatedAt)
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("invalid audit entry time: %w", err)
+	}
+	entry.SK = models.FormatAuditSK(createdAt, fmt.Sprintf("%02d-%s", i, uuid.NewString()[:8]))
+	if entry.RequestID == "" {
+		entry.RequestID = requestid.FromContext(ctx)
+	}
+	data, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("error marshalling audit entry: %w", err)
+	}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name("PK"))).
+		Build()
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("error building expression: %w", err)
+	}
+	return types.TransactWriteItem{Put: &types.Put{
+		TableName:                 aws.String(tableName),
+		Item:                      data,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}, nil
+}
+
+// List returns every audit entry of the job post with id, oldest first.
+func (s Audit) List(ctx context.Context, id string) ([]models.AuditEntry, error) {
+	keyCondition := expression.KeyEqual(expression.Key("PK"), expression.Value(models.FormatPK(id))).
+		And(expression.KeyBeginsWith(expression.Key("SK"), models.AuditSKPrefix))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+	paginator := dynamodb.NewQueryPaginator(s.ddbc, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ConsistentRead:            aws.Bool(true),
+	})
+	entries := []models.AuditEntry{}
+	for paginator.HasMorePages() {
+		data, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying audit entries: %w", err)
+		}
+		page := []models.AuditEntry{}
+		if err := attributevalue.UnmarshalListOfMaps(data.Items, &page); err != nil {
+			return nil, fmt.Errorf("error unmarshalling audit entries: %w", err)
+		}
+		entries = append(entries, page...)
+	}
+	return entries, nil
+}
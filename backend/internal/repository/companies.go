@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/josepheid/upfront/api/models"
+)
+
+var (
+	// ErrCompanyNotFound is returned when a company doesn't exist.
+	ErrCompanyNotFound = errors.New("company not found")
+	// ErrSlugTaken is returned when another company already has a slug.
+	ErrSlugTaken = errors.New("company slug is taken")
+)
+
+const (
+	companySK     = "company"
+	companySlugSK = "slug"
+	// companyIndex is the GSI of job posts by companyID, newest first.
+	companyIndex = "companyIndex"
+)
+
+// Companies reads and writes company items in the upfront table.
+type Companies struct {
+	ddbc      *dynamodb.Client
+	tableName string
+	members   Members
+}
+
+func NewCompanies(ddbc *dynamodb.Client, tableName string) Companies {
+	return Companies{
+		ddbc:      ddbc,
+		tableName: tableName,
+		members:   NewMembers(ddbc, tableName),
+	}
+}
+
+// Get returns the company with id.
+func (s Companies) Get(ctx context.Context, id string) (models.Company, error) {
+	data, err := s.ddbc.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.FormatCompanyPK(id)},
+			"SK": &types.AttributeValueMemberS{Value: companySK},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return models.Company{}, fmt.Errorf("error getting company: %w", err)
+	}
+	if data.Item == nil {
+		return models.Company{}, ErrCompanyNotFound
+	}
+	company := models.Company{}
+	if err := attributevalue.UnmarshalMap(data.Item, &company); err != nil {
+		return models.Company{}, fmt.Errorf("error unmarshalling company: %w", err)
+	}
+	return company, nil
+}
+
+// GetBySlug returns the company that has reserved slug.
+func (s Companies) GetBySlug(ctx context.Context, slug string) (models.Company, error) {
+	data, err := s.ddbc.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: models.FormatCompanySlugPK(slug)},
+			"SK": &types.AttributeValueMemberS{Value: companySlugSK},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return models.Company{}, fmt.Errorf("error getting company slug: %w", err)
+	}
+	if data.Item == nil {
+		return models.Company{}, ErrCompanyNotFound
+	}
+	reservation := models.CompanySlug{}
+	if err := attributevalue.UnmarshalMap(data.Item, &reservation); err != nil {
+		return models.Company{}, fmt.Errorf("error unmarshalling company slug: %w", err)
+	}
+	return s.Get(ctx, reservation.CompanyID)
+}
+
+// Create stores a new company with owner as its first member and reserves its slug, failing with
+// ErrSlugTaken if another company already has the slug.
+func (s Companies) Create(ctx context.Context, company models.Company, owner models.Member) error {
+	company.PK = models.FormatCompanyPK(company.CompanyID)
+	company.SK = companySK
+	companyData, err := attributevalue.MarshalMap(company)
+	if err != nil {
+		return fmt.Errorf("error marshalling company: %w", err)
+	}
+	slugData, err := attributevalue.MarshalMap(models.CompanySlug{
+		PK:        models.FormatCompanySlugPK(company.Slug),
+		SK:        companySlugSK,
+		CompanyID: company.CompanyID,
+	})
+	if err != nil {
+		return fmt.Errorf("error marshalling company slug: %w", err)
+	}
+	ownerData, err := attributevalue.MarshalMap(owner)
+	if err != nil {
+		return fmt.Errorf("error marshalling company owner: %w", err)
+	}
+	notExists := aws.String("attribute_not_exists(PK)")
+	_, err = s.ddbc.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{TableName: aws.String(s.tableName), Item: slugData, ConditionExpression: notExists}},
+			{Put: &types.Put{TableName: aws.String(s.tableName), Item: companyData, ConditionExpression: notExists}},
+			{Put: &types.Put{TableName: aws.String(s.tableName), Item: ownerData, ConditionExpression: notExists}},
+		},
+	})
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) {
+		// The first item is the slug, a company ID collision isn't expected since IDs are UUIDs.
+		if len(cancelled.CancellationReasons) > 0 && aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return ErrSlugTaken
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error creating company: %w", err)
+	}
+	return nil
+}
+
+// Save writes company, failing with ErrConflict if the stored company's updatedAt is no longer
+// previousUpdatedAt. The slug can't be changed by saving.
+func (s Companies) Save(ctx context.Context, company models.Company, previousUpdatedAt string) error {
+	company.PK = models.FormatCompanyPK(company.CompanyID)
+	company.SK = companySK
+	data, err := attributevalue.MarshalMap(company)
+	if err != nil {
+		return fmt.Errorf("error marshalling company: %w", err)
+	}
+	cond := expression.Name("updatedAt").Equal(expression.Value(previousUpdatedAt)).
+		And(expression.Name("slug").Equal(expression.Value(company.Slug)))
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = s.ddbc.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(s.tableName),
+		Item:                      data,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("error putting company: %w", err)
+	}
+	return nil
+}
+
+// JobPosts returns the company's job posts that match filter, newest first, leaving out drafts.
+// Pass an empty condition to return every post.
+func (s Companies) JobPosts(ctx context.Context, companyID string, filter expression.ConditionBuilder) ([]models.JobPostItem, error) {
+	// Drafts name a company before anyone has checked they may post for it, so they aren't the
+	// company's posts until checked out.
+	notDraft := expression.Name("status").NotEqual(expression.Value(models.Draft))
+	if filter.IsSet() {
+		notDraft = notDraft.And(filter)
+	}
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(expression.KeyEqual(expression.Key("companyID"), expression.Value(companyID))).
+		WithFilter(notDraft).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+	paginator := dynamodb.NewQueryPaginator(s.ddbc, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableName),
+		IndexName:                 aws.String(companyIndex),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ScanIndexForward:          aws.Bool(false),
+	})
+	posts := []models.JobPostItem{}
+	for paginator.HasMorePages() {
+		data, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying company index: %w", err)
+		}
+		page := []models.JobPostItem{}
+		if err := attributevalue.UnmarshalListOfMaps(data.Items, &page); err != nil {
+			return nil, fmt.Errorf("error unmarshalling job posts: %w", err)
+		}
+		posts = append(posts, page...)
+	}
+	return posts, nil
+}
+
+// CopyToJobPost updates the company details copied onto a job post. The post's version is moved on
+// too, so a concurrent Save of the post fails rather than restoring the old details.
+func (s Companies) CopyToJobPost(ctx context.Context, company models.Company, post models.JobPostItem, now time.Time) error {
+	update := bumpVersion(expression.Set(expression.Name("companyName"), expression.Value(company.Name)).
+		Set(expression.Name("companyWebsite"), expression.Value(company.Website)).
+		Set(expression.Name("companyLogoURL"), expression.Value(company.LogoURL)).
+		Set(expression.Name("updatedAt"), expression.Value(now.Format(time.RFC3339))))
+	cond := expression.Name("companyID").Equal(expression.Value(company.CompanyID))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = s.ddbc.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: post.PK},
+			"SK": &types.AttributeValueMemberS{Value: post.SK},
+		},
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("error updating job post: %w", err)
+	}
+	return nil
+}
+
+// maxSlugAttempts bounds the numbered suffixes tried when a company's slug is taken by another
+// recruiter, after them the slug is suffixed with the start of the company's ID instead.
+const maxSlugAttempts = 20
+
+// FindOrCreate returns the company whose slug is derived from profile's name if email can edit
+// it, otherwise it creates the company with email as its owner. When the slug belongs to a company
+// email isn't an editor of, a numbered suffix is added, so "acme" becomes "acme-2". Only call it
+// with an email the recruiter has proved they own, it makes them a member of the company.
+func (s Companies) FindOrCreate(ctx context.Context, email string, profile models.CompanyProfile, now time.Time) (models.Company, error) {
+	return s.create(ctx, uuid.NewString(), email, profile, now, true)
+}
+
+// GetOrCreate returns the company with id, creating it with email as its owner if it doesn't exist.
+// Unlike FindOrCreate it never joins an existing company, a taken slug always gets a numbered
+// suffix, so it is safe for an email nobody has verified. Calling it again with the same id returns
+// the company made the first time.
+func (s Companies) GetOrCreate(ctx context.Context, id, email string, profile models.CompanyProfile, now time.Time) (models.Company, error) {
+	company, err := s.Get(ctx, id)
+	if !errors.Is(err, ErrCompanyNotFound) {
+		return company, err
+	}
+	company, err = s.create(ctx, id, email, profile, now, false)
+	if err != nil {
+		// A concurrent call may have created it first.
+		if existing, getErr := s.Get(ctx, id); getErr == nil {
+			return existing, nil
+		}
+		return models.Company{}, err
+	}
+	return company, nil
+}
+
+// create makes the company with id under the first free slug derived from profile's name. When
+// join is set, a company with the slug that email can edit is returned instead. It only fails with
+// ErrSlugTaken if the last resort slug made from id is taken too.
+func (s Companies) create(ctx context.Context, id, email string, profile models.CompanyProfile, now time.Time, join bool) (models.Company, error) {
+	base := models.Slugify(profile.Name)
+	if base == "" {
+		return models.Company{}, fmt.Errorf("company name %q has no letters or numbers", profile.Name)
+	}
+	for attempt := 1; attempt <= maxSlugAttempts+1; attempt++ {
+		slug := companySlug(base, id, attempt)
+		existing, err := s.GetBySlug(ctx, slug)
+		if err == nil {
+			if !join {
+				continue
+			}
+			member, err := s.members.Get(ctx, existing.CompanyID, email)
+			if err == nil && member.Active() && member.Role.AtLeast(models.Editor) {
+				return existing, nil
+			}
+			if err != nil && !errors.Is(err, ErrMemberNotFound) {
+				return models.Company{}, err
+			}
+			continue
+		}
+		if !errors.Is(err, ErrCompanyNotFound) {
+			return models.Company{}, err
+		}
+		company := models.Company{
+			CompanyID: id,
+			Slug:      slug,
+			CreatedAt: now.Format(time.RFC3339),
+			UpdatedAt: now.Format(time.RFC3339),
+		}
+		company.Apply(profile)
+		err = s.Create(ctx, company, models.NewOwner(company.CompanyID, email, now))
+		if errors.Is(err, ErrSlugTaken) {
+			// Claimed since it was read, the claimant may be this recruiter so check it again.
+			attempt--
+			continue
+		}
+		if err != nil {
+			return models.Company{}, err
+		}
+		return company, nil
+	}
+	return models.Company{}, ErrSlugTaken
+}
+
+// companySlug returns the slug to try for a company with id on attempt, see maxSlugAttempts.
+func companySlug(base, id string, attempt int) string {
+	switch {
+	case attempt == 1:
+		return base
+	case attempt <= maxSlugAttempts:
+		return fmt.Sprintf("%s-%d", base, attempt)
+	}
+	short := strings.ReplaceAll(id, "-", "")
+	return base + "-" + short[:min(len(short), 8)]
+}
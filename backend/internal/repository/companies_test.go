@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/ddbtest"
+)
+
+func TestCompaniesFindOrCreate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_, ddbc := ddbtest.New(t)
+	companies := NewCompanies(ddbc, "upfront")
+	profile := models.CompanyProfile{Name: "Acme"}
+
+	acme, err := companies.FindOrCreate(ctx, "owner@acme.com", profile, now)
+	if err != nil || acme.Slug != "acme" {
+		t.Fatalf("FindOrCreate() = %+v, %v, want a new company acme", acme, err)
+	}
+	again, err := companies.FindOrCreate(ctx, "owner@acme.com", profile, now)
+	if err != nil || again.CompanyID != acme.CompanyID {
+		t.Errorf("FindOrCreate() by its owner = %+v, %v, want the existing company", again, err)
+	}
+	other, err := companies.FindOrCreate(ctx, "someone@else.com", profile, now)
+	if err != nil || other.CompanyID == acme.CompanyID || other.Slug != "acme-2" {
+		t.Errorf("FindOrCreate() by a stranger = %+v, %v, want a new company acme-2", other, err)
+	}
+}
+
+func TestCompaniesGetOrCreate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_, ddbc := ddbtest.New(t)
+	companies := NewCompanies(ddbc, "upfront")
+	members := NewMembers(ddbc, "upfront")
+	profile := models.CompanyProfile{Name: "Acme"}
+
+	acme, err := companies.FindOrCreate(ctx, "owner@acme.com", profile, now)
+	if err != nil {
+		t.Fatalf("FindOrCreate() = %v", err)
+	}
+
+	// The owner's email on an unverified checkout doesn't get them into their own company, let
+	// alone anyone else into it.
+	for _, tt := range []struct{ id, slug string }{
+		{id: "11111111-1111-1111-1111-111111111111", slug: "acme-2"},
+		{id: "22222222-2222-2222-2222-222222222222", slug: "acme-3"},
+	} {
+		company, err := companies.GetOrCreate(ctx, tt.id, "owner@acme.com", profile, now)
+		if err != nil {
+			t.Fatalf("GetOrCreate(%s) = %v", tt.id, err)
+		}
+		if company.CompanyID != tt.id || company.Slug != tt.slug {
+			t.Errorf("GetOrCreate(%s) = %+v, want a new company %s", tt.id, company, tt.slug)
+		}
+	}
+	if _, err := members.Get(ctx, acme.CompanyID, "owner@acme.com"); err != nil {
+		t.Errorf("owner of acme = %v", err)
+	}
+
+	again, err := companies.GetOrCreate(ctx, "11111111-1111-1111-1111-111111111111", "owner@acme.com", profile, now)
+	if err != nil || again.Slug != "acme-2" {
+		t.Errorf("GetOrCreate() again = %+v, %v, want the company it made the first time", again, err)
+	}
+	owner, err := members.Get(ctx, "11111111-1111-1111-1111-111111111111", "owner@acme.com")
+	if err != nil || owner.Role != models.Owner {
+		t.Errorf("owner of acme-2 = %+v, %v", owner, err)
+	}
+}
+
+func TestCompaniesCreateSlugFallback(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_, ddbc := ddbtest.New(t)
+	companies := NewCompanies(ddbc, "upfront")
+	profile := models.CompanyProfile{Name: "Acme"}
+
+	for i := 1; i <= maxSlugAttempts; i++ {
+		if _, err := companies.FindOrCreate(ctx, fmt.Sprintf("owner%d@acme.com", i), profile, now); err != nil {
+			t.Fatalf("FindOrCreate() %d = %v", i, err)
+		}
+	}
+	// Every numbered slug is taken, so the company's ID makes one instead of failing.
+	company, err := companies.GetOrCreate(ctx, "33333333-3333-3333-3333-333333333333", "late@acme.com", profile, now)
+	if err != nil || company.Slug != "acme-33333333" {
+		t.Errorf("GetOrCreate() = %+v, %v, want a new company acme-33333333", company, err)
+	}
+}
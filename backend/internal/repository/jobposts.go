@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/josepheid/upfront/api/models"
+)
+
+var (
+	// ErrNotFound is returned when a job post doesn't exist.
+	ErrNotFound = errors.New("job post not found")
+	// ErrConflict is returned when a job post or company changed between being read and saved.
+	ErrConflict = errors.New("item was modified concurrently")
+)
+
+// JobPosts reads and writes job post items in the upfront table.
+type JobPosts struct {
+	ddbc      *dynamodb.Client
+	tableName string
+}
+
+func NewJobPosts(ddbc *dynamodb.Client, tableName string) JobPosts {
+	return JobPosts{
+		ddbc:      ddbc,
+		tableName: tableName,
+	}
+}
+
+// Get returns the job post with id.
+func (s JobPosts) Get(ctx context.Context, id string) (models.JobPostItem, error) {
+	// The post's SK is a timestamp, the SKs of the other items under it, such as reports and audit
+	// entries, start with a letter.
+	keyCondition := expression.KeyEqual(expression.Key("PK"), expression.Value(models.FormatPK(id))).
+		And(expression.KeyLessThan(expression.Key("SK"), expression.Value("a")))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return models.JobPostItem{}, fmt.Errorf("error building expression: %w", err)
+	}
+	data, err := s.ddbc.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ConsistentRead:            aws.Bool(true),
+		Limit:                     aws.Int32(1),
+	})
+	if err != nil {
+		return models.JobPostItem{}, fmt.Errorf("error getting job: %w", err)
+	}
+	if len(data.Items) == 0 {
+		return models.JobPostItem{}, ErrNotFound
+	}
+	item := models.JobPostItem{}
+	if err := attributevalue.UnmarshalMap(data.Items[0], &item); err != nil {
+		return models.JobPostItem{}, fmt.Errorf("error unmarshalling item: %w", err)
+	}
+	return item, nil
+}
+
+// Create writes a new post along with the audit entry of its creation.
+func (s JobPosts) Create(ctx context.Context, item models.JobPostItem, audit models.AuditEntry) error {
+	return s.put(ctx, item, expression.AttributeNotExists(expression.Name("PK")), audit)
+}
+
+// Save writes item, failing with ErrConflict if the stored post has been written since item was
+// read, or its status can't move to item's, see models.Sources. The audit entries are written with
+// the post, or not at all. item's version is moved on to match on success.
+func (s JobPosts) Save(ctx context.Context, item *models.JobPostItem, audit ...models.AuditEntry) error {
+	next := *item
+	next.Version++
+	cond := versionIs(item.Version).And(statusIn(models.Sources(item.Status)))
+	if err := s.put(ctx, next, cond, audit...); err != nil {
+		return err
+	}
+	*item = next
+	return nil
+}
+
+// Transition moves item to status to with a conditional update of its status alone, along with an
+// audit entry of action by actor. It fails with the models.TransitionError when the lifecycle
+// doesn't allow it, and with ErrConflict when the stored post has changed since item was read.
+// item is updated to match on success.
+func (s JobPosts) Transition(ctx context.Context, item *models.JobPostItem, to models.Status, now time.Time, action models.AuditAction, actor string) error {
+	next := *item
+	before := next.Snapshot()
+	if err := next.Transition(to, now); err != nil {
+		return err
+	}
+	next.Version++
+	cond := expression.Name("status").Equal(expression.Value(item.Status)).
+		And(versionIs(item.Version))
+	upd := expression.
+		Set(expression.Name("status"), expression.Value(next.Status)).
+		Set(expression.Name("updatedAt"), expression.Value(next.UpdatedAt)).
+		Set(expression.Name("version"), expression.Value(next.Version))
+	expr, err := expression.NewBuilder().WithCondition(cond).WithUpdate(upd).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	audit, err := auditPut(ctx, s.tableName, next.Audit(action, actor, before, now), 0)
+	if err != nil {
+		return err
+	}
+	_, err = s.ddbc.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Update: &types.Update{
+			TableName: aws.String(s.tableName),
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: item.PK},
+				"SK": &types.AttributeValueMemberS{Value: item.SK},
+			},
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			UpdateExpression:          expr.Update(),
+		}},
+		audit,
+	}})
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 && aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("error updating status: %w", err)
+	}
+	*item = next
+	return nil
+}
+
+// versionIs matches a stored post still at version. Posts written before versions were kept have
+// none, which reads as 0.
+func versionIs(version int64) expression.ConditionBuilder {
+	cond := expression.Name("version").Equal(expression.Value(version))
+	if version == 0 {
+		cond = cond.Or(expression.AttributeNotExists(expression.Name("version")))
+	}
+	return cond
+}
+
+// bumpVersion moves a post's version on in an update that doesn't go through Save, so a Save of a
+// copy read before it fails rather than undoing it.
+func bumpVersion(upd expression.UpdateBuilder) expression.UpdateBuilder {
+	return upd.Add(expression.Name("version"), expression.Value(1))
+}
+
+func statusIn(statuses []models.Status) expression.ConditionBuilder {
+	values := make([]expression.OperandBuilder, len(statuses))
+	for i, status := range statuses {
+		values[i] = expression.Value(status)
+	}
+	return expression.Name("status").In(values[0], values[1:]...)
+}
+
+func (s JobPosts) put(ctx context.Context, item models.JobPostItem, cond expression.ConditionBuilder, audit ...models.AuditEntry) error {
+	data, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("error marshalling job item: %w", err)
+	}
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	items := []types.TransactWriteItem{{Put: &types.Put{
+		TableName:                 aws.String(s.tableName),
+		Item:                      data,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}}
+	for i, entry := range audit {
+		put, err := auditPut(ctx, s.tableName, entry, i)
+		if err != nil {
+			return err
+		}
+		items = append(items, put)
+	}
+	_, err = s.ddbc.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 && aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("error putting item: %w", err)
+	}
+	return nil
+}
+
+// WithStatus returns every job post with status, oldest first.
+func (s JobPosts) WithStatus(ctx context.Context, status models.Status) ([]models.JobPostItem, error) {
+	return s.Find(ctx, expression.Name("status").Equal(expression.Value(status)))
+}
+
+// Find returns every job post matching filter, oldest first.
+func (s JobPosts) Find(ctx context.Context, filter expression.ConditionBuilder) ([]models.JobPostItem, error) {
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(expression.KeyEqual(expression.Key("allJobs"), expression.Value("ALL_JOBS"))).
+		WithFilter(filter).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+	paginator := dynamodb.NewQueryPaginator(s.ddbc, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableName),
+		IndexName:                 aws.String("allJobsIndex"),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+	})
+	posts := []models.JobPostItem{}
+	for paginator.HasMorePages() {
+		data, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying all jobs gsi: %w", err)
+		}
+		page := []models.JobPostItem{}
+		if err := attributevalue.UnmarshalListOfMaps(data.Items, &page); err != nil {
+			return nil, fmt.Errorf("error unmarshalling job posts: %w", err)
+		}
+		posts = append(posts, page...)
+	}
+	return posts, nil
+}
+
+// DeleteDraft removes the draft item, failing with ErrConflict if it has been checked out since it
+// was read, and then the items stored under it except its audit entries, see Delete.
+func (s JobPosts) DeleteDraft(ctx context.Context, item models.JobPostItem) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name("status").Equal(expression.Value(models.Draft))).
+		Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = s.ddbc.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]types.AttributeValue{
+			"PK": &types.AttributeValueMemberS{Value: item.PK},
+			"SK": &types.AttributeValueMemberS{Value: item.SK},
+		},
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting draft: %w", err)
+	}
+	if _, err := s.Delete(ctx, item.JobID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Delete removes the job post with id and the items stored under it, such as its reports. Its audit
+// entries are kept, so the record of what happened to the post outlives it. It returns the number
+// of items deleted.
+func (s JobPosts) Delete(ctx context.Context, id string) (int, error) {
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(expression.KeyEqual(expression.Key("PK"), expression.Value(models.FormatPK(id)))).
+		WithProjection(expression.NamesList(expression.Name("PK"), expression.Name("SK"))).
+		Build()
+	if err != nil {
+		return 0, fmt.Errorf("error building expression: %w", err)
+	}
+	paginator := dynamodb.NewQueryPaginator(s.ddbc, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		ConsistentRead:            aws.Bool(true),
+	})
+	var deleted int
+	for paginator.HasMorePages() {
+		data, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("error querying job partition: %w", err)
+		}
+		for _, key := range data.Items {
+			// Key attributes can't be filtered on in a query, so audit entries are skipped here.
+			if sk, ok := key["SK"].(*types.AttributeValueMemberS); ok && strings.HasPrefix(sk.Value, models.AuditSKPrefix) {
+				continue
+			}
+			_, err := s.ddbc.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+				TableName: aws.String(s.tableName),
+				Key:       key,
+			})
+			if err != nil {
+				return deleted, fmt.Errorf("error deleting item: %w", err)
+			}
+			deleted++
+		}
+	}
+	if deleted == 0 {
+		return 0, ErrNotFound
+	}
+	return deleted, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/ddbtest"
+)
+
+func TestJobPostsDeleteKeepsAudit(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	table, ddbc := ddbtest.New(t)
+	posts := NewJobPosts(ddbc, "upfront")
+	audit := NewAudit(ddbc, "upfront")
+
+	draft := models.NewDraft("job-1", models.JobPostFormProps{LoginEmail: "owner@acme.com"}, now)
+	if err := posts.Create(ctx, draft, draft.Audit(models.AuditCreated, "owner@acme.com", nil, now)); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := posts.DeleteDraft(ctx, draft); err != nil {
+		t.Fatalf("DeleteDraft() = %v", err)
+	}
+	for _, key := range table.Keys() {
+		if !strings.HasPrefix(key[1], models.AuditSKPrefix) {
+			t.Errorf("DeleteDraft() left %v", key)
+		}
+	}
+	entries, err := audit.List(ctx, "job-1")
+	if err != nil || len(entries) != 1 || entries[0].Action != models.AuditCreated {
+		t.Errorf("audit.List() after DeleteDraft() = %+v, %v, want the creation entry", entries, err)
+	}
+
+	// With only its audit entries left the post is gone.
+	if _, err := posts.Delete(ctx, "job-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() of a deleted post = %v, want ErrNotFound", err)
+	}
+	if n := table.Len(); n != 1 {
+		t.Errorf("Delete() of a deleted post left %d items, want the audit entry", n)
+	}
+}
+
+func TestJobPostsSaveVersion(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_, ddbc := ddbtest.New(t)
+	posts := NewJobPosts(ddbc, "upfront")
+	reports := NewReports(ddbc, "upfront")
+
+	item := models.JobPostItem{PK: models.FormatPK("job-1"), SK: now.Format(time.RFC3339), JobID: "job-1", Status: models.Active}
+	if err := posts.Create(ctx, item, item.Audit(models.AuditCreated, "owner@acme.com", nil, now)); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+
+	// Two writers read the post in the same second, so only the version tells their writes apart.
+	first, second := item, item
+	first.Title = "First"
+	if err := posts.Save(ctx, &first); err != nil || first.Version != 1 {
+		t.Fatalf("Save() = %v with version %d, want version 1", err, first.Version)
+	}
+	second.Title = "Second"
+	if err := posts.Save(ctx, &second); !errors.Is(err, ErrConflict) {
+		t.Errorf("Save() of a stale copy = %v, want ErrConflict", err)
+	}
+	if second.Version != 0 {
+		t.Errorf("a failed Save() moved the version to %d", second.Version)
+	}
+
+	// A report counted on the post since it was read makes the save fail rather than drop the count.
+	if err := reports.Create(ctx, first, models.Report{ReporterID: "reporter", Status: models.ReportOpen, CreatedAt: now.Format(time.RFC3339)}); err != nil {
+		t.Fatalf("reports.Create() = %v", err)
+	}
+	first.Title = "Third"
+	if err := posts.Save(ctx, &first); !errors.Is(err, ErrConflict) {
+		t.Errorf("Save() after a report = %v, want ErrConflict", err)
+	}
+
+	got, err := posts.Get(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got.Title != "First" || got.Version != 2 || got.ReportCount != 1 {
+		t.Errorf("Get() = title %q, version %d, %d reports, want First, 2, 1", got.Title, got.Version, got.ReportCount)
+	}
+	if err := posts.Transition(ctx, &got, models.Filled, now, models.AuditFilled, "owner@acme.com"); err != nil || got.Version != 3 {
+		t.Errorf("Transition() = %v with version %d, want version 3", err, got.Version)
+	}
+	if err := posts.Transition(ctx, &first, models.Filled, now, models.AuditFilled, "owner@acme.com"); !errors.Is(err, ErrConflict) {
+		t.Errorf("Transition() of a stale copy = %v, want ErrConflict", err)
+	}
+}
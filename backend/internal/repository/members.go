@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/josepheid/upfront/api/models"
+)
+
+var (
+	// ErrMemberNotFound is returned when a recruiter isn't a member of, or invited to, a company.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrAlreadyMember is returned when inviting a recruiter who has already joined a company.
+	ErrAlreadyMember = errors.New("recruiter is already a member")
+	// ErrLastOwner is returned when a change would leave a company without an active owner.
+	ErrLastOwner = errors.New("company must keep an owner")
+)
+
+// memberIndex is the GSI of memberships by memberEmail.
+const memberIndex = "memberIndex"
+
+// Members reads and writes company memberships and invitations in the upfront table.
+type Members struct {
+	ddbc      *dynamodb.Client
+	tableName string
+}
+
+func NewMembers(ddbc *dynamodb.Client, tableName string) Members {
+	return Members{
+		ddbc:      ddbc,
+		tableName: tableName,
+	}
+}
+
+func memberKey(companyID, email string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: models.FormatCompanyPK(companyID)},
+		"SK": &types.AttributeValueMemberS{Value: models.FormatMemberSK(email)},
+	}
+}
+
+// Get returns email's membership of, or invitation to, a company.
+func (s Members) Get(ctx context.Context, companyID, email string) (models.Member, error) {
+	data, err := s.ddbc.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            memberKey(companyID, email),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return models.Member{}, fmt.Errorf("error getting member: %w", err)
+	}
+	if data.Item == nil {
+		return models.Member{}, ErrMemberNotFound
+	}
+	member := models.Member{}
+	if err := attributevalue.UnmarshalMap(data.Item, &member); err != nil {
+		return models.Member{}, fmt.Errorf("error unmarshalling member: %w", err)
+	}
+	return member, nil
+}
+
+// List returns a company's members and outstanding invitations.
+func (s Members) List(ctx context.Context, companyID string) ([]models.Member, error) {
+	keyCondition := expression.KeyEqual(expression.Key("PK"), expression.Value(models.FormatCompanyPK(companyID))).
+		And(expression.KeyBeginsWith(expression.Key("SK"), models.MemberSKPrefix))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+	return s.query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ConsistentRead:            aws.Bool(true),
+	})
+}
+
+// Memberships returns every company email is a member of or invited to.
+func (s Members) Memberships(ctx context.Context, email string) ([]models.Member, error) {
+	keyCondition := expression.KeyEqual(expression.Key("memberEmail"), expression.Value(strings.ToLower(email)))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+	return s.query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableName),
+		IndexName:                 aws.String(memberIndex),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+	})
+}
+
+func (s Members) query(ctx context.Context, input *dynamodb.QueryInput) ([]models.Member, error) {
+	members := []models.Member{}
+	paginator := dynamodb.NewQueryPaginator(s.ddbc, input)
+	for paginator.HasMorePages() {
+		data, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying members: %w", err)
+		}
+		page := []models.Member{}
+		if err := attributevalue.UnmarshalListOfMaps(data.Items, &page); err != nil {
+			return nil, fmt.Errorf("error unmarshalling members: %w", err)
+		}
+		members = append(members, page...)
+	}
+	return members, nil
+}
+
+// Invite stores an invitation, replacing any earlier invitation to the same recruiter. It fails
+// with ErrAlreadyMember if they have already joined.
+func (s Members) Invite(ctx context.Context, invitation models.Member) error {
+	data, err := attributevalue.MarshalMap(invitation)
+	if err != nil {
+		return fmt.Errorf("error marshalling invitation: %w", err)
+	}
+	cond := expression.AttributeNotExists(expression.Name("PK")).
+		Or(expression.Name("status").Equal(expression.Value(models.Invited)))
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = s.ddbc.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(s.tableName),
+		Item:                      data,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrAlreadyMember
+	}
+	if err != nil {
+		return fmt.Errorf("error putting invitation: %w", err)
+	}
+	return nil
+}
+
+// Accept makes an invitation an active membership, failing with ErrMemberNotFound if there is no
+// invitation that can still be accepted.
+func (s Members) Accept(ctx context.Context, companyID, email string, now time.Time) (models.Member, error) {
+	update := expression.Set(expression.Name("status"), expression.Value(models.ActiveMember)).
+		Set(expression.Name("joinedAt"), expression.Value(now.Format(time.RFC3339))).
+		Remove(expression.Name("ttl"))
+	cond := expression.Name("status").Equal(expression.Value(models.Invited)).
+		And(expression.Name("ttl").GreaterThan(expression.Value(now.Unix())))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(cond).Build()
+	if err != nil {
+		return models.Member{}, fmt.Errorf("error building expression: %w", err)
+	}
+	data, err := s.ddbc.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.tableName),
+		Key:                       memberKey(companyID, email),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return models.Member{}, ErrMemberNotFound
+	}
+	if err != nil {
+		return models.Member{}, fmt.Errorf("error accepting invitation: %w", err)
+	}
+	member := models.Member{}
+	if err := attributevalue.UnmarshalMap(data.Attributes, &member); err != nil {
+		return models.Member{}, fmt.Errorf("error unmarshalling member: %w", err)
+	}
+	return member, nil
+}
+
+// SetRole changes a member's role from previous to role. Demoting an owner fails with ErrLastOwner
+// unless another active owner remains.
+func (s Members) SetRole(ctx context.Context, companyID, email string, previous, role models.Role) error {
+	update := expression.Set(expression.Name("role"), expression.Value(role))
+	cond := expression.Name("role").Equal(expression.Value(previous))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	write := types.TransactWriteItem{Update: &types.Update{
+		TableName:                 aws.String(s.tableName),
+		Key:                       memberKey(companyID, email),
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}
+	return s.changeOwners(ctx, companyID, email, previous == models.Owner && role != models.Owner, write)
+}
+
+// Remove deletes a membership or invitation. Removing an owner fails with ErrLastOwner unless
+// another active owner remains.
+func (s Members) Remove(ctx context.Context, member models.Member) error {
+	cond := expression.Name("role").Equal(expression.Value(member.Role))
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	write := types.TransactWriteItem{Delete: &types.Delete{
+		TableName:                 aws.String(s.tableName),
+		Key:                       memberKey(member.CompanyID, member.Email),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}
+	return s.changeOwners(ctx, member.CompanyID, member.Email, member.Active() && member.Role == models.Owner, write)
+}
+
+// changeOwners applies write to email's membership. When it takes away an owner another active
+// owner is checked in the same transaction, so two owners can't demote each other at once.
+func (s Members) changeOwners(ctx context.Context, companyID, email string, losesOwner bool, write types.TransactWriteItem) error {
+	items := []types.TransactWriteItem{write}
+	if losesOwner {
+		members, err := s.List(ctx, companyID)
+		if err != nil {
+			return err
+		}
+		var other *models.Member
+		for i, m := range members {
+			if m.Email != email && m.Active() && m.Role == models.Owner {
+				other = &members[i]
+				break
+			}
+		}
+		if other == nil {
+			return ErrLastOwner
+		}
+		cond := expression.Name("role").Equal(expression.Value(models.Owner)).
+			And(expression.Name("status").Equal(expression.Value(models.ActiveMember)))
+		expr, err := expression.NewBuilder().WithCondition(cond).Build()
+		if err != nil {
+			return fmt.Errorf("error building expression: %w", err)
+		}
+		items = append(items, types.TransactWriteItem{ConditionCheck: &types.ConditionCheck{
+			TableName:                 aws.String(s.tableName),
+			Key:                       memberKey(companyID, other.Email),
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}})
+	}
+	_, err := s.ddbc.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) {
+		for i, reason := range cancelled.CancellationReasons {
+			if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+				continue
+			}
+			if i == 0 {
+				return ErrConflict
+			}
+			return ErrLastOwner
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error updating member: %w", err)
+	}
+	return nil
+}
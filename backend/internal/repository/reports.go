@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/josepheid/upfront/api/models"
+)
+
+// ErrAlreadyReported is returned when a reporter reports the same job post twice.
+var ErrAlreadyReported = errors.New("job post was already reported by this reporter")
+
+// Reports reads and writes the reports stored under job posts in the upfront table.
+type Reports struct {
+	ddbc      *dynamodb.Client
+	tableName string
+}
+
+func NewReports(ddbc *dynamodb.Client, tableName string) Reports {
+	return Reports{
+		ddbc:      ddbc,
+		tableName: tableName,
+	}
+}
+
+func jobPostKey(item models.JobPostItem) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: item.PK},
+		"SK": &types.AttributeValueMemberS{Value: item.SK},
+	}
+}
+
+// Create stores report against item and counts it on the post. The post's version is moved on too,
+// so a concurrent Save of the post fails rather than losing the count.
+func (s Reports) Create(ctx context.Context, item models.JobPostItem, report models.Report) error {
+	report.PK = item.PK
+	report.SK = models.FormatReportSK(report.ReporterID)
+	report.JobID = item.JobID
+	data, err := attributevalue.MarshalMap(report)
+	if err != nil {
+		return fmt.Errorf("error marshalling report: %w", err)
+	}
+	putExpr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name("PK"))).
+		Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	upd := bumpVersion(expression.Add(expression.Name("reportCount"), expression.Value(1)).
+		Add(expression.Name("openReportCount"), expression.Value(1)))
+	updExpr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name("PK"))).
+		WithUpdate(upd).
+		Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = s.ddbc.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:                 aws.String(s.tableName),
+				Item:                      data,
+				ConditionExpression:       putExpr.Condition(),
+				ExpressionAttributeNames:  putExpr.Names(),
+				ExpressionAttributeValues: putExpr.Values(),
+			}},
+			{Update: &types.Update{
+				TableName:                 aws.String(s.tableName),
+				Key:                       jobPostKey(item),
+				ConditionExpression:       updExpr.Condition(),
+				ExpressionAttributeNames:  updExpr.Names(),
+				ExpressionAttributeValues: updExpr.Values(),
+				UpdateExpression:          updExpr.Update(),
+			}},
+		},
+	})
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) {
+		for i, reason := range cancelled.CancellationReasons {
+			if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+				continue
+			}
+			if i == 0 {
+				return ErrAlreadyReported
+			}
+			return ErrNotFound
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error storing report: %w", err)
+	}
+	return nil
+}
+
+// List returns every report of the job post with id, oldest first.
+func (s Reports) List(ctx context.Context, id string) ([]models.Report, error) {
+	keyCondition := expression.KeyEqual(expression.Key("PK"), expression.Value(models.FormatPK(id))).
+		And(expression.KeyBeginsWith(expression.Key("SK"), models.ReportSKPrefix))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+	paginator := dynamodb.NewQueryPaginator(s.ddbc, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ConsistentRead:            aws.Bool(true),
+	})
+	reports := []models.Report{}
+	for paginator.HasMorePages() {
+		data, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying reports: %w", err)
+		}
+		page := []models.Report{}
+		if err := attributevalue.UnmarshalListOfMaps(data.Items, &page); err != nil {
+			return nil, fmt.Errorf("error unmarshalling reports: %w", err)
+		}
+		reports = append(reports, page...)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].CreatedAt < reports[j].CreatedAt
+	})
+	return reports, nil
+}
+
+// Resolve closes every open report of item with outcome and clears its open count. It returns the
+// reports it resolved.
+func (s Reports) Resolve(ctx context.Context, item models.JobPostItem, outcome models.ReportOutcome, note, by string, now time.Time) ([]models.Report, error) {
+	reports, err := s.List(ctx, item.JobID)
+	if err != nil {
+		return nil, err
+	}
+	resolved := []models.Report{}
+	for _, report := range reports {
+		if report.Status != models.ReportOpen {
+			continue
+		}
+		report.Status = models.ReportResolved
+		report.Outcome = outcome
+		report.Note = note
+		report.ResolvedBy = by
+		report.ResolvedAt = now.Format(time.RFC3339)
+		cond := expression.Name("status").Equal(expression.Value(models.ReportOpen))
+		upd := expression.Set(expression.Name("status"), expression.Value(report.Status)).
+			Set(expression.Name("outcome"), expression.Value(report.Outcome)).
+			Set(expression.Name("resolvedBy"), expression.Value(report.ResolvedBy)).
+			Set(expression.Name("resolvedAt"), expression.Value(report.ResolvedAt))
+		if note != "" {
+			upd = upd.Set(expression.Name("note"), expression.Value(note))
+		}
+		expr, err := expression.NewBuilder().WithCondition(cond).WithUpdate(upd).Build()
+		if err != nil {
+			return nil, fmt.Errorf("error building expression: %w", err)
+		}
+		_, err = s.ddbc.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(s.tableName),
+			Key: map[string]types.AttributeValue{
+				"PK": &types.AttributeValueMemberS{Value: report.PK},
+				"SK": &types.AttributeValueMemberS{Value: report.SK},
+			},
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			UpdateExpression:          expr.Update(),
+		})
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			// Another admin resolved it first.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error resolving report: %w", err)
+		}
+		resolved = append(resolved, report)
+	}
+
+	if len(resolved) == 0 {
+		return resolved, nil
+	}
+	// Reports made since the list are left open and counted.
+	upd := bumpVersion(expression.Add(expression.Name("openReportCount"), expression.Value(-len(resolved))))
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name("PK"))).
+		WithUpdate(upd).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = s.ddbc.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.tableName),
+		Key:                       jobPostKey(item),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error updating open report count: %w", err)
+	}
+	return resolved, nil
+}
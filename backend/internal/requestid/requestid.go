@@ -0,0 +1,102 @@
+package requestid
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v80"
+)
+
+// Header carries the request ID on incoming requests, responses and outbound calls.
+const Header = "X-Request-ID"
+
+// maxLength stops clients from filling the logs with oversized IDs.
+const maxLength = 128
+
+type idKey struct{}
+type loggerKey struct{}
+
+// Middleware gives every request an ID and a logger that includes it. A valid X-Request-ID header
+// from the client is kept so calls can be correlated across services, otherwise the API Gateway
+// request ID is used, falling back to a new UUID.
+func Middleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		apiGatewayRequestID := ""
+		if apiGatewayContext, ok := core.GetAPIGatewayContextFromContext(r.Context()); ok {
+			apiGatewayRequestID = apiGatewayContext.RequestID
+		}
+		if !valid(id) {
+			id = apiGatewayRequestID
+		}
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		l := logger.With("requestId", id)
+		if apiGatewayRequestID != "" && apiGatewayRequestID != id {
+			l = l.With("apiGatewayRequestId", apiGatewayRequestID)
+		}
+
+		w.Header().Set(Header, id)
+		ctx := context.WithValue(r.Context(), idKey{}, id)
+		ctx = context.WithValue(ctx, loggerKey{}, l)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// FromContext returns the request ID, or an empty string outside of Middleware.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
+
+// Logger returns the request scoped logger, or fallback outside of Middleware.
+func Logger(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return fallback
+}
+
+// AWSAPIOptions adds the request ID header to outbound AWS SDK calls, pass them to
+// config.WithAPIOptions when loading the AWS config.
+var AWSAPIOptions = []func(*middleware.Stack) error{
+	func(stack *middleware.Stack) error {
+		return stack.Build.Add(middleware.BuildMiddlewareFunc("RequestID", func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+			if req, ok := in.Request.(*smithyhttp.Request); ok {
+				if id := FromContext(ctx); id != "" {
+					req.Header.Set(Header, id)
+				}
+			}
+			return next.HandleBuild(ctx, in)
+		}), middleware.After)
+	},
+}
+
+// Stripe adds the request ID header to an outbound Stripe call.
+func Stripe(ctx context.Context, params *stripe.Params) {
+	if id := FromContext(ctx); id != "" {
+		if params.Headers == nil {
+			params.Headers = http.Header{}
+		}
+		params.Headers.Set(Header, id)
+	}
+}
+
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,199 @@
+package respond
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Code is a stable, machine readable error code. Clients match on these, so existing codes must
+// never be renamed or reused for a different meaning.
+type Code string
+
+const (
+	CodeInvalidRequestBody   Code = "invalid_request_body"
+	CodeValidationFailed     Code = "validation_failed"
+	CodeOriginNotAllowed     Code = "origin_not_allowed"
+	CodeRouteNotFound        Code = "route_not_found"
+	CodeJobNotFound          Code = "job_not_found"
+	CodePaymentIncomplete    Code = "payment_incomplete"
+	CodeIdempotencyKeyReused Code = "idempotency_key_reused"
+	CodeRequestInProgress    Code = "request_in_progress"
+	CodeUpstreamTimeout      Code = "upstream_timeout"
+	CodeInternal             Code = "internal_error"
+	CodeUnauthenticated      Code = "unauthenticated"
+	CodeForbidden            Code = "forbidden"
+	CodeInvalidJobStatus     Code = "invalid_job_status"
+	CodeConcurrentUpdate     Code = "concurrent_update"
+	CodeInvoiceNotFound      Code = "invoice_not_found"
+	CodeCompanyNotFound      Code = "company_not_found"
+	CodeMemberNotFound       Code = "member_not_found"
+	CodeAlreadyMember        Code = "already_member"
+	CodeLastOwner            Code = "last_owner"
+	CodeAlreadyReported      Code = "already_reported"
+	CodeRateLimited          Code = "rate_limited"
+	CodePauseLimitReached    Code = "pause_limit_reached"
+	CodeDraftNotFound        Code = "draft_not_found"
+)
+
+// Codes is the catalogue of every error code the API can return.
+var Codes = []Code{
+	CodeInvalidRequestBody,
+	CodeValidationFailed,
+	CodeOriginNotAllowed,
+	CodeRouteNotFound,
+	CodeJobNotFound,
+	CodePaymentIncomplete,
+	CodeIdempotencyKeyReused,
+	CodeRequestInProgress,
+	CodeUpstreamTimeout,
+	CodeInternal,
+	CodeUnauthenticated,
+	CodeForbidden,
+	CodeInvalidJobStatus,
+	CodeConcurrentUpdate,
+	CodeInvoiceNotFound,
+	CodeCompanyNotFound,
+	CodeMemberNotFound,
+	CodeAlreadyMember,
+	CodeLastOwner,
+	CodeAlreadyReported,
+	CodeRateLimited,
+	CodePauseLimitReached,
+	CodeDraftNotFound,
+}
+
+// NewError creates an Error, prefer the typed constructors below.
+func NewError(code Code, status int, message string, issues ...string) Error {
+	return Error{
+		Code:       code,
+		Message:    message,
+		StatusCode: status,
+		Issues:     issues,
+	}
+}
+
+// InvalidRequestBody is returned when the request body can't be decoded.
+func InvalidRequestBody() Error {
+	return NewError(CodeInvalidRequestBody, http.StatusBadRequest, "The request body is not valid JSON.")
+}
+
+// ValidationFailed is returned when the request is well formed but its values are not acceptable.
+func ValidationFailed(issues ...string) Error {
+	return NewError(CodeValidationFailed, http.StatusBadRequest, "The request was invalid.", issues...)
+}
+
+// OriginNotAllowed is returned when a redirect or link would point at an origin that isn't allowed.
+func OriginNotAllowed(issues ...string) Error {
+	return NewError(CodeOriginNotAllowed, http.StatusBadRequest, "The origin is not allowed.", issues...)
+}
+
+// RouteNotFound is returned for paths the API doesn't serve.
+func RouteNotFound() Error {
+	return NewError(CodeRouteNotFound, http.StatusNotFound, "The route does not exist.")
+}
+
+// JobNotFound is returned when a job post doesn't exist.
+func JobNotFound() Error {
+	return NewError(CodeJobNotFound, http.StatusNotFound, "The job post was not found.")
+}
+
+// DraftNotFound is returned when a draft doesn't exist, or has been checked out already.
+func DraftNotFound() Error {
+	return NewError(CodeDraftNotFound, http.StatusNotFound, "The draft could not be found.")
+}
+
+// InvoiceNotFound is returned when a job post has no paid purchase to invoice with the given ID.
+func InvoiceNotFound() Error {
+	return NewError(CodeInvoiceNotFound, http.StatusNotFound, "The invoice was not found.")
+}
+
+// CompanyNotFound is returned when a company doesn't exist.
+func CompanyNotFound() Error {
+	return NewError(CodeCompanyNotFound, http.StatusNotFound, "The company was not found.")
+}
+
+// MemberNotFound is returned when a recruiter isn't a member of, or invited to, a company.
+func MemberNotFound() Error {
+	return NewError(CodeMemberNotFound, http.StatusNotFound, "The member or invitation was not found.")
+}
+
+// AlreadyMember is returned when inviting a recruiter who has already joined the company.
+func AlreadyMember() Error {
+	return NewError(CodeAlreadyMember, http.StatusConflict, "The recruiter is already a member of the company.")
+}
+
+// LastOwner is returned when a change would leave a company without an owner.
+func LastOwner() Error {
+	return NewError(CodeLastOwner, http.StatusConflict, "The company must keep at least one owner.")
+}
+
+// AlreadyReported is returned when a visitor reports a job post they have already reported.
+func AlreadyReported() Error {
+	return NewError(CodeAlreadyReported, http.StatusConflict, "You have already reported this job post.")
+}
+
+// RateLimited is returned when a caller has made too many requests, they can retry later.
+func RateLimited() Error {
+	return NewError(CodeRateLimited, http.StatusTooManyRequests, "Too many requests, please try again later.")
+}
+
+// PauseLimitReached is returned when pausing a job post that has been paused for as long as its
+// plan allows.
+func PauseLimitReached() Error {
+	return NewError(CodePauseLimitReached, http.StatusConflict, "The job post has used all of its pause time.")
+}
+
+// PaymentIncomplete is returned when the checkout for a job post hasn't been paid.
+func PaymentIncomplete() Error {
+	return NewError(CodePaymentIncomplete, http.StatusPaymentRequired, "The payment has not been completed.")
+}
+
+// IdempotencyKeyReused is returned when an Idempotency-Key is sent again with a different request.
+func IdempotencyKeyReused() Error {
+	return NewError(CodeIdempotencyKeyReused, http.StatusUnprocessableEntity, "The idempotency key was already used for a different request.")
+}
+
+// RequestInProgress is returned when a retry arrives before the original request has finished.
+func RequestInProgress() Error {
+	return NewError(CodeRequestInProgress, http.StatusConflict, "A request with this idempotency key is still in progress, please retry shortly.")
+}
+
+// Unauthenticated is returned when a recruiter only endpoint is called without a valid session.
+func Unauthenticated() Error {
+	return NewError(CodeUnauthenticated, http.StatusUnauthorized, "You need to sign in to do this.")
+}
+
+// Forbidden is returned when the caller is signed in but doesn't own the resource.
+func Forbidden() Error {
+	return NewError(CodeForbidden, http.StatusForbidden, "You are not allowed to do this.")
+}
+
+// InvalidJobStatus is returned when a job post's status doesn't allow the requested action.
+func InvalidJobStatus(issues ...string) Error {
+	return NewError(CodeInvalidJobStatus, http.StatusConflict, "The job post can't be changed in its current status.", issues...)
+}
+
+// ConcurrentUpdate is returned when a job post changed while a request was updating it.
+func ConcurrentUpdate() Error {
+	return NewError(CodeConcurrentUpdate, http.StatusConflict, "The job post was changed by another request, please retry.")
+}
+
+// Internal is returned for failures the client can't do anything about, the detail is only logged.
+func Internal() Error {
+	return NewError(CodeInternal, http.StatusInternalServerError, "Something went wrong, please try again later.")
+}
+
+// UpstreamTimeout is returned when a dependency didn't answer within its timeout budget.
+func UpstreamTimeout() Error {
+	return NewError(CodeUpstreamTimeout, http.StatusGatewayTimeout, "A dependency took too long to respond, please try again.")
+}
+
+// Upstream maps an error from an outbound call to UpstreamTimeout when a deadline was exceeded
+// and to Internal otherwise.
+func Upstream(err error) Error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return UpstreamTimeout()
+	}
+	return Internal()
+}
@@ -0,0 +1,82 @@
+package respond
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestErrorConstructors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    Error
+		code   Code
+		status int
+		issues []string
+	}{
+		{name: "InvalidRequestBody", err: InvalidRequestBody(), code: CodeInvalidRequestBody, status: http.StatusBadRequest},
+		{name: "ValidationFailed", err: ValidationFailed("title is required"), code: CodeValidationFailed, status: http.StatusBadRequest, issues: []string{"title is required"}},
+		{name: "OriginNotAllowed", err: OriginNotAllowed("origin is not allowed"), code: CodeOriginNotAllowed, status: http.StatusBadRequest, issues: []string{"origin is not allowed"}},
+		{name: "RouteNotFound", err: RouteNotFound(), code: CodeRouteNotFound, status: http.StatusNotFound},
+		{name: "JobNotFound", err: JobNotFound(), code: CodeJobNotFound, status: http.StatusNotFound},
+		{name: "DraftNotFound", err: DraftNotFound(), code: CodeDraftNotFound, status: http.StatusNotFound},
+		{name: "InvoiceNotFound", err: InvoiceNotFound(), code: CodeInvoiceNotFound, status: http.StatusNotFound},
+		{name: "CompanyNotFound", err: CompanyNotFound(), code: CodeCompanyNotFound, status: http.StatusNotFound},
+		{name: "MemberNotFound", err: MemberNotFound(), code: CodeMemberNotFound, status: http.StatusNotFound},
+		{name: "AlreadyMember", err: AlreadyMember(), code: CodeAlreadyMember, status: http.StatusConflict},
+		{name: "LastOwner", err: LastOwner(), code: CodeLastOwner, status: http.StatusConflict},
+		{name: "AlreadyReported", err: AlreadyReported(), code: CodeAlreadyReported, status: http.StatusConflict},
+		{name: "RateLimited", err: RateLimited(), code: CodeRateLimited, status: http.StatusTooManyRequests},
+		{name: "PauseLimitReached", err: PauseLimitReached(), code: CodePauseLimitReached, status: http.StatusConflict},
+		{name: "PaymentIncomplete", err: PaymentIncomplete(), code: CodePaymentIncomplete, status: http.StatusPaymentRequired},
+		{name: "IdempotencyKeyReused", err: IdempotencyKeyReused(), code: CodeIdempotencyKeyReused, status: http.StatusUnprocessableEntity},
+		{name: "RequestInProgress", err: RequestInProgress(), code: CodeRequestInProgress, status: http.StatusConflict},
+		{name: "Unauthenticated", err: Unauthenticated(), code: CodeUnauthenticated, status: http.StatusUnauthorized},
+		{name: "Forbidden", err: Forbidden(), code: CodeForbidden, status: http.StatusForbidden},
+		{name: "InvalidJobStatus", err: InvalidJobStatus("the job post is already filled"), code: CodeInvalidJobStatus, status: http.StatusConflict, issues: []string{"the job post is already filled"}},
+		{name: "ConcurrentUpdate", err: ConcurrentUpdate(), code: CodeConcurrentUpdate, status: http.StatusConflict},
+		{name: "Internal", err: Internal(), code: CodeInternal, status: http.StatusInternalServerError},
+		{name: "UpstreamTimeout", err: UpstreamTimeout(), code: CodeUpstreamTimeout, status: http.StatusGatewayTimeout},
+		{name: "Upstream deadline", err: Upstream(fmt.Errorf("error getting job: %w", context.DeadlineExceeded)), code: CodeUpstreamTimeout, status: http.StatusGatewayTimeout},
+		{name: "Upstream failure", err: Upstream(errors.New("connection reset")), code: CodeInternal, status: http.StatusInternalServerError},
+	}
+	covered := map[Code]bool{}
+	for _, tt := range tests {
+		covered[tt.code] = true
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Code != tt.code || tt.err.StatusCode != tt.status || tt.err.Message == "" || !reflect.DeepEqual(tt.err.Issues, tt.issues) {
+				t.Errorf("%s() = %+v, want code %s, status %d and issues %v", tt.name, tt.err, tt.code, tt.status, tt.issues)
+			}
+
+			// WithError sends the constructor's status and code in both formats.
+			for _, accept := range []string{"application/json", "application/problem+json"} {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header.Set("Accept", accept)
+				w := httptest.NewRecorder()
+				WithError(w, r, tt.err)
+				var body struct {
+					Code       Code `json:"code"`
+					StatusCode int  `json:"statusCode"`
+					Status     int  `json:"status"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decoding %s: %v", w.Body.String(), err)
+				}
+				if w.Code != tt.status || body.Code != tt.code || max(body.StatusCode, body.Status) != tt.status {
+					t.Errorf("WithError() for %s wrote %d %s", accept, w.Code, w.Body.String())
+				}
+			}
+		})
+	}
+	// Every code in the catalogue has a constructor, so a new one can't be added without a test.
+	for _, code := range Codes {
+		if !covered[code] {
+			t.Errorf("no constructor tested for %s", code)
+		}
+	}
+}
@@ -2,34 +2,89 @@ package respond
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
+	"strings"
+
+	"github.com/josepheid/upfront/internal/requestid"
 )
 
 func WithJSON(w http.ResponseWriter, v any, status int) {
 	w.Header().Add("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		WithError(w, "failed to encode", http.StatusInternalServerError)
+		WithError(w, nil, Internal())
 	}
 }
 
 // Error is a standard error response.
 type Error struct {
+	// Code is a stable, machine readable identifier for the error.
+	Code Code `json:"code" example:"validation_failed"`
 	// Message is the error message.
 	Message string `json:"message" example:"The request was invalid."`
 	// StatusCode is the HTTP status code.
 	StatusCode int `json:"statusCode" example:"400"`
 	// Issues is a list of issues with the request. This is optional.
 	Issues []string `json:"issues,omitempty" example:"The request was invalid."`
+	// RequestID identifies the request in the logs.
+	RequestID string `json:"requestId,omitempty" example:"c0a8012e-7d4b-4f3a-9a57-6f1e2f1d8b11"`
 }
 
-func WithError(w http.ResponseWriter, msg string, status int, issues ...string) {
-	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(status)
-	v := Error{
-		Message:    msg,
-		StatusCode: status,
-		Issues:     issues,
+// Problem is an RFC 7807 problem details response, it is sent instead of Error when the client
+// accepts application/problem+json.
+type Problem struct {
+	// Type is a URI identifying the error code.
+	Type string `json:"type" example:"urn:upfront:error:validation_failed"`
+	// Title is the error message.
+	Title string `json:"title" example:"The request was invalid."`
+	// Status is the HTTP status code.
+	Status int `json:"status" example:"400"`
+	// Code is a stable, machine readable identifier for the error.
+	Code Code `json:"code" example:"validation_failed"`
+	// Issues is a list of issues with the request. This is optional.
+	Issues []string `json:"issues,omitempty" example:"The request was invalid."`
+	// RequestID identifies the request in the logs.
+	RequestID string `json:"requestId,omitempty" example:"c0a8012e-7d4b-4f3a-9a57-6f1e2f1d8b11"`
+}
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeProblem = "application/problem+json"
+)
+
+// WithError writes e as JSON, or as problem+json if r accepts it. Handlers should log the
+// underlying error themselves, e is what the client sees.
+func WithError(w http.ResponseWriter, r *http.Request, e Error) {
+	if r != nil {
+		e.RequestID = requestid.FromContext(r.Context())
+	}
+	if r != nil && acceptsProblem(r.Header.Get("Accept")) {
+		w.Header().Add("Content-Type", contentTypeProblem)
+		w.WriteHeader(e.StatusCode)
+		json.NewEncoder(w).Encode(Problem{
+			Type:      "urn:upfront:error:" + string(e.Code),
+			Title:     e.Message,
+			Status:    e.StatusCode,
+			Code:      e.Code,
+			Issues:    e.Issues,
+			RequestID: e.RequestID,
+		})
+		return
+	}
+	w.Header().Add("Content-Type", contentTypeJSON)
+	w.WriteHeader(e.StatusCode)
+	json.NewEncoder(w).Encode(e)
+}
+
+// acceptsProblem reports whether an Accept header explicitly asks for problem+json.
+func acceptsProblem(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil || mediaType != contentTypeProblem {
+			continue
+		}
+		return params["q"] != "0" && params["q"] != "0.0"
 	}
-	json.NewEncoder(w).Encode(v)
+	return false
 }
@@ -0,0 +1,144 @@
+package respond
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/josepheid/upfront/internal/requestid"
+)
+
+func TestAcceptsProblem(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: "", want: false},
+		{accept: "application/json", want: false},
+		{accept: "*/*", want: false},
+		{accept: "application/*", want: false},
+		{accept: "application/problem+json", want: true},
+		{accept: "Application/Problem+JSON", want: true},
+		{accept: "application/json, application/problem+json", want: true},
+		{accept: "application/problem+json; q=0.5, application/json", want: true},
+		{accept: "application/problem+json;q=0", want: false},
+		{accept: "application/problem+json;q=0.0", want: false},
+		{accept: "not a media type, application/problem+json", want: true},
+	}
+	for _, tt := range tests {
+		if got := acceptsProblem(tt.accept); got != tt.want {
+			t.Errorf("acceptsProblem(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+// serveError responds with e to a request with accept, through requestid.Middleware with the
+// request ID id.
+func serveError(t *testing.T, e Error, accept, id string) *httptest.ResponseRecorder {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := requestid.Middleware(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WithError(w, r, e)
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/upfront/job-posts/1", nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	r.Header.Set(requestid.Header, id)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestWithError(t *testing.T) {
+	e := ValidationFailed("title is required")
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		want        any
+	}{
+		{
+			name:        "problem+json",
+			accept:      "application/problem+json",
+			contentType: contentTypeProblem,
+			want: &Problem{
+				Type:      "urn:upfront:error:validation_failed",
+				Title:     "The request was invalid.",
+				Status:    http.StatusBadRequest,
+				Code:      CodeValidationFailed,
+				Issues:    []string{"title is required"},
+				RequestID: "req-1",
+			},
+		},
+		{
+			name:        "any type",
+			accept:      "*/*",
+			contentType: contentTypeJSON,
+			want: &Error{
+				Code:       CodeValidationFailed,
+				Message:    "The request was invalid.",
+				StatusCode: http.StatusBadRequest,
+				Issues:     []string{"title is required"},
+				RequestID:  "req-1",
+			},
+		},
+		{
+			name:        "plain json",
+			accept:      "application/json",
+			contentType: contentTypeJSON,
+			want: &Error{
+				Code:       CodeValidationFailed,
+				Message:    "The request was invalid.",
+				StatusCode: http.StatusBadRequest,
+				Issues:     []string{"title is required"},
+				RequestID:  "req-1",
+			},
+		},
+		{
+			name:        "no accept header",
+			contentType: contentTypeJSON,
+			want: &Error{
+				Code:       CodeValidationFailed,
+				Message:    "The request was invalid.",
+				StatusCode: http.StatusBadRequest,
+				Issues:     []string{"title is required"},
+				RequestID:  "req-1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveError(t, e, tt.accept, "req-1")
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			got := reflect.New(reflect.TypeOf(tt.want).Elem()).Interface()
+			if err := json.Unmarshal(w.Body.Bytes(), got); err != nil {
+				t.Fatalf("decoding %s: %v", w.Body.String(), err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("body = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithErrorWithoutRequest(t *testing.T) {
+	w := httptest.NewRecorder()
+	WithError(w, nil, Internal())
+	var got Error
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body.String(), err)
+	}
+	if w.Code != http.StatusInternalServerError || got.Code != CodeInternal || got.RequestID != "" {
+		t.Errorf("WithError() without a request wrote %d %+v", w.Code, got)
+	}
+}
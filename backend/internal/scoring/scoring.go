@@ -0,0 +1,397 @@
+package scoring
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/josepheid/upfront/api/models"
+)
+
+// defaultRules are used unless SCORING_RULES_FILE points at another rules file.
+//
+//go:embed rules.json
+var defaultRules []byte
+
+// Rules configure the scorer, each rule adds its weight to a post's score when it matches.
+type Rules struct {
+	// ReviewThreshold is the score at which a post is held for review.
+	ReviewThreshold int           `json:"reviewThreshold"`
+	BannedPhrases   BannedPhrases `json:"bannedPhrases"`
+	Links           Links         `json:"links"`
+	Salary          Salary        `json:"salary"`
+	Domains         Domains       `json:"domains"`
+	Shouting        Shouting      `json:"shouting"`
+	Emoji           Emoji         `json:"emoji"`
+}
+
+// BannedPhrases match anywhere in the title, description or how to apply, ignoring case. The
+// weight is added once for each phrase found.
+type BannedPhrases struct {
+	Weight  int      `json:"weight"`
+	Phrases []string `json:"phrases"`
+}
+
+// Links check the links in how to apply. Weight is added for each link to one of Domains, such as
+// URL shorteners and messaging apps, or to an IP address, and InsecureWeight for each http link.
+type Links struct {
+	Weight         int      `json:"weight"`
+	InsecureWeight int      `json:"insecureWeight"`
+	Domains        []string `json:"domains"`
+}
+
+// Salary compares posts with the bands for their title. Weight is added when the maximum salary is
+// more than Tolerance times the band's maximum, or the minimum salary is less than the band's
+// minimum divided by Tolerance.
+type Salary struct {
+	Weight    int          `json:"weight"`
+	Tolerance float64      `json:"tolerance"`
+	Bands     []SalaryBand `json:"bands"`
+}
+
+// SalaryBand is the expected salary range for titles containing any of Keywords, in Currency.
+type SalaryBand struct {
+	Keywords []string        `json:"keywords"`
+	Currency models.Currency `json:"currency"`
+	Min      int             `json:"min"`
+	Max      int             `json:"max"`
+}
+
+// Domains compare the company website with the recruiter's email address. Weight is added when
+// their domains differ, and FreeEmailWeight instead when the email is from a free provider.
+type Domains struct {
+	Weight           int      `json:"weight"`
+	FreeEmailWeight  int      `json:"freeEmailWeight"`
+	FreeEmailDomains []string `json:"freeEmailDomains"`
+}
+
+// Shouting adds Weight when more than MaxCapsRatio of the letters in the title and description are
+// capitals, for posts with at least MinLetters letters.
+type Shouting struct {
+	Weight       int     `json:"weight"`
+	MaxCapsRatio float64 `json:"maxCapsRatio"`
+	MinLetters   int     `json:"minLetters"`
+}
+
+// Emoji adds Weight when the title and description contain more than MaxEmoji emoji.
+type Emoji struct {
+	Weight   int `json:"weight"`
+	MaxEmoji int `json:"maxEmoji"`
+}
+
+// Names of the rules, used in findings.
+const (
+	RuleBannedPhrase   = "bannedPhrase"
+	RuleSuspiciousLink = "suspiciousLink"
+	RuleSalary         = "salary"
+	RuleDomainMismatch = "domainMismatch"
+	RuleShouting       = "shouting"
+	RuleEmoji          = "emoji"
+)
+
+// Parse reads rules from JSON, fields that are missing are zero so their rules never match.
+func Parse(data []byte) (Rules, error) {
+	var rules Rules
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return Rules{}, fmt.Errorf("error decoding rules: %w", err)
+	}
+	if err := rules.validate(); err != nil {
+		return Rules{}, err
+	}
+	return rules, nil
+}
+
+func (r Rules) validate() error {
+	var errs []error
+	if r.ReviewThreshold <= 0 {
+		errs = append(errs, errors.New("reviewThreshold must be positive"))
+	}
+	for name, w := range map[string]int{
+		"bannedPhrases.weight":    r.BannedPhrases.Weight,
+		"links.weight":            r.Links.Weight,
+		"links.insecureWeight":    r.Links.InsecureWeight,
+		"salary.weight":           r.Salary.Weight,
+		"domains.weight":          r.Domains.Weight,
+		"domains.freeEmailWeight": r.Domains.FreeEmailWeight,
+		"shouting.weight":         r.Shouting.Weight,
+		"emoji.weight":            r.Emoji.Weight,
+	} {
+		if w < 0 {
+			errs = append(errs, fmt.Errorf("%s can't be negative", name))
+		}
+	}
+	if r.Salary.Tolerance != 0 && r.Salary.Tolerance < 1 {
+		errs = append(errs, errors.New("salary.tolerance must be at least 1"))
+	}
+	for i, b := range r.Salary.Bands {
+		if len(b.Keywords) == 0 || b.Min < 0 || b.Max < b.Min {
+			errs = append(errs, fmt.Errorf("salary.bands[%d] needs keywords and 0 <= min <= max", i))
+		}
+	}
+	if r.Shouting.MaxCapsRatio < 0 || r.Shouting.MaxCapsRatio > 1 {
+		errs = append(errs, errors.New("shouting.maxCapsRatio must be between 0 and 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// FromEnv loads the rules file named by the SCORING_RULES_FILE environment variable, or the
+// default rules when it is unset.
+func FromEnv() (Rules, error) {
+	path := os.Getenv("SCORING_RULES_FILE")
+	if path == "" {
+		return Parse(defaultRules)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("error reading SCORING_RULES_FILE: %w", err)
+	}
+	return Parse(data)
+}
+
+// Scorer scores posts with a set of rules.
+type Scorer struct {
+	rules Rules
+}
+
+func NewScorer(rules Rules) Scorer {
+	return Scorer{rules: rules}
+}
+
+// Score runs every rule over post.
+func (s Scorer) Score(post models.JobPostFormProps, now time.Time) models.ContentScore {
+	var findings []models.Finding
+	for _, rule := range []func(models.JobPostFormProps) []models.Finding{
+		s.bannedPhrases,
+		s.links,
+		s.salary,
+		s.domains,
+		s.shouting,
+		s.emoji,
+	} {
+		findings = append(findings, rule(post)...)
+	}
+	score := models.ContentScore{
+		Findings: findings,
+		ScoredAt: now.Format(time.RFC3339),
+	}
+	for _, f := range findings {
+		score.Score += f.Weight
+	}
+	score.NeedsReview = score.Score >= s.rules.ReviewThreshold
+	return score
+}
+
+func (s Scorer) bannedPhrases(post models.JobPostFormProps) []models.Finding {
+	r := s.rules.BannedPhrases
+	if r.Weight == 0 {
+		return nil
+	}
+	text := strings.ToLower(strings.Join([]string{post.Title, post.Description, post.HowToApply}, "\n"))
+	var findings []models.Finding
+	for _, phrase := range r.Phrases {
+		if strings.Contains(text, strings.ToLower(phrase)) {
+			findings = append(findings, models.Finding{
+				Rule:   RuleBannedPhrase,
+				Detail: fmt.Sprintf("contains %q", phrase),
+				Weight: r.Weight,
+			})
+		}
+	}
+	return findings
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s"'<>()]+`)
+
+func (s Scorer) links(post models.JobPostFormProps) []models.Finding {
+	r := s.rules.Links
+	var findings []models.Finding
+	for _, link := range linkPattern.FindAllString(post.HowToApply, -1) {
+		raw := link
+		if !strings.Contains(strings.ToLower(link), "://") {
+			raw = "https://" + link
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+		switch {
+		case net.ParseIP(host) != nil && r.Weight > 0:
+			findings = append(findings, models.Finding{
+				Rule:   RuleSuspiciousLink,
+				Detail: fmt.Sprintf("how to apply links to the IP address %s", host),
+				Weight: r.Weight,
+			})
+		case slices.ContainsFunc(r.Domains, func(d string) bool { return sameOrSubdomain(host, d) }) && r.Weight > 0:
+			findings = append(findings, models.Finding{
+				Rule:   RuleSuspiciousLink,
+				Detail: fmt.Sprintf("how to apply links to %s", host),
+				Weight: r.Weight,
+			})
+		case strings.EqualFold(u.Scheme, "http") && r.InsecureWeight > 0:
+			findings = append(findings, models.Finding{
+				Rule:   RuleSuspiciousLink,
+				Detail: fmt.Sprintf("how to apply links to %s without https", host),
+				Weight: r.InsecureWeight,
+			})
+		}
+	}
+	return findings
+}
+
+func (s Scorer) salary(post models.JobPostFormProps) []models.Finding {
+	r := s.rules.Salary
+	if r.Weight == 0 || post.MaxSalary <= 0 {
+		return nil
+	}
+	tolerance := r.Tolerance
+	if tolerance == 0 {
+		tolerance = 1
+	}
+	title := strings.ToLower(post.Title)
+	for _, band := range r.Bands {
+		if band.Currency != post.Currency || !slices.ContainsFunc(band.Keywords, func(k string) bool {
+			return containsWord(title, strings.ToLower(k))
+		}) {
+			continue
+		}
+		var detail string
+		switch {
+		case float64(post.MaxSalary) > float64(band.Max)*tolerance:
+			detail = fmt.Sprintf("maximum salary %d %s is far above the %d to %d expected for the title", post.MaxSalary, post.Currency, band.Min, band.Max)
+		case float64(post.MinSalary) < float64(band.Min)/tolerance:
+			detail = fmt.Sprintf("minimum salary %d %s is far below the %d to %d expected for the title", post.MinSalary, post.Currency, band.Min, band.Max)
+		default:
+			continue
+		}
+		// Titles matching several bands are only counted once.
+		return []models.Finding{{Rule: RuleSalary, Detail: detail, Weight: r.Weight}}
+	}
+	return nil
+}
+
+func (s Scorer) domains(post models.JobPostFormProps) []models.Finding {
+	r := s.rules.Domains
+	_, emailDomain, ok := strings.Cut(strings.ToLower(post.LoginEmail), "@")
+	if !ok || emailDomain == "" {
+		return nil
+	}
+	if slices.Contains(r.FreeEmailDomains, emailDomain) {
+		if r.FreeEmailWeight == 0 {
+			return nil
+		}
+		return []models.Finding{{
+			Rule:   RuleDomainMismatch,
+			Detail: fmt.Sprintf("posted from a free email address at %s", emailDomain),
+			Weight: r.FreeEmailWeight,
+		}}
+	}
+	raw := strings.TrimSpace(post.CompanyWebsite)
+	if raw == "" || r.Weight == 0 {
+		return nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	websiteDomain := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+	if sameOrSubdomain(emailDomain, websiteDomain) || sameOrSubdomain(websiteDomain, emailDomain) {
+		return nil
+	}
+	return []models.Finding{{
+		Rule:   RuleDomainMismatch,
+		Detail: fmt.Sprintf("email domain %s doesn't match the company website %s", emailDomain, websiteDomain),
+		Weight: r.Weight,
+	}}
+}
+
+func (s Scorer) shouting(post models.JobPostFormProps) []models.Finding {
+	r := s.rules.Shouting
+	if r.Weight == 0 {
+		return nil
+	}
+	var letters, caps int
+	for _, c := range post.Title + " " + post.Description {
+		if unicode.IsLetter(c) {
+			letters++
+			if unicode.IsUpper(c) {
+				caps++
+			}
+		}
+	}
+	if letters == 0 || letters < r.MinLetters {
+		return nil
+	}
+	ratio := float64(caps) / float64(letters)
+	if ratio <= r.MaxCapsRatio {
+		return nil
+	}
+	return []models.Finding{{
+		Rule:   RuleShouting,
+		Detail: fmt.Sprintf("%.0f%% of the letters are capitals", ratio*100),
+		Weight: r.Weight,
+	}}
+}
+
+func (s Scorer) emoji(post models.JobPostFormProps) []models.Finding {
+	r := s.rules.Emoji
+	if r.Weight == 0 {
+		return nil
+	}
+	var count int
+	for _, c := range post.Title + " " + post.Description {
+		if isEmoji(c) {
+			count++
+		}
+	}
+	if count <= r.MaxEmoji {
+		return nil
+	}
+	return []models.Finding{{
+		Rule:   RuleEmoji,
+		Detail: fmt.Sprintf("contains %d emoji", count),
+		Weight: r.Weight,
+	}}
+}
+
+// isEmoji reports whether c is in one of the pictographic emoji blocks.
+func isEmoji(c rune) bool {
+	return (c >= 0x1F300 && c <= 0x1FAFF) || (c >= 0x2600 && c <= 0x27BF) || (c >= 0x1F1E6 && c <= 0x1F1FF)
+}
+
+// sameOrSubdomain reports whether host is domain or one of its subdomains.
+func sameOrSubdomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// containsWord reports whether text contains word with no letters either side of it.
+func containsWord(text, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		before := start == 0 || !unicode.IsLetter(rune(text[start-1]))
+		after := end == len(text) || !unicode.IsLetter(rune(text[end]))
+		if before && after {
+			return true
+		}
+		i = start + 1
+	}
+}
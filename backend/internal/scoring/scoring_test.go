@@ -0,0 +1,180 @@
+package scoring
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josepheid/upfront/api/models"
+)
+
+var testRules = Rules{
+	ReviewThreshold: 10,
+	BannedPhrases:   BannedPhrases{Weight: 5, Phrases: []string{"wire transfer", "Registration Fee"}},
+	Links:           Links{Weight: 4, InsecureWeight: 2, Domains: []string{"bit.ly", "t.me"}},
+	Salary: Salary{Weight: 3, Tolerance: 2, Bands: []SalaryBand{
+		{Keywords: []string{"engineer", "developer"}, Currency: models.GBP, Min: 30000, Max: 150000},
+		{Keywords: []string{"intern"}, Currency: models.GBP, Min: 15000, Max: 30000},
+	}},
+	Domains:  Domains{Weight: 2, FreeEmailWeight: 1, FreeEmailDomains: []string{"gmail.com"}},
+	Shouting: Shouting{Weight: 2, MaxCapsRatio: 0.5, MinLetters: 10},
+	Emoji:    Emoji{Weight: 1, MaxEmoji: 2},
+}
+
+// clean is a post none of the test rules match.
+func clean() models.JobPostFormProps {
+	return models.JobPostFormProps{
+		Title:          "Backend Engineer",
+		Description:    "Build and run our payments platform in Go.",
+		HowToApply:     "Apply at https://careers.acme.com/jobs/1",
+		CompanyWebsite: "https://www.acme.com",
+		LoginEmail:     "jobs@acme.com",
+		Currency:       models.GBP,
+		MinSalary:      60000,
+		MaxSalary:      80000,
+	}
+}
+
+func TestScore(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		change func(p *models.JobPostFormProps)
+		// want is the rules that match in order, a rule that matches twice is listed twice.
+		want []string
+	}{
+		{name: "clean post", change: func(p *models.JobPostFormProps) {}},
+		{name: "banned phrase ignores case", change: func(p *models.JobPostFormProps) { p.Description = "Pay the registration fee by WIRE TRANSFER." }, want: []string{RuleBannedPhrase, RuleBannedPhrase}},
+		{name: "banned phrase in how to apply", change: func(p *models.JobPostFormProps) { p.HowToApply = "Send a wire transfer" }, want: []string{RuleBannedPhrase}},
+		{name: "shortened link", change: func(p *models.JobPostFormProps) { p.HowToApply = "Apply at https://bit.ly/abc" }, want: []string{RuleSuspiciousLink}},
+		{name: "messaging subdomain without scheme", change: func(p *models.JobPostFormProps) { p.HowToApply = "Message www.go.t.me/acme" }, want: []string{RuleSuspiciousLink}},
+		{name: "lookalike domain", change: func(p *models.JobPostFormProps) { p.HowToApply = "Apply at https://notbit.ly/abc" }},
+		{name: "ip address", change: func(p *models.JobPostFormProps) { p.HowToApply = "Apply at https://192.168.0.1/apply" }, want: []string{RuleSuspiciousLink}},
+		{name: "insecure link", change: func(p *models.JobPostFormProps) { p.HowToApply = "Apply at http://careers.acme.com" }, want: []string{RuleSuspiciousLink}},
+		{name: "links outside how to apply", change: func(p *models.JobPostFormProps) { p.Description = "See https://bit.ly/abc" }},
+		{name: "salary far above band", change: func(p *models.JobPostFormProps) { p.MaxSalary = 300001 }, want: []string{RuleSalary}},
+		{name: "salary at tolerance", change: func(p *models.JobPostFormProps) { p.MaxSalary = 300000 }},
+		{name: "salary far below band", change: func(p *models.JobPostFormProps) { p.MinSalary = 14999 }, want: []string{RuleSalary}},
+		{name: "salary in another currency", change: func(p *models.JobPostFormProps) { p.Currency = models.EUR; p.MaxSalary = 900000 }},
+		{name: "salary keyword inside a word", change: func(p *models.JobPostFormProps) { p.Title = "Internal Auditor"; p.MaxSalary = 90000 }},
+		{name: "salary counted once for several bands", change: func(p *models.JobPostFormProps) { p.Title = "Engineer Intern"; p.MaxSalary = 400000 }, want: []string{RuleSalary}},
+		{name: "no salary", change: func(p *models.JobPostFormProps) { p.MinSalary, p.MaxSalary = 0, 0 }},
+		{name: "email from another domain", change: func(p *models.JobPostFormProps) { p.LoginEmail = "jobs@acme-careers.com" }, want: []string{RuleDomainMismatch}},
+		{name: "email from a subdomain", change: func(p *models.JobPostFormProps) { p.LoginEmail = "jobs@uk.acme.com" }},
+		{name: "website without scheme", change: func(p *models.JobPostFormProps) { p.CompanyWebsite = "acme.com/about" }},
+		{name: "free email", change: func(p *models.JobPostFormProps) { p.LoginEmail = "Someone@Gmail.com" }, want: []string{RuleDomainMismatch}},
+		{name: "shouting", change: func(p *models.JobPostFormProps) { p.Title = "URGENT HIRING"; p.Description = "APPLY NOW TODAY" }, want: []string{RuleShouting}},
+		{name: "short shouting", change: func(p *models.JobPostFormProps) { p.Title = "CTO"; p.Description = "GO" }},
+		{name: "too many emoji", change: func(p *models.JobPostFormProps) { p.Description = "Great team 🚀🔥💰" }, want: []string{RuleEmoji}},
+		{name: "some emoji", change: func(p *models.JobPostFormProps) { p.Description = "Great team 🚀🔥" }},
+	}
+	scorer := NewScorer(testRules)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := clean()
+			tt.change(&post)
+			score := scorer.Score(post, now)
+			var got []string
+			total := 0
+			for _, f := range score.Findings {
+				got = append(got, f.Rule)
+				total += f.Weight
+				if f.Detail == "" || f.Weight <= 0 {
+					t.Errorf("finding %+v has no detail or weight", f)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("findings = %v, want %v: %+v", got, tt.want, score.Findings)
+			}
+			if score.Score != total {
+				t.Errorf("score = %d, want the sum of the findings %d", score.Score, total)
+			}
+			if score.ScoredAt != now.Format(time.RFC3339) {
+				t.Errorf("scoredAt = %s", score.ScoredAt)
+			}
+		})
+	}
+}
+
+func TestScoreWeights(t *testing.T) {
+	scorer := NewScorer(testRules)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	post := clean()
+	post.HowToApply = "Apply at http://careers.acme.com or https://bit.ly/abc"
+	post.LoginEmail = "recruiter@gmail.com"
+	score := scorer.Score(post, now)
+	// An insecure link, a shortened link and a free email.
+	if score.Score != 2+4+1 || score.NeedsReview {
+		t.Errorf("score = %+v, want 7 below the threshold", score)
+	}
+
+	post.Description = "Pay by wire transfer"
+	score = scorer.Score(post, now)
+	if score.Score != 12 || !score.NeedsReview {
+		t.Errorf("score = %+v, want 12 needing review", score)
+	}
+
+	// Rules with no weight never match.
+	if score := NewScorer(Rules{ReviewThreshold: 1}).Score(post, now); score.Score != 0 || len(score.Findings) != 0 || score.NeedsReview {
+		t.Errorf("score with no rules = %+v", score)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse(defaultRules); err != nil {
+		t.Fatalf("default rules don't parse: %v", err)
+	}
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{name: "minimal", json: `{"reviewThreshold": 5}`},
+		{name: "unknown field", json: `{"reviewThreshold": 5, "bannedWords": {}}`, wantErr: "unknown field"},
+		{name: "no threshold", json: `{}`, wantErr: "reviewThreshold must be positive"},
+		{name: "negative weight", json: `{"reviewThreshold": 5, "emoji": {"weight": -1}}`, wantErr: "emoji.weight can't be negative"},
+		{name: "tolerance below one", json: `{"reviewThreshold": 5, "salary": {"tolerance": 0.5}}`, wantErr: "salary.tolerance must be at least 1"},
+		{name: "band without keywords", json: `{"reviewThreshold": 5, "salary": {"bands": [{"min": 1, "max": 2}]}}`, wantErr: "salary.bands[0]"},
+		{name: "band upside down", json: `{"reviewThreshold": 5, "salary": {"bands": [{"keywords": ["a"], "min": 2, "max": 1}]}}`, wantErr: "salary.bands[0]"},
+		{name: "caps ratio above one", json: `{"reviewThreshold": 5, "shouting": {"maxCapsRatio": 1.5}}`, wantErr: "maxCapsRatio"},
+		{name: "every problem", json: `{"links": {"weight": -1}, "shouting": {"maxCapsRatio": -1}}`, wantErr: "links.weight can't be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.json))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Parse() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`{"reviewThreshold": 42}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SCORING_RULES_FILE", path)
+	rules, err := FromEnv()
+	if err != nil || rules.ReviewThreshold != 42 {
+		t.Errorf("FromEnv() = %+v, %v, want the rules file", rules, err)
+	}
+
+	t.Setenv("SCORING_RULES_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := FromEnv(); err == nil {
+		t.Error("FromEnv() with a missing file succeeded")
+	}
+
+	t.Setenv("SCORING_RULES_FILE", "")
+	if _, err := FromEnv(); err != nil {
+		t.Errorf("FromEnv() with the default rules = %v", err)
+	}
+}
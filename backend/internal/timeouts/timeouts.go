@@ -0,0 +1,87 @@
+package timeouts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Budgets are how long a single outbound call to each dependency may take. Calls are also bound by
+// the incoming request's context, so whichever deadline comes first wins.
+type Budgets struct {
+	DynamoDB time.Duration
+	Stripe   time.Duration
+	Cognito  time.Duration
+	SES      time.Duration
+	KMS      time.Duration
+	S3       time.Duration
+}
+
+// Defaults leave room for a handler to make several calls inside API Gateway's 29 second limit.
+var Defaults = Budgets{
+	DynamoDB: 2 * time.Second,
+	Stripe:   8 * time.Second,
+	Cognito:  3 * time.Second,
+	SES:      3 * time.Second,
+	KMS:      2 * time.Second,
+	S3:       5 * time.Second,
+}
+
+// FromEnv reads budgets from the DYNAMODB_TIMEOUT, STRIPE_TIMEOUT, COGNITO_TIMEOUT, SES_TIMEOUT,
+// KMS_TIMEOUT and S3_TIMEOUT environment variables, e.g. "1500ms". Unset variables keep their default.
+func FromEnv() (Budgets, error) {
+	b := Defaults
+	for key, d := range map[string]*time.Duration{
+		"DYNAMODB_TIMEOUT": &b.DynamoDB,
+		"STRIPE_TIMEOUT":   &b.Stripe,
+		"COGNITO_TIMEOUT":  &b.Cognito,
+		"SES_TIMEOUT":      &b.SES,
+		"KMS_TIMEOUT":      &b.KMS,
+		"S3_TIMEOUT":       &b.S3,
+	} {
+		v := os.Getenv(key)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			return Budgets{}, fmt.Errorf("environment variable %s must be a positive duration, got %q", key, v)
+		}
+		*d = parsed
+	}
+	return b, nil
+}
+
+// APIOption bounds every call made by an AWS SDK client to d, add it to the client's APIOptions.
+func APIOption(d time.Duration) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("Timeout", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next.HandleInitialize(ctx, in)
+		}), middleware.Before)
+	}
+}
+
+// reserve is kept back from the Lambda deadline so a handler still has time to respond with a
+// timeout error instead of being killed mid request.
+const reserve = 500 * time.Millisecond
+
+// Middleware shortens the request context's deadline by a small reserve, outbound calls derived
+// from it then fail while there is still time to tell the client.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithDeadline(r.Context(), deadline.Add(-reserve))
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,129 @@
+package expirejobposts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/repository"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	tableName string
+	ddbc      *dynamodb.Client
+	jobPosts  repository.JobPosts
+}
+
+func NewHandler(logger *slog.Logger, tableName string, ddbc *dynamodb.Client) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		tableName: tableName,
+		ddbc:      ddbc,
+		jobPosts:  repository.NewJobPosts(ddbc, tableName),
+	}, nil
+}
+
+// Handle marks active posts whose expiresAt has passed as Expired, so they can be renewed. Paused
+// posts are expired too once their time has run out with the time they were paused credited back.
+func (h Handler) Handle(ctx context.Context, event events.CloudWatchEvent) error {
+	now := time.Now()
+
+	keyCondition := expression.KeyEqual(expression.Key("allJobs"), expression.Value("ALL_JOBS"))
+	live := expression.Name("status").Equal(expression.Value(models.Active)).
+		Or(expression.Name("status").Equal(expression.Value(models.Paused)))
+	filter := live.And(expression.Name("expiresAt").LessThan(expression.Value(now.Format(time.RFC3339))))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).WithFilter(filter).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(h.ddbc, &dynamodb.QueryInput{
+		TableName:                 aws.String(h.tableName),
+		IndexName:                 aws.String("allJobsIndex"),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+	})
+
+	var expired, skipped, failed int
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error querying all jobs gsi: %w", err)
+		}
+		jobPosts := []models.JobPostItem{}
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &jobPosts); err != nil {
+			return fmt.Errorf("error unmarshalling list of maps: %w", err)
+		}
+		for _, item := range jobPosts {
+			logger := h.logger.With("jobID", item.JobID, "expiresAt", item.ExpiresAt)
+			updated, err := h.expire(ctx, item, now)
+			switch {
+			case err != nil:
+				logger.Error("error expiring job post", "error", err)
+				failed++
+			case updated:
+				logger.Info("expired job post")
+				expired++
+			default:
+				logger.Info("job post was renewed or changed, skipped it")
+				skipped++
+			}
+		}
+	}
+
+	h.logger.Info("expired job posts", "expired", expired, "skipped", skipped, "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to expire %d job posts", failed)
+	}
+	return nil
+}
+
+// expire marks item as Expired, with an audit entry, and reports whether it was still due to
+// expire.
+func (h Handler) expire(ctx context.Context, item models.JobPostItem, now time.Time) (bool, error) {
+	if item.Status == models.Paused {
+		return h.expirePaused(ctx, item, now)
+	}
+	// A renewal paid since the query moves expiresAt and the version on, in which case the post is
+	// left alone.
+	err := h.jobPosts.Transition(ctx, &item, models.Expired, now, models.AuditExpired, models.SystemActor)
+	if errors.Is(err, repository.ErrConflict) || errors.Is(err, models.ErrNotLapsed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// expirePaused expires a paused post, crediting the time it was paused, unless that leaves it with
+// time left. It reports whether the post was expired.
+func (h Handler) expirePaused(ctx context.Context, item models.JobPostItem, now time.Time) (bool, error) {
+	before := item.Snapshot()
+	err := item.ExpirePaused(now)
+	if errors.Is(err, models.ErrNotLapsed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	err = h.jobPosts.Save(ctx, &item, item.Audit(models.AuditExpired, models.SystemActor, before, now))
+	if errors.Is(err, repository.ErrConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
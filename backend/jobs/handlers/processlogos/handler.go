@@ -0,0 +1,55 @@
+package processlogos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/josepheid/upfront/internal/logos"
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	processor logos.Processor
+}
+
+func NewHandler(logger *slog.Logger, processor logos.Processor) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		processor: processor,
+	}, nil
+}
+
+// Handle processes the logos uploaded in event. Rejected images are logged and skipped, other
+// failures are returned so the event is retried, uploads that were already processed are gone by then.
+func (h Handler) Handle(ctx context.Context, event events.S3Event) error {
+	var failed int
+	for _, record := range event.Records {
+		// Keys in S3 notifications are URL encoded.
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			h.logger.Error("invalid object key in event", "error", err, "key", record.S3.Object.Key)
+			continue
+		}
+		logger := h.logger.With("key", key)
+		err = h.processor.Process(ctx, key, time.Now())
+		if errors.Is(err, logos.ErrInvalidImage) {
+			logger.Warn("rejected logo upload", "error", err)
+			continue
+		}
+		if err != nil {
+			logger.Error("error processing logo", "error", err)
+			failed++
+			continue
+		}
+		logger.Info("processed logo")
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to process %d of %d logos", failed, len(event.Records))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/josepheid/upfront/internal/blobstore"
+	"github.com/josepheid/upfront/internal/logos"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/josepheid/upfront/internal/timeouts"
+	"github.com/josepheid/upfront/jobs/handlers/processlogos"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	region := "eu-west-2"
+
+	ctx := context.Background()
+	config, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	budgets, err := timeouts.FromEnv()
+	if err != nil {
+		logger.Error("invalid timeout configuration", "error", err)
+		os.Exit(1)
+	}
+
+	upfrontTableName := os.Getenv("UPFRONT_TABLE_NAME")
+
+	// If the environment variable is not set
+	if upfrontTableName == "" {
+		logger.Error("environment variable UPFRONT_TABLE_NAME is not set", "error", err)
+		os.Exit(1)
+	}
+
+	logoBucket := os.Getenv("LOGO_BUCKET")
+	if logoBucket == "" {
+		logger.Error("environment variable LOGO_BUCKET is not set")
+		os.Exit(1)
+	}
+
+	baseURL, err := logos.BaseURLFromEnv()
+	if err != nil {
+		logger.Error("invalid logo configuration", "error", err)
+		os.Exit(1)
+	}
+
+	ddbc := dynamodb.NewFromConfig(config, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.DynamoDB))
+	})
+
+	s3c := s3.NewFromConfig(config, blobstore.EndpointFromEnv, func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, timeouts.APIOption(budgets.S3))
+	})
+
+	processor := logos.NewProcessor(blobstore.NewS3(s3c, logoBucket), repository.NewCompanies(ddbc, upfrontTableName), baseURL)
+	h, err := processlogos.NewHandler(logger, processor)
+	if err != nil {
+		logger.Error("could not create handler", slog.Any("error", err))
+		os.Exit(1)
+	}
+	lambda.Start(h.Handle)
+}
@@ -0,0 +1,211 @@
+package reconcilependingpayments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/josepheid/upfront/api/models"
+	"github.com/josepheid/upfront/internal/payments"
+	"github.com/josepheid/upfront/internal/repository"
+	"github.com/stripe/stripe-go/v80"
+)
+
+// abandonAfter is how old a pending post must be before it is reconciled. Checkout sessions
+// expire after 24 hours, so by then the session has reached its final state.
+const abandonAfter = 25 * time.Hour
+
+// outcome is what reconciling a pending post did with it.
+type outcome int
+
+const (
+	// kept is a post that was paid for or activated since it was read.
+	kept outcome = iota
+	// deleted is an abandoned post that was removed.
+	deleted
+	// drafted is an abandoned checkout of a draft that was made a draft again.
+	drafted
+)
+
+type Handler struct {
+	logger    *slog.Logger
+	payments  payments.Provider
+	tableName string
+	ddbc      *dynamodb.Client
+	jobPosts  repository.JobPosts
+}
+
+func NewHandler(logger *slog.Logger, provider payments.Provider, tableName string, ddbc *dynamodb.Client) (Handler, error) {
+	return Handler{
+		logger:    logger,
+		payments:  provider,
+		tableName: tableName,
+		ddbc:      ddbc,
+		jobPosts:  repository.NewJobPosts(ddbc, tableName),
+	}, nil
+}
+
+// Handle removes pending posts whose checkout was abandoned, checkouts of drafts go back to being
+// drafts instead. Posts that were paid for but never activated are kept and their TTL is removed
+// so they can still be validated.
+func (h Handler) Handle(ctx context.Context, event events.CloudWatchEvent) error {
+	cutoff := time.Now().Add(-abandonAfter)
+
+	keyCondition := expression.KeyEqual(expression.Key("allJobs"), expression.Value("ALL_JOBS")).
+		And(expression.KeyLessThan(expression.Key("createdAt"), expression.Value(cutoff.Format(time.RFC3339))))
+	pending := expression.Name("status").Equal(expression.Value(models.PendingPayment))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).WithFilter(pending).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(h.ddbc, &dynamodb.QueryInput{
+		TableName:                 aws.String(h.tableName),
+		IndexName:                 aws.String("allJobsIndex"),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+	})
+
+	var deletedCount, draftedCount, keptCount, failed int
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error querying all jobs gsi: %w", err)
+		}
+		jobPosts := []models.JobPostItem{}
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &jobPosts); err != nil {
+			return fmt.Errorf("error unmarshalling list of maps: %w", err)
+		}
+		for _, item := range jobPosts {
+			logger := h.logger.With("jobID", item.JobID, "sessionID", item.SessionID)
+			result, err := h.reconcile(ctx, item)
+			switch {
+			case err != nil:
+				logger.Error("error reconciling pending job post", "error", err)
+				failed++
+			case result == deleted:
+				logger.Info("removed abandoned job post")
+				deletedCount++
+			case result == drafted:
+				logger.Info("returned abandoned checkout to its draft")
+				draftedCount++
+			default:
+				logger.Warn("pending job post was paid for or activated, kept it")
+				keptCount++
+			}
+		}
+	}
+
+	h.logger.Info("reconciled pending job posts", "deleted", deletedCount, "drafted", draftedCount, "kept", keptCount, "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to reconcile %d job posts", failed)
+	}
+	return nil
+}
+
+// reconcile deletes item, or makes it a draft again if it was checked out from one, unless its
+// checkout session was paid.
+func (h Handler) reconcile(ctx context.Context, item models.JobPostItem) (outcome, error) {
+	checkoutSession, err := h.payments.GetCheckout(ctx, item.SessionID)
+	if err != nil {
+		return kept, err
+	}
+
+	if payments.Paid(checkoutSession) {
+		return kept, h.keep(ctx, item)
+	}
+
+	if checkoutSession.Status == stripe.CheckoutSessionStatusOpen {
+		// Make sure nobody can pay for the post once it has gone.
+		if err := h.payments.ExpireCheckout(ctx, item.SessionID); err != nil {
+			return kept, err
+		}
+	}
+
+	if item.FromDraft {
+		return h.returnToDraft(ctx, item)
+	}
+	return h.delete(ctx, item)
+}
+
+// returnToDraft makes item the draft it was checked out from again, so the recruiter doesn't lose
+// it by abandoning the checkout.
+func (h Handler) returnToDraft(ctx context.Context, item models.JobPostItem) (outcome, error) {
+	now := time.Now()
+	before := item.Snapshot()
+	if err := item.ReturnToDraft(now); err != nil {
+		return kept, err
+	}
+	// Save only succeeds if the post hasn't changed, validate-purchase may have activated it since.
+	err := h.jobPosts.Save(ctx, &item, item.Audit(models.AuditPurchaseExpired, models.PaymentsActor, before, now))
+	if errors.Is(err, repository.ErrConflict) {
+		return kept, nil
+	}
+	if err != nil {
+		return kept, fmt.Errorf("error returning job post to draft: %w", err)
+	}
+	return drafted, nil
+}
+
+func (h Handler) keep(ctx context.Context, item models.JobPostItem) error {
+	// The version moves on so a Save of a copy read before this can't put the ttl back.
+	upd := expression.Remove(expression.Name("ttl")).Add(expression.Name("version"), expression.Value(1))
+	expr, err := expression.NewBuilder().WithUpdate(upd).Build()
+	if err != nil {
+		return fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = h.ddbc.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(h.tableName),
+		Key:                       jobPostKey(item),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		UpdateExpression:          expr.Update(),
+	})
+	if err != nil {
+		return fmt.Errorf("error removing ttl: %w", err)
+	}
+	return nil
+}
+
+// delete removes item, but not its audit entries, if it is still pending.
+func (h Handler) delete(ctx context.Context, item models.JobPostItem) (outcome, error) {
+	// Only delete the post if it is still unpaid, validate-purchase may have activated it since.
+	cond := expression.Name("status").Equal(expression.Value(models.PendingPayment))
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return kept, fmt.Errorf("error building expression: %w", err)
+	}
+	_, err = h.ddbc.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(h.tableName),
+		Key:                       jobPostKey(item),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return kept, nil
+	}
+	if err != nil {
+		return kept, fmt.Errorf("error deleting item: %w", err)
+	}
+	// The post's audit entries are kept, they record the checkout that was never paid.
+	return deleted, nil
+}
+
+func jobPostKey(item models.JobPostItem) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"PK": &types.AttributeValueMemberS{Value: item.PK},
+		"SK": &types.AttributeValueMemberS{Value: item.SK},
+	}
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsapigateway"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awskms"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awss3notifications"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awssecretsmanager"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2/awscognito"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsdynamodb"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsevents"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awseventstargets"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsiam"
 	golambda "github.com/aws/aws-cdk-go/awscdklambdagoalpha/v2"
 	"github.com/aws/constructs-go/constructs/v10"
@@ -26,6 +33,33 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 	}
 	stack := awscdk.NewStack(scope, &id, &sprops)
 
+	// Frontend origins that checkout redirects and magic links may point at, they must be set at
+	// deploy time with `cdk deploy -c allowedOrigins=https://example.com,http://localhost:3000`.
+	// There is no default, a stack that only allowed localhost would reject the real frontend.
+	origins, ok := stack.Node().TryGetContext(jsii.String("allowedOrigins")).(string)
+	if !ok || strings.TrimSpace(origins) == "" {
+		panic("allowedOrigins context is required, deploy with `cdk deploy -c allowedOrigins=https://example.com`")
+	}
+	allowedOrigins := jsii.String(origins)
+
+	// Days a Premium post is pinned to the top of the listing, override with `-c featuredDays=14`.
+	featuredDays := jsii.String("7")
+	if v := stack.Node().TryGetContext(jsii.String("featuredDays")); v != nil {
+		featuredDays = jsii.String(fmt.Sprint(v))
+	}
+
+	// Holds new posts for an admin to review before they are listed, enable with `-c moderationEnabled=true`.
+	moderationEnabled := jsii.String("false")
+	if v := stack.Node().TryGetContext(jsii.String("moderationEnabled")); v != nil {
+		moderationEnabled = jsii.String(fmt.Sprint(v))
+	}
+
+	// Open reports that take a live post down until an admin reviews it, override with `-c reportThreshold=5`.
+	reportThreshold := jsii.String("3")
+	if v := stack.Node().TryGetContext(jsii.String("reportThreshold")); v != nil {
+		reportThreshold = jsii.String(fmt.Sprint(v))
+	}
+
 	//KMS Key
 	key := awskms.NewKey(stack, &id, &awskms.KeyProps{
 		Enabled:           jsii.Bool(true),
@@ -145,6 +179,12 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 		PreventUserExistenceErrors: jsii.Bool(true),
 	})
 
+	awscognito.NewCfnUserPoolGroup(stack, jsii.String("adminsGroup"), &awscognito.CfnUserPoolGroupProps{
+		UserPoolId:  passwordlessMagicLinkUserPool.UserPoolId(),
+		GroupName:   jsii.String("admins"),
+		Description: jsii.String("Upfront staff who can moderate and refund any job post"),
+	})
+
 	// Upfront Table
 	upfrontTable := awsdynamodb.NewTableV2(stack, jsii.String("Table"), &awsdynamodb.TablePropsV2{
 		PartitionKey: &awsdynamodb.Attribute{
@@ -155,6 +195,8 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 			Name: jsii.String("SK"),
 			Type: awsdynamodb.AttributeType_STRING,
 		},
+		// Epoch seconds after which DynamoDB deletes an item, e.g. stored idempotent responses.
+		TimeToLiveAttribute: jsii.String("ttl"),
 	})
 
 	upfrontTable.AddGlobalSecondaryIndex(&awsdynamodb.GlobalSecondaryIndexPropsV2{
@@ -183,9 +225,56 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 		ProjectionType: awsdynamodb.ProjectionType_ALL, // Or specify keys you need with INCLUDE
 	})
 
+	upfrontTable.AddGlobalSecondaryIndex(&awsdynamodb.GlobalSecondaryIndexPropsV2{
+		IndexName: jsii.String("companyIndex"),
+		PartitionKey: &awsdynamodb.Attribute{
+			Name: jsii.String("companyID"), // Only set on job posts, companies store their ID as "id"
+			Type: awsdynamodb.AttributeType_STRING,
+		},
+		SortKey: &awsdynamodb.Attribute{
+			Name: jsii.String("createdAt"),
+			Type: awsdynamodb.AttributeType_STRING,
+		},
+		ProjectionType: awsdynamodb.ProjectionType_ALL,
+	})
+
+	upfrontTable.AddGlobalSecondaryIndex(&awsdynamodb.GlobalSecondaryIndexPropsV2{
+		IndexName: jsii.String("memberIndex"),
+		PartitionKey: &awsdynamodb.Attribute{
+			Name: jsii.String("memberEmail"), // Only set on company members and invitations
+			Type: awsdynamodb.AttributeType_STRING,
+		},
+		SortKey: &awsdynamodb.Attribute{
+			Name: jsii.String("PK"),
+			Type: awsdynamodb.AttributeType_STRING,
+		},
+		ProjectionType: awsdynamodb.ProjectionType_ALL,
+	})
+
+	// Invoice PDFs, kept private and only served through the getInvoice lambda.
+	blobBucket := awss3.NewBucket(stack, jsii.String("blobBucket"), &awss3.BucketProps{
+		BlockPublicAccess: awss3.BlockPublicAccess_BLOCK_ALL(),
+		Encryption:        awss3.BucketEncryption_S3_MANAGED,
+		EnforceSSL:        jsii.Bool(true),
+		RemovalPolicy:     awscdk.RemovalPolicy_RETAIN,
+	})
+
+	// Keys the hashes that rate limits and reports identify visitors' IP addresses by, so they
+	// can't be reversed by hashing every address.
+	ipHashKey := awssecretsmanager.NewSecret(stack, jsii.String("ipHashKey"), &awssecretsmanager.SecretProps{
+		SecretName: jsii.String("IP_HASH_KEY"),
+		GenerateSecretString: &awssecretsmanager.SecretStringGenerator{
+			SecretStringTemplate: jsii.String("{}"),
+			GenerateStringKey:    jsii.String("IP_HASH_KEY"),
+			PasswordLength:       jsii.Number(64),
+			ExcludePunctuation:   jsii.Bool(true),
+		},
+	})
+
 	createCheckoutSession := golambda.NewGoFunction(stack, jsii.String("createCheckoutSession"), &golambda.GoFunctionProps{
 		Entry:       jsii.String("../backend/api/handlers/createcheckoutsession/post"),
 		Description: jsii.String("lambda responsible for creating checkout sessions"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
 		InitialPolicy: &[]awsiam.PolicyStatement{
 			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
 				Actions:   jsii.Strings("secretsmanager:GetSecretValue"),
@@ -194,21 +283,25 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 		},
 		Environment: &map[string]*string{
 			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"ALLOWED_ORIGINS":    allowedOrigins,
 		},
 	})
 
 	validatePurchase := golambda.NewGoFunction(stack, jsii.String("validatePurchase"), &golambda.GoFunctionProps{
 		Entry:       jsii.String("../backend/api/handlers/validatepurchase/get"),
 		Description: jsii.String("lambda responsible for validate that the customer has paid"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
 		InitialPolicy: &[]awsiam.PolicyStatement{
 			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
-				Actions:   jsii.Strings("secretsmanager:GetSecretValue", "cognito-idp:AdminCreateUser", "cognito-idp:AdminSetUserPassword", "cognito-idp:AdminGetUser"),
+				Actions:   jsii.Strings("secretsmanager:GetSecretValue", "cognito-idp:AdminCreateUser", "cognito-idp:AdminSetUserPassword", "cognito-idp:AdminGetUser", "ses:SendRawEmail"),
 				Resources: jsii.Strings("*"),
 			}),
 		},
 		Environment: &map[string]*string{
 			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
 			"USER_POOL_ID":       passwordlessMagicLinkUserPool.UserPoolId(),
+			"BLOB_STORE_BUCKET":  blobBucket.BucketName(),
+			"MODERATION_ENABLED": moderationEnabled,
 		},
 	})
 
@@ -217,6 +310,7 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 		Description: jsii.String("lambda responsible for getting jobs"),
 		Environment: &map[string]*string{
 			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"FEATURED_DAYS":      featuredDays,
 		},
 	})
 
@@ -231,6 +325,7 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 	startChallenge := golambda.NewGoFunction(stack, jsii.String("startChallenge"), &golambda.GoFunctionProps{
 		Entry:       jsii.String("../backend/api/handlers/startchallenge/post"),
 		Description: jsii.String("lambda responsible for starting magic link auth challenges"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
 		InitialPolicy: &[]awsiam.PolicyStatement{
 			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
 				Actions: jsii.Strings("ses:SendEmail",
@@ -250,6 +345,392 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
 			"KMS_KEY_ID":         key.KeyId(),
 			"USER_POOL_ID":       passwordlessMagicLinkUserPool.UserPoolId(),
+			"ALLOWED_ORIGINS":    allowedOrigins,
+		},
+	})
+
+	getOpenAPI := golambda.NewGoFunction(stack, jsii.String("getOpenAPI"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getopenapi/get"),
+		Description: jsii.String("lambda responsible for serving the OpenAPI specification"),
+		MemorySize:  jsii.Number(128),
+	})
+
+	// Company logos. Recruiters upload to uploads/logos/ with presigned URLs, the processed
+	// thumbnails under logos/ are public so posts can link to them.
+	logoBucket := awss3.NewBucket(stack, jsii.String("logoBucket"), &awss3.BucketProps{
+		BlockPublicAccess: awss3.BlockPublicAccess_BLOCK_ACLS(),
+		Encryption:        awss3.BucketEncryption_S3_MANAGED,
+		EnforceSSL:        jsii.Bool(true),
+		Cors: &[]*awss3.CorsRule{{
+			AllowedMethods: &[]awss3.HttpMethods{awss3.HttpMethods_PUT},
+			AllowedOrigins: jsii.Strings(strings.Split(*allowedOrigins, ",")...),
+			AllowedHeaders: jsii.Strings("Content-Type"),
+		}},
+		LifecycleRules: &[]*awss3.LifecycleRule{{
+			// Uploads are deleted once processed, this clears ones that never were.
+			Prefix:     jsii.String("uploads/"),
+			Expiration: awscdk.Duration_Days(jsii.Number(1)),
+		}},
+	})
+	logoBucket.GrantPublicAccess(jsii.String("logos/*"), jsii.String("s3:GetObject"))
+	logoBaseURL := jsii.String("https://" + *logoBucket.BucketRegionalDomainName())
+
+	reconcilePendingPayments := golambda.NewGoFunction(stack, jsii.String("reconcilePendingPayments"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/jobs/handlers/reconcilependingpayments/scheduled"),
+		Description: jsii.String("lambda responsible for removing job posts whose checkout was abandoned"),
+		Timeout:     awscdk.Duration_Minutes(jsii.Number(5)),
+		InitialPolicy: &[]awsiam.PolicyStatement{
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("secretsmanager:GetSecretValue"),
+				Resources: jsii.Strings("*"),
+			}),
+		},
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	awsevents.NewRule(stack, jsii.String("reconcilePendingPaymentsSchedule"), &awsevents.RuleProps{
+		Schedule: awsevents.Schedule_Rate(awscdk.Duration_Hours(jsii.Number(1))),
+		Targets: &[]awsevents.IRuleTarget{
+			awseventstargets.NewLambdaFunction(reconcilePendingPayments, nil),
+		},
+	})
+
+	renewJobPost := golambda.NewGoFunction(stack, jsii.String("renewJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/renewjobpost/post"),
+		Description: jsii.String("lambda responsible for creating checkout sessions to renew job posts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		InitialPolicy: &[]awsiam.PolicyStatement{
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("secretsmanager:GetSecretValue"),
+				Resources: jsii.Strings("*"),
+			}),
+		},
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"ALLOWED_ORIGINS":    allowedOrigins,
+		},
+	})
+
+	upgradeJobPost := golambda.NewGoFunction(stack, jsii.String("upgradeJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/upgradejobpost/post"),
+		Description: jsii.String("lambda responsible for creating checkout sessions to upgrade job posts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		InitialPolicy: &[]awsiam.PolicyStatement{
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("secretsmanager:GetSecretValue"),
+				Resources: jsii.Strings("*"),
+			}),
+		},
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"ALLOWED_ORIGINS":    allowedOrigins,
+		},
+	})
+
+	cancelJobPost := golambda.NewGoFunction(stack, jsii.String("cancelJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/canceljobpost/post"),
+		Description: jsii.String("lambda responsible for cancelling and refunding job posts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(25)),
+		InitialPolicy: &[]awsiam.PolicyStatement{
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("secretsmanager:GetSecretValue"),
+				Resources: jsii.Strings("*"),
+			}),
+		},
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	getInvoice := golambda.NewGoFunction(stack, jsii.String("getInvoice"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getinvoice/get"),
+		Description: jsii.String("lambda responsible for serving invoice PDFs"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		InitialPolicy: &[]awsiam.PolicyStatement{
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("ses:SendRawEmail"),
+				Resources: jsii.Strings("*"),
+			}),
+		},
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"BLOB_STORE_BUCKET":  blobBucket.BucketName(),
+		},
+	})
+
+	updateJobPost := golambda.NewGoFunction(stack, jsii.String("updateJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/updatejobpost/put"),
+		Description: jsii.String("lambda responsible for recruiters editing their job posts"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"MODERATION_ENABLED": moderationEnabled,
+		},
+	})
+
+	getReviewQueue := golambda.NewGoFunction(stack, jsii.String("getReviewQueue"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getreviewqueue/get"),
+		Description: jsii.String("lambda responsible for listing job posts waiting for moderation"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	getDuplicates := golambda.NewGoFunction(stack, jsii.String("getDuplicates"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getduplicates/get"),
+		Description: jsii.String("lambda responsible for listing possible duplicate job posts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	pauseJobPost := golambda.NewGoFunction(stack, jsii.String("pauseJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/pausejobpost/post"),
+		Description: jsii.String("lambda responsible for pausing and resuming job posts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	getJobPost := golambda.NewGoFunction(stack, jsii.String("getJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getjobpost/get"),
+		Description: jsii.String("lambda responsible for getting a single job post"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	fillJobPost := golambda.NewGoFunction(stack, jsii.String("fillJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/filljobpost/post"),
+		Description: jsii.String("lambda responsible for marking job posts filled"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		InitialPolicy: &[]awsiam.PolicyStatement{
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("secretsmanager:GetSecretValue"),
+				Resources: jsii.Strings("*"),
+			}),
+		},
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	getAnalytics := golambda.NewGoFunction(stack, jsii.String("getAnalytics"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getanalytics/get"),
+		Description: jsii.String("lambda responsible for summing up the outcomes of job posts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	createDraft := golambda.NewGoFunction(stack, jsii.String("createDraft"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/createdraft/post"),
+		Description: jsii.String("lambda responsible for saving job post drafts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	getDraft := golambda.NewGoFunction(stack, jsii.String("getDraft"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getdraft/get"),
+		Description: jsii.String("lambda responsible for getting and previewing job post drafts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	updateDraft := golambda.NewGoFunction(stack, jsii.String("updateDraft"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/updatedraft/put"),
+		Description: jsii.String("lambda responsible for updating job post drafts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	deleteDraft := golambda.NewGoFunction(stack, jsii.String("deleteDraft"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/deletedraft/delete"),
+		Description: jsii.String("lambda responsible for deleting job post drafts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	getAuditLog := golambda.NewGoFunction(stack, jsii.String("getAuditLog"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getauditlog/get"),
+		Description: jsii.String("lambda responsible for listing the changes made to a job post"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	createReport := golambda.NewGoFunction(stack, jsii.String("createReport"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/createreport/post"),
+		Description: jsii.String("lambda responsible for visitors reporting job posts"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"REPORT_THRESHOLD":   reportThreshold,
+		},
+	})
+
+	getReports := golambda.NewGoFunction(stack, jsii.String("getReports"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getreports/get"),
+		Description: jsii.String("lambda responsible for listing reported job posts"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	resolveReports := golambda.NewGoFunction(stack, jsii.String("resolveReports"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/resolvereports/post"),
+		Description: jsii.String("lambda responsible for closing the reports of a job post"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	reviewJobPost := golambda.NewGoFunction(stack, jsii.String("reviewJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/reviewjobpost/post"),
+		Description: jsii.String("lambda responsible for moderating job posts and refunding rejected ones"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(25)),
+		InitialPolicy: &[]awsiam.PolicyStatement{
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("secretsmanager:GetSecretValue", "ses:SendEmail"),
+				Resources: jsii.Strings("*"),
+			}),
+		},
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	getCompany := golambda.NewGoFunction(stack, jsii.String("getCompany"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getcompany/get"),
+		Description: jsii.String("lambda responsible for serving company profiles"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	updateCompany := golambda.NewGoFunction(stack, jsii.String("updateCompany"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/updatecompany/put"),
+		Description: jsii.String("lambda responsible for updating company profiles"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	uploadCompanyLogo := golambda.NewGoFunction(stack, jsii.String("uploadCompanyLogo"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/uploadcompanylogo/post"),
+		Description: jsii.String("lambda responsible for issuing presigned company logo uploads"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"LOGO_BUCKET":        logoBucket.BucketName(),
+		},
+	})
+
+	getCompanyMembers := golambda.NewGoFunction(stack, jsii.String("getCompanyMembers"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/getcompanymembers/get"),
+		Description: jsii.String("lambda responsible for listing company members"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	inviteCompanyMember := golambda.NewGoFunction(stack, jsii.String("inviteCompanyMember"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/invitecompanymember/post"),
+		Description: jsii.String("lambda responsible for inviting recruiters to companies"),
+		Timeout:     awscdk.Duration_Seconds(jsii.Number(20)),
+		InitialPolicy: &[]awsiam.PolicyStatement{
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("ses:SendEmail"),
+				Resources: jsii.Strings("*"),
+			}),
+			awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+				Actions:   jsii.Strings("cognito-idp:AdminCreateUser", "cognito-idp:AdminSetUserPassword", "cognito-idp:AdminGetUser"),
+				Resources: jsii.Strings(*passwordlessMagicLinkUserPool.UserPoolArn()),
+			}),
+		},
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"USER_POOL_ID":       passwordlessMagicLinkUserPool.UserPoolId(),
+			"ALLOWED_ORIGINS":    allowedOrigins,
+		},
+	})
+
+	acceptInvitation := golambda.NewGoFunction(stack, jsii.String("acceptInvitation"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/acceptinvitation/post"),
+		Description: jsii.String("lambda responsible for accepting company invitations"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	updateCompanyMember := golambda.NewGoFunction(stack, jsii.String("updateCompanyMember"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/updatecompanymember/put"),
+		Description: jsii.String("lambda responsible for changing company member roles"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	removeCompanyMember := golambda.NewGoFunction(stack, jsii.String("removeCompanyMember"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/removecompanymember/delete"),
+		Description: jsii.String("lambda responsible for removing company members"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	transferJobPost := golambda.NewGoFunction(stack, jsii.String("transferJobPost"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/api/handlers/transferjobpost/post"),
+		Description: jsii.String("lambda responsible for transferring job posts between company members"),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	processLogos := golambda.NewGoFunction(stack, jsii.String("processLogos"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/jobs/handlers/processlogos/s3event"),
+		Description: jsii.String("lambda responsible for making thumbnails of uploaded company logos"),
+		Timeout:     awscdk.Duration_Minutes(jsii.Number(1)),
+		MemorySize:  jsii.Number(512),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+			"LOGO_BUCKET":        logoBucket.BucketName(),
+			"LOGO_BASE_URL":      logoBaseURL,
+		},
+	})
+
+	logoBucket.AddEventNotification(awss3.EventType_OBJECT_CREATED, awss3notifications.NewLambdaDestination(processLogos), &awss3.NotificationKeyFilter{
+		Prefix: jsii.String("uploads/logos/"),
+	})
+
+	expireJobPosts := golambda.NewGoFunction(stack, jsii.String("expireJobPosts"), &golambda.GoFunctionProps{
+		Entry:       jsii.String("../backend/jobs/handlers/expirejobposts/scheduled"),
+		Description: jsii.String("lambda responsible for expiring job posts past their expiry date"),
+		Timeout:     awscdk.Duration_Minutes(jsii.Number(5)),
+		Environment: &map[string]*string{
+			"UPFRONT_TABLE_NAME": upfrontTable.TableName(),
+		},
+	})
+
+	awsevents.NewRule(stack, jsii.String("expireJobPostsSchedule"), &awsevents.RuleProps{
+		Schedule: awsevents.Schedule_Rate(awscdk.Duration_Hours(jsii.Number(1))),
+		Targets: &[]awsevents.IRuleTarget{
+			awseventstargets.NewLambdaFunction(expireJobPosts, nil),
 		},
 	})
 
@@ -258,6 +739,45 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 	upfrontTable.GrantFullAccess(getJobsPosts)
 	upfrontTable.GrantFullAccess(startChallenge)
 	upfrontTable.GrantReadData(getRecruiterJobsPosts)
+	upfrontTable.GrantReadWriteData(reconcilePendingPayments)
+	upfrontTable.GrantReadWriteData(renewJobPost)
+	upfrontTable.GrantReadWriteData(expireJobPosts)
+	upfrontTable.GrantReadWriteData(upgradeJobPost)
+	upfrontTable.GrantReadWriteData(cancelJobPost)
+	upfrontTable.GrantReadWriteData(getInvoice)
+	upfrontTable.GrantReadWriteData(updateJobPost)
+	upfrontTable.GrantReadData(getReviewQueue)
+	upfrontTable.GrantReadWriteData(reviewJobPost)
+	upfrontTable.GrantReadData(getDuplicates)
+	upfrontTable.GrantReadData(getAuditLog)
+	upfrontTable.GrantReadWriteData(pauseJobPost)
+	upfrontTable.GrantReadData(getJobPost)
+	upfrontTable.GrantReadWriteData(fillJobPost)
+	upfrontTable.GrantReadData(getAnalytics)
+	upfrontTable.GrantReadWriteData(createDraft)
+	upfrontTable.GrantReadData(getDraft)
+	upfrontTable.GrantReadWriteData(updateDraft)
+	upfrontTable.GrantReadWriteData(deleteDraft)
+	upfrontTable.GrantReadWriteData(createReport)
+	ipHashKey.GrantRead(createReport, nil)
+	ipHashKey.GrantRead(createDraft, nil)
+	upfrontTable.GrantReadData(getReports)
+	upfrontTable.GrantReadWriteData(resolveReports)
+	upfrontTable.GrantReadData(getCompany)
+	upfrontTable.GrantReadWriteData(updateCompany)
+	upfrontTable.GrantReadData(uploadCompanyLogo)
+	upfrontTable.GrantReadWriteData(processLogos)
+	upfrontTable.GrantReadData(getCompanyMembers)
+	upfrontTable.GrantReadWriteData(inviteCompanyMember)
+	upfrontTable.GrantReadWriteData(acceptInvitation)
+	upfrontTable.GrantReadWriteData(updateCompanyMember)
+	upfrontTable.GrantReadWriteData(removeCompanyMember)
+	upfrontTable.GrantReadWriteData(transferJobPost)
+	blobBucket.GrantReadWrite(validatePurchase, nil)
+	blobBucket.GrantReadWrite(getInvoice, nil)
+	logoBucket.GrantPut(uploadCompanyLogo, jsii.String("uploads/logos/*"))
+	logoBucket.GrantReadWrite(processLogos, nil)
+	logoBucket.GrantDelete(processLogos, jsii.String("uploads/logos/*"))
 
 	notFound := golambda.NewGoFunction(stack, jsii.String("notFound"), &golambda.GoFunctionProps{
 		Description: jsii.String("Returns a not found response."),
@@ -271,12 +791,28 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 		CloudWatchRole: jsii.Bool(false),
 		Handler:        notFound,
 		Proxy:          jsii.Bool(false),
+		// Lets lambdas return invoice PDFs, clients must send a matching Accept header.
+		BinaryMediaTypes: jsii.Strings("application/pdf"),
 	})
 	upfront := api.Root().AddResource(jsii.String("upfront"), apiResourceOpts)
 
+	// Recruiter only methods require the ID token from the magic link sign in.
+	recruiterAuthorizer := awsapigateway.NewCognitoUserPoolsAuthorizer(stack, jsii.String("recruiterAuthorizer"), &awsapigateway.CognitoUserPoolsAuthorizerProps{
+		CognitoUserPools: &[]awscognito.IUserPool{passwordlessMagicLinkUserPool},
+	})
+	recruiterMethodOpts := &awsapigateway.MethodOptions{
+		ApiKeyRequired:    jsii.Bool(true),
+		Authorizer:        recruiterAuthorizer,
+		AuthorizationType: awsapigateway.AuthorizationType_COGNITO,
+	}
+
 	checkoutSession := upfront.AddResource(jsii.String("checkout-session"), apiResourceOpts)
 	createCheckoutSessionPostIntegration := awsapigateway.NewLambdaIntegration(createCheckoutSession, apiLambdaOpts)
 	checkoutSession.AddMethod(jsii.String(http.MethodPost), createCheckoutSessionPostIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
+	// Signed in recruiters check out here, so they can post for their company.
+	recruiter := upfront.AddResource(jsii.String("recruiter"), apiResourceOpts)
+	recruiterCheckoutSession := recruiter.AddResource(jsii.String("checkout-session"), apiResourceOpts)
+	recruiterCheckoutSession.AddMethod(jsii.String(http.MethodPost), createCheckoutSessionPostIntegration, recruiterMethodOpts)
 
 	validatePurchaseId := upfront.AddResource(jsii.String("validate-purchase"), apiResourceOpts)
 	validatePurchaseWithId := validatePurchaseId.AddResource(jsii.String("{id}"), apiResourceOpts)
@@ -287,15 +823,122 @@ func NewCdkStack(scope constructs.Construct, id string, props *CdkStackProps) aw
 	jobPostsGetIntegration := awsapigateway.NewLambdaIntegration(getJobsPosts, apiLambdaOpts)
 	jobPosts.AddMethod(jsii.String(http.MethodGet), jobPostsGetIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
 
+	jobPostWithId := jobPosts.AddResource(jsii.String("{id}"), apiResourceOpts)
+	updateJobPostIntegration := awsapigateway.NewLambdaIntegration(updateJobPost, apiLambdaOpts)
+	jobPostWithId.AddMethod(jsii.String(http.MethodPut), updateJobPostIntegration, recruiterMethodOpts)
+	getJobPostIntegration := awsapigateway.NewLambdaIntegration(getJobPost, apiLambdaOpts)
+	jobPostWithId.AddMethod(jsii.String(http.MethodGet), getJobPostIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
+
+	renewJobPostResource := jobPostWithId.AddResource(jsii.String("renew"), apiResourceOpts)
+	renewJobPostPostIntegration := awsapigateway.NewLambdaIntegration(renewJobPost, apiLambdaOpts)
+	renewJobPostResource.AddMethod(jsii.String(http.MethodPost), renewJobPostPostIntegration, recruiterMethodOpts)
+
+	upgradeJobPostResource := jobPostWithId.AddResource(jsii.String("upgrade"), apiResourceOpts)
+	upgradeJobPostPostIntegration := awsapigateway.NewLambdaIntegration(upgradeJobPost, apiLambdaOpts)
+	upgradeJobPostResource.AddMethod(jsii.String(http.MethodPost), upgradeJobPostPostIntegration, recruiterMethodOpts)
+
+	invoices := jobPostWithId.AddResource(jsii.String("invoices"), apiResourceOpts)
+	invoiceWithSessionId := invoices.AddResource(jsii.String("{sessionId}"), apiResourceOpts)
+	getInvoiceIntegration := awsapigateway.NewLambdaIntegration(getInvoice, apiLambdaOpts)
+	invoiceWithSessionId.AddMethod(jsii.String(http.MethodGet), getInvoiceIntegration, recruiterMethodOpts)
+
+	cancelJobPostIntegration := awsapigateway.NewLambdaIntegration(cancelJobPost, apiLambdaOpts)
+	cancelJobPostResource := jobPostWithId.AddResource(jsii.String("cancel"), apiResourceOpts)
+	cancelJobPostResource.AddMethod(jsii.String(http.MethodPost), cancelJobPostIntegration, recruiterMethodOpts)
+
+	transferJobPostResource := jobPostWithId.AddResource(jsii.String("transfer"), apiResourceOpts)
+	transferJobPostIntegration := awsapigateway.NewLambdaIntegration(transferJobPost, apiLambdaOpts)
+	transferJobPostResource.AddMethod(jsii.String(http.MethodPost), transferJobPostIntegration, recruiterMethodOpts)
+
+	reportsResource := jobPostWithId.AddResource(jsii.String("reports"), apiResourceOpts)
+	createReportIntegration := awsapigateway.NewLambdaIntegration(createReport, apiLambdaOpts)
+	reportsResource.AddMethod(jsii.String(http.MethodPost), createReportIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
+
+	// Recruiters save drafts before they have an account, after that only the draft's owner signed
+	// in can reach it. Drafts saved signed in let their owner sign in again, see startchallenge.
+	drafts := upfront.AddResource(jsii.String("drafts"), apiResourceOpts)
+	createDraftIntegration := awsapigateway.NewLambdaIntegration(createDraft, apiLambdaOpts)
+	drafts.AddMethod(jsii.String(http.MethodPost), createDraftIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
+	recruiter.AddResource(jsii.String("drafts"), apiResourceOpts).AddMethod(jsii.String(http.MethodPost), createDraftIntegration, recruiterMethodOpts)
+	draftWithId := drafts.AddResource(jsii.String("{id}"), apiResourceOpts)
+	getDraftIntegration := awsapigateway.NewLambdaIntegration(getDraft, apiLambdaOpts)
+	draftWithId.AddMethod(jsii.String(http.MethodGet), getDraftIntegration, recruiterMethodOpts)
+	updateDraftIntegration := awsapigateway.NewLambdaIntegration(updateDraft, apiLambdaOpts)
+	draftWithId.AddMethod(jsii.String(http.MethodPut), updateDraftIntegration, recruiterMethodOpts)
+	deleteDraftIntegration := awsapigateway.NewLambdaIntegration(deleteDraft, apiLambdaOpts)
+	draftWithId.AddMethod(jsii.String(http.MethodDelete), deleteDraftIntegration, recruiterMethodOpts)
+	draftWithId.AddResource(jsii.String("preview"), apiResourceOpts).AddMethod(jsii.String(http.MethodGet), getDraftIntegration, recruiterMethodOpts)
+
+	// Admin routes use the same authorizer, the lambdas check the caller is in the admins group.
+	admin := upfront.AddResource(jsii.String("admin"), apiResourceOpts)
+	adminJobPosts := admin.AddResource(jsii.String("job-posts"), apiResourceOpts)
+	adminJobPostWithId := adminJobPosts.AddResource(jsii.String("{id}"), apiResourceOpts)
+	adminCancelJobPostResource := adminJobPostWithId.AddResource(jsii.String("cancel"), apiResourceOpts)
+	adminCancelJobPostResource.AddMethod(jsii.String(http.MethodPost), cancelJobPostIntegration, recruiterMethodOpts)
+	adminReviewJobPostResource := adminJobPostWithId.AddResource(jsii.String("review"), apiResourceOpts)
+	reviewJobPostIntegration := awsapigateway.NewLambdaIntegration(reviewJobPost, apiLambdaOpts)
+	adminReviewJobPostResource.AddMethod(jsii.String(http.MethodPost), reviewJobPostIntegration, recruiterMethodOpts)
+	adminReviewQueue := admin.AddResource(jsii.String("review-queue"), apiResourceOpts)
+	getReviewQueueIntegration := awsapigateway.NewLambdaIntegration(getReviewQueue, apiLambdaOpts)
+	adminReviewQueue.AddMethod(jsii.String(http.MethodGet), getReviewQueueIntegration, recruiterMethodOpts)
+	adminDuplicates := admin.AddResource(jsii.String("duplicates"), apiResourceOpts)
+	getDuplicatesIntegration := awsapigateway.NewLambdaIntegration(getDuplicates, apiLambdaOpts)
+	adminDuplicates.AddMethod(jsii.String(http.MethodGet), getDuplicatesIntegration, recruiterMethodOpts)
+	getAnalyticsIntegration := awsapigateway.NewLambdaIntegration(getAnalytics, apiLambdaOpts)
+	upfront.AddResource(jsii.String("analytics"), apiResourceOpts).AddMethod(jsii.String(http.MethodGet), getAnalyticsIntegration, recruiterMethodOpts)
+	admin.AddResource(jsii.String("analytics"), apiResourceOpts).AddMethod(jsii.String(http.MethodGet), getAnalyticsIntegration, recruiterMethodOpts)
+	adminReports := admin.AddResource(jsii.String("reports"), apiResourceOpts)
+	getReportsIntegration := awsapigateway.NewLambdaIntegration(getReports, apiLambdaOpts)
+	adminReports.AddMethod(jsii.String(http.MethodGet), getReportsIntegration, recruiterMethodOpts)
+	adminResolveReportsResource := adminJobPostWithId.AddResource(jsii.String("reports"), apiResourceOpts).AddResource(jsii.String("resolve"), apiResourceOpts)
+	resolveReportsIntegration := awsapigateway.NewLambdaIntegration(resolveReports, apiLambdaOpts)
+	adminResolveReportsResource.AddMethod(jsii.String(http.MethodPost), resolveReportsIntegration, recruiterMethodOpts)
+	pauseJobPostIntegration := awsapigateway.NewLambdaIntegration(pauseJobPost, apiLambdaOpts)
+	jobPostWithId.AddResource(jsii.String("pause"), apiResourceOpts).AddMethod(jsii.String(http.MethodPost), pauseJobPostIntegration, recruiterMethodOpts)
+	jobPostWithId.AddResource(jsii.String("resume"), apiResourceOpts).AddMethod(jsii.String(http.MethodPost), pauseJobPostIntegration, recruiterMethodOpts)
+	fillJobPostIntegration := awsapigateway.NewLambdaIntegration(fillJobPost, apiLambdaOpts)
+	jobPostWithId.AddResource(jsii.String("filled"), apiResourceOpts).AddMethod(jsii.String(http.MethodPost), fillJobPostIntegration, recruiterMethodOpts)
+	getAuditLogIntegration := awsapigateway.NewLambdaIntegration(getAuditLog, apiLambdaOpts)
+	jobPostWithId.AddResource(jsii.String("audit"), apiResourceOpts).AddMethod(jsii.String(http.MethodGet), getAuditLogIntegration, recruiterMethodOpts)
+	adminJobPostWithId.AddResource(jsii.String("audit"), apiResourceOpts).AddMethod(jsii.String(http.MethodGet), getAuditLogIntegration, recruiterMethodOpts)
+
+	companies := upfront.AddResource(jsii.String("companies"), apiResourceOpts)
+	companyWithSlug := companies.AddResource(jsii.String("{slug}"), apiResourceOpts)
+	getCompanyIntegration := awsapigateway.NewLambdaIntegration(getCompany, apiLambdaOpts)
+	companyWithSlug.AddMethod(jsii.String(http.MethodGet), getCompanyIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
+	updateCompanyIntegration := awsapigateway.NewLambdaIntegration(updateCompany, apiLambdaOpts)
+	companyWithSlug.AddMethod(jsii.String(http.MethodPut), updateCompanyIntegration, recruiterMethodOpts)
+	companyLogo := companyWithSlug.AddResource(jsii.String("logo"), apiResourceOpts)
+	uploadCompanyLogoIntegration := awsapigateway.NewLambdaIntegration(uploadCompanyLogo, apiLambdaOpts)
+	companyLogo.AddMethod(jsii.String(http.MethodPost), uploadCompanyLogoIntegration, recruiterMethodOpts)
+	companyMembers := companyWithSlug.AddResource(jsii.String("members"), apiResourceOpts)
+	getCompanyMembersIntegration := awsapigateway.NewLambdaIntegration(getCompanyMembers, apiLambdaOpts)
+	companyMembers.AddMethod(jsii.String(http.MethodGet), getCompanyMembersIntegration, recruiterMethodOpts)
+	companyMemberWithEmail := companyMembers.AddResource(jsii.String("{email}"), apiResourceOpts)
+	updateCompanyMemberIntegration := awsapigateway.NewLambdaIntegration(updateCompanyMember, apiLambdaOpts)
+	companyMemberWithEmail.AddMethod(jsii.String(http.MethodPut), updateCompanyMemberIntegration, recruiterMethodOpts)
+	removeCompanyMemberIntegration := awsapigateway.NewLambdaIntegration(removeCompanyMember, apiLambdaOpts)
+	companyMemberWithEmail.AddMethod(jsii.String(http.MethodDelete), removeCompanyMemberIntegration, recruiterMethodOpts)
+	companyInvitations := companyWithSlug.AddResource(jsii.String("invitations"), apiResourceOpts)
+	inviteCompanyMemberIntegration := awsapigateway.NewLambdaIntegration(inviteCompanyMember, apiLambdaOpts)
+	companyInvitations.AddMethod(jsii.String(http.MethodPost), inviteCompanyMemberIntegration, recruiterMethodOpts)
+	acceptInvitationResource := companyInvitations.AddResource(jsii.String("accept"), apiResourceOpts)
+	acceptInvitationIntegration := awsapigateway.NewLambdaIntegration(acceptInvitation, apiLambdaOpts)
+	acceptInvitationResource.AddMethod(jsii.String(http.MethodPost), acceptInvitationIntegration, recruiterMethodOpts)
+
 	recruiterJobPosts := upfront.AddResource(jsii.String("recruiter-posts"), apiResourceOpts)
 	recruiterJobPostsWithEmail := recruiterJobPosts.AddResource(jsii.String("{email}"), apiResourceOpts)
 	recruiterJobPostsGetIntegration := awsapigateway.NewLambdaIntegration(getRecruiterJobsPosts, apiLambdaOpts)
-	recruiterJobPostsWithEmail.AddMethod(jsii.String(http.MethodGet), recruiterJobPostsGetIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
+	recruiterJobPostsWithEmail.AddMethod(jsii.String(http.MethodGet), recruiterJobPostsGetIntegration, recruiterMethodOpts)
 
 	startChallengeResource := upfront.AddResource(jsii.String("start-challenge"), apiResourceOpts)
 	startChallengePostIntegration := awsapigateway.NewLambdaIntegration(startChallenge, apiLambdaOpts)
 	startChallengeResource.AddMethod(jsii.String(http.MethodPost), startChallengePostIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
 
+	openAPIResource := upfront.AddResource(jsii.String("openapi.json"), apiResourceOpts)
+	openAPIGetIntegration := awsapigateway.NewLambdaIntegration(getOpenAPI, apiLambdaOpts)
+	openAPIResource.AddMethod(jsii.String(http.MethodGet), openAPIGetIntegration, &awsapigateway.MethodOptions{ApiKeyRequired: jsii.Bool(true)})
+
 	return stack
 }
 
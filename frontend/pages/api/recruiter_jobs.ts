@@ -1,6 +1,6 @@
 import { NextApiRequest, NextApiResponse } from "next";
 import { JobPostItem } from "./checkout_session/[id]";
-import { getCurrentUser } from "aws-amplify/auth";
+import { fetchAuthSession, getCurrentUser } from "aws-amplify/auth";
 
 export interface getRecruiterJobsResponse {
     jobs: JobPostItem[];
@@ -10,6 +10,7 @@ export interface getRecruiterJobsResponse {
 export async function getRecruiterJobs() {
     try {
         const currentUser = await getCurrentUser();
+        const session = await fetchAuthSession();
         const url = `https://m7kkswah50.execute-api.eu-west-2.amazonaws.com/prod/upfront/recruiter-posts/${
             currentUser.signInDetails?.loginId as string
         }`;
@@ -18,6 +19,7 @@ export async function getRecruiterJobs() {
             method: "GET",
             headers: {
                 "x-api-key": process.env.NEXT_PUBLIC_API_KEY as string,
+                Authorization: session.tokens?.idToken?.toString() as string,
                 "Content-Type": "application/json",
             },
         });
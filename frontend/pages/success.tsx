@@ -40,8 +40,8 @@ export default function Success({ jobPost, signedIn }: SuccessProps) {
                     Payment Success
                 </Text>
                 <Text fontSize={"2rem"} mb="1rem">
-                    An email has been sent to {jobPost.loginEmail} confirming
-                    your plan purchase. <br />
+                    An email has been sent confirming your plan purchase.{" "}
+                    <br />
                     You may now sign in to the Upfront portal to view/manage
                     your job posts!
                 </Text>